@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/san-kum/bookmarker/internal/app"
+	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/spf13/cobra"
+)
+
+func newArchiveCommand() *cobra.Command {
+	var missing bool
+
+	cmd := &cobra.Command{
+		Use:   "archive [id...]",
+		Short: "Save offline snapshots of bookmarked pages",
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			var bookmarks []*model.Bookmark
+			for _, arg := range args {
+				id, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid bookmark ID %q", arg)
+				}
+				bookmark, err := a.BookmarkService().Get(id)
+				if err != nil {
+					return err
+				}
+				if bookmark == nil {
+					return fmt.Errorf("bookmark %d not found", id)
+				}
+				bookmarks = append(bookmarks, bookmark)
+			}
+
+			if missing {
+				unarchived, err := a.Archiver().ListUnarchived()
+				if err != nil {
+					return err
+				}
+				bookmarks = append(bookmarks, unarchived...)
+			}
+
+			if len(bookmarks) == 0 {
+				return fmt.Errorf("no bookmarks to archive; pass IDs or --missing")
+			}
+
+			out := cmd.OutOrStdout()
+			var failed int
+			for _, bookmark := range bookmarks {
+				snapshot, err := a.Archiver().Archive(bookmark)
+				if err != nil {
+					failed++
+					fmt.Fprintf(out, "%d\terror\t%s\t%v\n", bookmark.ID, bookmark.URL, err)
+					continue
+				}
+				fmt.Fprintf(out, "%d\t%s\t%s\n", bookmark.ID, bookmark.URL, a.Archiver().Path(snapshot))
+			}
+			fmt.Fprintf(out, "Archived %d bookmarks, %d failed\n", len(bookmarks)-failed, failed)
+			return nil
+		}),
+	}
+
+	cmd.Flags().BoolVar(&missing, "missing", false, "archive every bookmark without a snapshot")
+
+	return cmd
+}
+
+func newSnapshotCommand() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "snapshot <id>",
+		Short: "Write a bookmark's latest offline snapshot as a self-contained file",
+		Long: "Write the latest offline snapshot of a bookmark with its stylesheets, images and\n" +
+			"fonts inlined, so the file opens anywhere without network access.",
+		Args: cobra.ExactArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			ids, err := parseBookmarkIDs(args)
+			if err != nil {
+				return err
+			}
+			snapshot, err := a.Archiver().Latest(ids[0])
+			if err != nil {
+				return err
+			}
+			if snapshot == nil {
+				return fmt.Errorf("bookmark %d has no offline snapshot; run archive first", ids[0])
+			}
+			data, err := a.Archiver().Render(snapshot)
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				_, err := cmd.OutOrStdout().Write(data)
+				return err
+			}
+			if err := os.WriteFile(output, data, 0644); err != nil {
+				return fmt.Errorf("failed to write output file: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote the snapshot of %d to %s\n", ids[0], output)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write, standard output if empty")
+
+	return cmd
+}
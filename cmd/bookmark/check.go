@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/san-kum/bookmarker/internal/app"
+	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/san-kum/bookmarker/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+func newCheckCommand() *cobra.Command {
+	var broken, moved bool
+	var olderThan string
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check bookmark links for dead or moved pages",
+		Long: "Check bookmark links and record their HTTP status.\n" +
+			"With --broken or --moved, list every bookmark currently in that state afterwards.",
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			age, err := parseAge(olderThan)
+			if err != nil {
+				return err
+			}
+
+			statuses, err := a.LinkChecker().CheckDue(age)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if !broken && !moved {
+				for _, status := range statuses {
+					if status == nil {
+						continue
+					}
+					bookmark, err := a.BookmarkService().Get(status.BookmarkID)
+					if err != nil {
+						return err
+					}
+					if bookmark != nil {
+						printLinkStatus(out, bookmark, status)
+					}
+				}
+				fmt.Fprintf(out, "Checked %d links\n", len(statuses))
+				return nil
+			}
+
+			var healths []string
+			if broken {
+				healths = append(healths, model.LinkHealthBroken)
+			}
+			if moved {
+				healths = append(healths, model.LinkHealthMoved)
+			}
+
+			for _, health := range healths {
+				bookmarks, err := a.BookmarkService().Find(repository.ListOptions{Health: health, Limit: -1})
+				if err != nil {
+					return err
+				}
+				for _, bookmark := range bookmarks {
+					status, err := a.LinkChecker().Status(bookmark.ID)
+					if err != nil {
+						return err
+					}
+					printLinkStatus(out, bookmark, status)
+				}
+				fmt.Fprintf(out, "%d %s links\n", len(bookmarks), health)
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().BoolVar(&broken, "broken", false, "list broken links")
+	cmd.Flags().BoolVar(&moved, "moved", false, "list permanently moved links")
+	cmd.Flags().StringVar(&olderThan, "older-than", "0", "only check links last checked longer ago than this (e.g. 1d)")
+
+	return cmd
+}
+
+func printLinkStatus(out io.Writer, bookmark *model.Bookmark, status *model.LinkStatus) {
+	label := fmt.Sprintf("%d", status.StatusCode)
+	switch {
+	case status.IsBroken():
+		label = "broken"
+	case status.IsMoved():
+		label = "moved"
+	}
+
+	fmt.Fprintf(out, "%d\t%s\t%s", bookmark.ID, label, bookmark.URL)
+	switch {
+	case status.Error != "":
+		fmt.Fprintf(out, "\t%s", status.Error)
+	case status.IsMoved():
+		fmt.Fprintf(out, "\t-> %s", status.FinalURL)
+	}
+	fmt.Fprintln(out)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/san-kum/bookmarker/internal/app"
+	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/san-kum/bookmarker/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+func newCollectionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "collection",
+		Aliases: []string{"collections"},
+		Short:   "Work with bookmark collections",
+		Long: `Collections are nestable folders of bookmarks, named by their path such as
+"Bookmarks bar/Recipes". A bookmark belongs to at most one collection.`,
+	}
+
+	cmd.AddCommand(newCollectionListCommand())
+	cmd.AddCommand(newCollectionCreateCommand())
+	cmd.AddCommand(newCollectionRenameCommand())
+	cmd.AddCommand(newCollectionMoveCommand())
+	cmd.AddCommand(newCollectionDeleteCommand())
+	cmd.AddCommand(newCollectionAddCommand())
+	cmd.AddCommand(newCollectionRemoveCommand())
+	cmd.AddCommand(newCollectionPositionCommand())
+
+	return cmd
+}
+
+func newCollectionListCommand() *cobra.Command {
+	var withBookmarks bool
+
+	cmd := &cobra.Command{
+		Use:   "list [path]",
+		Short: "Show the collection tree, or the bookmarks of a collection",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			if len(args) == 1 {
+				collection, err := a.CollectionService().Lookup(args[0])
+				if err != nil {
+					return err
+				}
+				return printCollectionBookmarks(a, out, collection.ID, "")
+			}
+
+			collections, err := a.CollectionService().List()
+			if err != nil {
+				return err
+			}
+			children := make(map[int64][]*model.Collection)
+			for _, collection := range collections {
+				var parent int64
+				if collection.ParentID != nil {
+					parent = *collection.ParentID
+				}
+				children[parent] = append(children[parent], collection)
+			}
+
+			var print func(parent int64, depth int) error
+			print = func(parent int64, depth int) error {
+				for _, collection := range children[parent] {
+					indent := strings.Repeat("  ", depth)
+					fmt.Fprintf(out, "%s%s\n", indent, collection.Name)
+					if withBookmarks {
+						if err := printCollectionBookmarks(a, out, collection.ID, indent+"  "); err != nil {
+							return err
+						}
+					}
+					if err := print(collection.ID, depth+1); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			return print(0, 0)
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&withBookmarks, "bookmarks", "b", false, "list the bookmarks of each collection")
+
+	return cmd
+}
+
+func printCollectionBookmarks(a *app.App, out io.Writer, collectionID int64, indent string) error {
+	bookmarks, err := a.BookmarkService().Find(repository.ListOptions{Collection: collectionID, Limit: -1})
+	if err != nil {
+		return err
+	}
+	for _, bookmark := range bookmarks {
+		fmt.Fprintf(out, "%s%d\t%s\n", indent, bookmark.ID, bookmark.URL)
+	}
+	return nil
+}
+
+func newCollectionCreateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "create <path>",
+		Short: "Create a collection and any missing parent collections",
+		Args:  cobra.ExactArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			if _, err := a.CollectionService().Create(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Created %s\n", args[0])
+			return nil
+		}),
+	}
+}
+
+func newCollectionRenameCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <path> <name>",
+		Short: "Rename a collection",
+		Args:  cobra.ExactArgs(2),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			if err := a.CollectionService().Rename(args[0], args[1]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Renamed %s to %s\n", args[0], args[1])
+			return nil
+		}),
+	}
+}
+
+func newCollectionMoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "move <path> <parent>",
+		Short: `Move a collection into another, or to the top level with "/"`,
+		Args:  cobra.ExactArgs(2),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			if err := a.CollectionService().Move(args[0], args[1]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Moved %s into %s\n", args[0], args[1])
+			return nil
+		}),
+	}
+}
+
+func newCollectionDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <path>...",
+		Short: "Delete collections and their subcollections, keeping their bookmarks",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			for _, path := range args {
+				if err := a.CollectionService().Delete(path); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Deleted %s\n", path)
+			}
+			return nil
+		}),
+	}
+}
+
+func newCollectionAddCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <path> <id>...",
+		Short: "Move bookmarks to the end of a collection, creating it if needed",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			ids, err := parseBookmarkIDs(args[1:])
+			if err != nil {
+				return err
+			}
+			collection, err := a.CollectionService().Create(args[0])
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if err := a.CollectionService().AddBookmark(collection.ID, id); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added %d bookmarks to %s\n", len(ids), args[0])
+			return nil
+		}),
+	}
+}
+
+func newCollectionRemoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <id>...",
+		Short: "Take bookmarks out of their collection",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			ids, err := parseBookmarkIDs(args)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if err := a.CollectionService().RemoveBookmark(id); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %d bookmarks from their collections\n", len(ids))
+			return nil
+		}),
+	}
+}
+
+func newCollectionPositionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "position <id> <position>",
+		Short: "Move a bookmark within its collection, 1 being the first place",
+		Args:  cobra.ExactArgs(2),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			ids, err := parseBookmarkIDs(args[:1])
+			if err != nil {
+				return err
+			}
+			position, err := strconv.Atoi(args[1])
+			if err != nil || position < 1 {
+				return fmt.Errorf("invalid position %q", args[1])
+			}
+			return a.CollectionService().MoveBookmark(ids[0], position-1)
+		}),
+	}
+}
+
+func parseBookmarkIDs(args []string) ([]int64, error) {
+	ids := make([]int64, len(args))
+	for i, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bookmark ID %q", arg)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
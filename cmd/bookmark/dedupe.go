@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/san-kum/bookmarker/internal/app"
+	"github.com/spf13/cobra"
+)
+
+func newDedupeCommand() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Find and merge bookmarks of the same page",
+		Long: `Find bookmarks whose URLs differ only in scheme, "www.", trailing slashes,
+fragments or tracking parameters, or that share a canonical URL, and merge
+each group into its oldest bookmark.`,
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			groups, err := a.BookmarkService().Dedupe(dryRun)
+
+			out := cmd.OutOrStdout()
+			var merged int
+			for _, group := range groups {
+				fmt.Fprintf(out, "%d\t%s\n", group.Keep.ID, group.Keep.URL)
+				for _, duplicate := range group.Duplicates {
+					fmt.Fprintf(out, "  %d\t%s\n", duplicate.ID, duplicate.URL)
+				}
+				merged += len(group.Duplicates)
+			}
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				fmt.Fprintf(out, "Would merge %d duplicates into %d bookmarks\n", merged, len(groups))
+			} else {
+				fmt.Fprintf(out, "Merged %d duplicates into %d bookmarks\n", merged, len(groups))
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the duplicates without merging them")
+
+	return cmd
+}
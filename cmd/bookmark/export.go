@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/san-kum/bookmarker/internal/app"
+	"github.com/san-kum/bookmarker/internal/repository"
+	"github.com/san-kum/bookmarker/internal/service/bookmarkfile"
+	"github.com/san-kum/bookmarker/internal/service/export"
+	"github.com/spf13/cobra"
+)
+
+func newExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export bookmarks to other formats",
+	}
+
+	cmd.AddCommand(newExportWARCCommand())
+	cmd.AddCommand(newExportBookmarksCommand("netscape", "Export bookmarks as a Netscape bookmark file", bookmarkfile.WriteNetscape))
+	cmd.AddCommand(newExportBookmarksCommand("chrome", `Export bookmarks as a Chrome "Bookmarks" file`, bookmarkfile.WriteChrome))
+	cmd.AddCommand(newExportHighlightsCommand())
+
+	return cmd
+}
+
+func newExportWARCCommand() *cobra.Command {
+	var output, tag string
+	var fetch bool
+
+	cmd := &cobra.Command{
+		Use:   "warc",
+		Short: "Export archived pages as a WARC 1.1 file",
+		Long: "Write the archived request and response of each bookmark's page to a WARC 1.1 file.\n" +
+			"Output ending in .gz is written as a per-record compressed .warc.gz.",
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			if output == "" {
+				return fmt.Errorf("--output is required")
+			}
+
+			bookmarks, err := a.BookmarkService().Find(repository.ListOptions{Tag: tag, Limit: -1})
+			if err != nil {
+				return err
+			}
+
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer f.Close()
+
+			w := export.NewWARCWriter(f, strings.HasSuffix(output, ".gz"))
+			if err := w.WriteInfo(filepath.Base(output), "bookmarker"); err != nil {
+				return err
+			}
+
+			stats, err := a.Archiver().ExportWARC(w, bookmarks, fetch)
+			if err != nil {
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write output file: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d pages and the notes of %d bookmarks to %s (%d fetched live, %d skipped without an archive)\n",
+				stats.Written, stats.Notes, output, stats.Fetched, stats.Skipped)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write (.warc or .warc.gz)")
+	cmd.Flags().StringVar(&tag, "tag", "", "only export bookmarks with this tag")
+	cmd.Flags().BoolVar(&fetch, "fetch", false, "fetch bookmarks that have no archived exchange")
+
+	return cmd
+}
+
+// newExportBookmarksCommand returns a command writing every bookmark, in its
+// collection, as a browser bookmark file.
+func newExportBookmarksCommand(name, short string, write func(io.Writer, *bookmarkfile.Folder) error) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   name,
+		Short: short,
+		Long:  short + ". Collections become folders.",
+		Args:  cobra.NoArgs,
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			folder, err := a.CollectionService().Export()
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				return write(cmd.OutOrStdout(), folder)
+			}
+
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer f.Close()
+
+			if err := write(f, folder); err != nil {
+				return fmt.Errorf("failed to write bookmark file: %w", err)
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write output file: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d bookmarks to %s\n", folder.Count(), output)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write, standard output if empty")
+
+	return cmd
+}
+
+func newExportHighlightsCommand() *cobra.Command {
+	var output, query string
+
+	cmd := &cobra.Command{
+		Use:   "highlights",
+		Short: "Export highlights as Markdown",
+		Long: "Write the passages highlighted in bookmarks, and their comments, as a Markdown\n" +
+			"document with a section per bookmark.",
+		Args: cobra.NoArgs,
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			bookmarks, err := a.BookmarkService().Highlights(query)
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				return export.WriteHighlightsMarkdown(cmd.OutOrStdout(), bookmarks)
+			}
+
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer f.Close()
+
+			if err := export.WriteHighlightsMarkdown(f, bookmarks); err != nil {
+				return fmt.Errorf("failed to write highlights: %w", err)
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write output file: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote the highlights of %d bookmarks to %s\n", len(bookmarks), output)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write, standard output if empty")
+	cmd.Flags().StringVarP(&query, "query", "q", "", "only export highlights whose passage or comment contains this text")
+
+	return cmd
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/san-kum/bookmarker/internal/app"
+	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/san-kum/bookmarker/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// newMarkFlagCommand returns a command that sets or clears a flag, such as
+// the star, on the bookmarks given.
+func newMarkFlagCommand(use, short, done string, set func(s *service.BookmarkService, id int64) error) *cobra.Command {
+	return &cobra.Command{
+		Use:   use + " <id>...",
+		Short: short,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			ids, err := parseBookmarkIDs(args)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if err := set(a.BookmarkService(), id); err != nil {
+					return fmt.Errorf("bookmark %d: %w", id, err)
+				}
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %d bookmarks\n", done, len(ids))
+			return nil
+		}),
+	}
+}
+
+func newStarCommand() *cobra.Command {
+	return newMarkFlagCommand("star", "Star bookmarks", "Starred", func(s *service.BookmarkService, id int64) error {
+		return s.SetStarred(id, true)
+	})
+}
+
+func newUnstarCommand() *cobra.Command {
+	return newMarkFlagCommand("unstar", "Remove the star from bookmarks", "Unstarred", func(s *service.BookmarkService, id int64) error {
+		return s.SetStarred(id, false)
+	})
+}
+
+func newPinCommand() *cobra.Command {
+	return newMarkFlagCommand("pin", "Pin bookmarks to the top of the list", "Pinned", func(s *service.BookmarkService, id int64) error {
+		return s.SetPinned(id, true)
+	})
+}
+
+func newUnpinCommand() *cobra.Command {
+	return newMarkFlagCommand("unpin", "Unpin bookmarks", "Unpinned", func(s *service.BookmarkService, id int64) error {
+		return s.SetPinned(id, false)
+	})
+}
+
+func newRateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rate <id> <rating>",
+		Short: fmt.Sprintf("Rate a bookmark from 1 to %d, or 0 to clear its rating", model.MaxRating),
+		Args:  cobra.ExactArgs(2),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			ids, err := parseBookmarkIDs(args[:1])
+			if err != nil {
+				return err
+			}
+			rating, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid rating %q", args[1])
+			}
+			if err := a.BookmarkService().SetRating(ids[0], rating); err != nil {
+				return err
+			}
+			if rating == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Cleared the rating of %d\n", ids[0])
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "Rated %d %d/%d\n", ids[0], rating, model.MaxRating)
+			}
+			return nil
+		}),
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/san-kum/bookmarker/internal/app"
+	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/spf13/cobra"
+)
+
+func newHighlightCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "highlight",
+		Aliases: []string{"highlights"},
+		Short:   "Work with passages highlighted in bookmarks",
+	}
+
+	cmd.AddCommand(newHighlightAddCommand())
+	cmd.AddCommand(newHighlightListCommand())
+	cmd.AddCommand(newHighlightCommentCommand())
+	cmd.AddCommand(newHighlightDeleteCommand())
+
+	return cmd
+}
+
+func newHighlightAddCommand() *cobra.Command {
+	var comment string
+
+	cmd := &cobra.Command{
+		Use:   "add <id> <passage>",
+		Short: "Highlight a passage of a bookmark's content",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			ids, err := parseBookmarkIDs(args[:1])
+			if err != nil {
+				return err
+			}
+			highlight, err := a.BookmarkService().AddHighlight(ids[0], strings.Join(args[1:], " "), -1, comment)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added highlight %d\n", highlight.ID)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&comment, "comment", "c", "", "comment on the passage")
+
+	return cmd
+}
+
+func newHighlightListCommand() *cobra.Command {
+	var query string
+
+	cmd := &cobra.Command{
+		Use:   "list [id]",
+		Short: "List the highlights of a bookmark, or of every bookmark",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			var bookmarks []*model.Bookmark
+			if len(args) == 1 {
+				ids, err := parseBookmarkIDs(args)
+				if err != nil {
+					return err
+				}
+				bookmark, err := a.BookmarkService().Get(ids[0])
+				if err != nil {
+					return err
+				}
+				if bookmark == nil {
+					return fmt.Errorf("bookmark not found.")
+				}
+				bookmarks = append(bookmarks, bookmark)
+			} else {
+				var err error
+				if bookmarks, err = a.BookmarkService().Highlights(query); err != nil {
+					return err
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			for _, bookmark := range bookmarks {
+				for _, highlight := range bookmark.Highlights {
+					fmt.Fprintf(out, "%d\t%d\t%s\t%s\n", highlight.ID, bookmark.ID,
+						strings.ReplaceAll(highlight.Text, "\n", " "), highlight.Comment)
+				}
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&query, "query", "q", "", "only list highlights whose passage or comment contains this text")
+
+	return cmd
+}
+
+func newHighlightCommentCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "comment <highlight-id> [comment]",
+		Short: "Set the comment on a highlight, removing it if none is given",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			id, err := parseHighlightID(args[0])
+			if err != nil {
+				return err
+			}
+			return a.BookmarkService().SetHighlightComment(id, strings.Join(args[1:], " "))
+		}),
+	}
+}
+
+func newHighlightDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <highlight-id>...",
+		Short: "Delete highlights",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			for _, arg := range args {
+				id, err := parseHighlightID(arg)
+				if err != nil {
+					return err
+				}
+				if err := a.BookmarkService().DeleteHighlight(id); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted %d highlights\n", len(args))
+			return nil
+		}),
+	}
+}
+
+func parseHighlightID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid highlight ID %q", arg)
+	}
+	return id, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/san-kum/bookmarker/internal/app"
+	"github.com/san-kum/bookmarker/internal/service/bookmarkfile"
+	"github.com/spf13/cobra"
+)
+
+func newImportCommand() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import bookmarks from a browser bookmark file",
+		Long: `Import a Netscape bookmark file, as exported by every major browser, or
+Chrome's JSON "Bookmarks" file. Folders become collections. The format is
+detected from the file unless --format is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open bookmark file: %w", err)
+			}
+			defer f.Close()
+
+			var folder *bookmarkfile.Folder
+			switch format {
+			case "":
+				folder, err = bookmarkfile.Parse(f)
+			case "netscape", "html":
+				folder, err = bookmarkfile.ParseNetscape(f)
+			case "chrome", "json":
+				folder, err = bookmarkfile.ParseChrome(f)
+			default:
+				return fmt.Errorf("unknown format %q", format)
+			}
+			if err != nil {
+				return err
+			}
+
+			stats, err := a.CollectionService().Import(folder)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Imported %d bookmarks into %d new collections (%d skipped)\n",
+				stats.Bookmarks, stats.Collections, stats.Skipped)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&format, "format", "", "file format: netscape or chrome")
+
+	return cmd
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/san-kum/bookmarker/internal/app"
+	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/san-kum/bookmarker/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+func newInboxCommand() *cobra.Command {
+	var order, tag string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "inbox",
+		Short: "List the read-later queue",
+		Long: "List the bookmarks waiting to be read or being read, oldest first or, with\n" +
+			"--order priority, highest priority first.",
+		Args: cobra.NoArgs,
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			bookmarks, err := a.BookmarkService().Inbox(order, tag, limit)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			for _, bookmark := range bookmarks {
+				printQueued(out, bookmark)
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&order, "order", repository.SortOldest, "queue order: oldest or priority")
+	cmd.Flags().StringVar(&tag, "tag", "", "only list bookmarks with this tag")
+	cmd.Flags().IntVarP(&limit, "limit", "n", -1, "maximum number of bookmarks to list")
+
+	return cmd
+}
+
+func newNextCommand() *cobra.Command {
+	var order, tag string
+	var peek bool
+
+	cmd := &cobra.Command{
+		Use:   "next",
+		Short: "Take the next unread bookmark off the read-later queue",
+		Long: "Print the next unread bookmark in the queue and mark it as being read. With\n" +
+			"--peek it is left unread.",
+		Args: cobra.NoArgs,
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			bookmark, err := a.BookmarkService().Next(order, tag, peek)
+			if err != nil {
+				return err
+			}
+			if bookmark == nil {
+				return fmt.Errorf("nothing left to read")
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\n", bookmark.ID, bookmark.URL, bookmark.Title)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&order, "order", repository.SortOldest, "queue order: oldest or priority")
+	cmd.Flags().StringVar(&tag, "tag", "", "only take bookmarks with this tag")
+	cmd.Flags().BoolVar(&peek, "peek", false, "leave the bookmark unread")
+
+	return cmd
+}
+
+func newMarkCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "mark <state> <id>...",
+		Short: "Mark bookmarks unread, reading, read or archived",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			state := strings.ToLower(args[0])
+			if !model.IsReadState(state) {
+				return fmt.Errorf("invalid read state %q, expected one of %s", args[0], strings.Join(model.ReadStates, ", "))
+			}
+			ids, err := parseBookmarkIDs(args[1:])
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if _, err := a.BookmarkService().SetReadState(id, state); err != nil {
+					return fmt.Errorf("bookmark %d: %w", id, err)
+				}
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Marked %d bookmarks %s\n", len(ids), state)
+			return nil
+		}),
+	}
+}
+
+func newPriorityCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "priority <id> <priority>",
+		Short: "Set a bookmark's priority in the read-later queue",
+		Long:  "Set a bookmark's priority in the read-later queue. Higher priorities are read first.",
+		Args:  cobra.ExactArgs(2),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			ids, err := parseBookmarkIDs(args[:1])
+			if err != nil {
+				return err
+			}
+			priority, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid priority %q", args[1])
+			}
+			if err := a.BookmarkService().SetPriority(ids[0], priority); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Set priority of %d to %d\n", ids[0], priority)
+			return nil
+		}),
+	}
+}
+
+func printQueued(out io.Writer, bookmark *model.Bookmark) {
+	state := bookmark.ReadState
+	if bookmark.ReadState == model.ReadStateReading {
+		state = fmt.Sprintf("%s %d%%", state, bookmark.ReadProgress)
+	}
+	fmt.Fprintf(out, "%d\t%s\t%d\t%s\t%s\n", bookmark.ID, state, bookmark.Priority, bookmark.URL, bookmark.Title)
+}
@@ -1,18 +1,67 @@
 package main
 
 import (
-	"log"
+	"os"
 
 	"github.com/san-kum/bookmarker/internal/app"
+	"github.com/spf13/cobra"
 )
 
 func main() {
-	application, err := app.NewApp()
-	if err != nil {
-		log.Fatalf("Failed to initialize application: %v", err)
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
 	}
+}
+
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:          "bookmark",
+		Short:        "Smart bookmark manager",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			application, err := app.NewApp()
+			if err != nil {
+				return err
+			}
+			return application.Run()
+		},
+	}
+
+	root.AddCommand(newRefreshCommand())
+	root.AddCommand(newCheckCommand())
+	root.AddCommand(newArchiveCommand())
+	root.AddCommand(newSnapshotCommand())
+	root.AddCommand(newImportCommand())
+	root.AddCommand(newExportCommand())
+	root.AddCommand(newTagCommand())
+	root.AddCommand(newRulesCommand())
+	root.AddCommand(newDedupeCommand())
+	root.AddCommand(newCollectionCommand())
+	root.AddCommand(newNotesCommand())
+	root.AddCommand(newAnnotationCommand())
+	root.AddCommand(newHighlightCommand())
+	root.AddCommand(newInboxCommand())
+	root.AddCommand(newNextCommand())
+	root.AddCommand(newMarkCommand())
+	root.AddCommand(newPriorityCommand())
+	root.AddCommand(newStarCommand())
+	root.AddCommand(newUnstarCommand())
+	root.AddCommand(newPinCommand())
+	root.AddCommand(newUnpinCommand())
+	root.AddCommand(newRateCommand())
+
+	return root
+}
 
-	if err := application.Run(); err != nil {
-		log.Fatalf("Application error: %v", err)
+// withApp initializes the application for a CLI command and closes it once
+// the command returns.
+func withApp(run func(a *app.App, cmd *cobra.Command, args []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		application, err := app.NewApp()
+		if err != nil {
+			return err
+		}
+		defer application.Close()
+		return run(application, cmd, args)
 	}
 }
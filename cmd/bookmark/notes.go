@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/san-kum/bookmarker/internal/app"
+	"github.com/spf13/cobra"
+)
+
+func newNotesCommand() *cobra.Command {
+	var printOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "notes <id>",
+		Short: "Edit a bookmark's notes in $EDITOR",
+		Long: "Open a bookmark's Markdown notes in $VISUAL or $EDITOR and save them once the\n" +
+			"editor exits. With --print, write the notes and annotations to standard output.",
+		Args: cobra.ExactArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			ids, err := parseBookmarkIDs(args)
+			if err != nil {
+				return err
+			}
+			bookmark, err := a.BookmarkService().Get(ids[0])
+			if err != nil {
+				return err
+			}
+			if bookmark == nil {
+				return fmt.Errorf("bookmark not found.")
+			}
+
+			if printOnly {
+				if notes := bookmark.NotesMarkdown(); notes != "" {
+					fmt.Fprintln(cmd.OutOrStdout(), notes)
+				}
+				return nil
+			}
+
+			notes, err := editText(bookmark.Notes)
+			if err != nil {
+				return err
+			}
+			if err := a.BookmarkService().SetNotes(bookmark.ID, notes); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved notes on %s\n", bookmark.URL)
+			return nil
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&printOnly, "print", "p", false, "print the notes instead of editing them")
+
+	return cmd
+}
+
+func newAnnotationCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "annotation",
+		Aliases: []string{"annotations", "annotate"},
+		Short:   "Work with timestamped annotations on bookmarks",
+	}
+
+	cmd.AddCommand(newAnnotationAddCommand())
+	cmd.AddCommand(newAnnotationListCommand())
+	cmd.AddCommand(newAnnotationEditCommand())
+	cmd.AddCommand(newAnnotationDeleteCommand())
+
+	return cmd
+}
+
+func newAnnotationAddCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <id> [text]",
+		Short: "Annotate a bookmark, writing the text in $EDITOR if not given",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			ids, err := parseBookmarkIDs(args[:1])
+			if err != nil {
+				return err
+			}
+			text := strings.Join(args[1:], " ")
+			if text == "" {
+				if text, err = editText(""); err != nil {
+					return err
+				}
+			}
+			annotation, err := a.BookmarkService().AddAnnotation(ids[0], text)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added annotation %d\n", annotation.ID)
+			return nil
+		}),
+	}
+}
+
+func newAnnotationListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list <id>",
+		Short: "List a bookmark's annotations, oldest first",
+		Args:  cobra.ExactArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			ids, err := parseBookmarkIDs(args)
+			if err != nil {
+				return err
+			}
+			bookmark, err := a.BookmarkService().Get(ids[0])
+			if err != nil {
+				return err
+			}
+			if bookmark == nil {
+				return fmt.Errorf("bookmark not found.")
+			}
+			out := cmd.OutOrStdout()
+			for _, annotation := range bookmark.Annotations {
+				fmt.Fprintf(out, "%d\t%s\t%s\n", annotation.ID,
+					annotation.CreatedAt.Local().Format("2006-01-02 15:04"),
+					strings.ReplaceAll(annotation.Text, "\n", " "))
+			}
+			return nil
+		}),
+	}
+}
+
+func newAnnotationEditCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit <annotation-id>",
+		Short: "Edit an annotation in $EDITOR, deleting it if left empty",
+		Args:  cobra.ExactArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			id, err := parseAnnotationID(args[0])
+			if err != nil {
+				return err
+			}
+			annotation, err := a.BookmarkService().GetAnnotation(id)
+			if err != nil {
+				return err
+			}
+			if annotation == nil {
+				return fmt.Errorf("annotation not found")
+			}
+			text, err := editText(annotation.Text)
+			if err != nil {
+				return err
+			}
+			return a.BookmarkService().UpdateAnnotation(id, text)
+		}),
+	}
+}
+
+func newAnnotationDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <annotation-id>...",
+		Short: "Delete annotations",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			for _, arg := range args {
+				id, err := parseAnnotationID(arg)
+				if err != nil {
+					return err
+				}
+				if err := a.BookmarkService().DeleteAnnotation(id); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted %d annotations\n", len(args))
+			return nil
+		}),
+	}
+}
+
+func parseAnnotationID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid annotation ID %q", arg)
+	}
+	return id, nil
+}
+
+// editText opens text in the user's editor and returns what was saved.
+// $VISUAL is preferred over $EDITOR, falling back to vi.
+func editText(text string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	f, err := os.CreateTemp("", "bookmark-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	// The editor may come with arguments, such as "code --wait".
+	args := append(strings.Fields(editor), f.Name())
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor failed: %w", err)
+	}
+
+	edited, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited file: %w", err)
+	}
+	return string(edited), nil
+}
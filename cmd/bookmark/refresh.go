@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/san-kum/bookmarker/internal/app"
+	"github.com/san-kum/bookmarker/internal/service"
+	"github.com/spf13/cobra"
+)
+
+func newRefreshCommand() *cobra.Command {
+	var olderThan string
+	var tag string
+
+	cmd := &cobra.Command{
+		Use:   "refresh [id...]",
+		Short: "Re-fetch and re-extract bookmarks",
+		Long: "Re-fetch bookmarks and update any fields whose extracted content changed.\n" +
+			"With no IDs, every bookmark not fetched within --older-than is refreshed.",
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			svc := a.BookmarkService()
+
+			var results []*service.RefreshResult
+			if len(args) > 0 {
+				for _, arg := range args {
+					id, err := strconv.ParseInt(arg, 10, 64)
+					if err != nil {
+						return fmt.Errorf("invalid bookmark ID %q", arg)
+					}
+					result, err := svc.Refresh(id)
+					if err != nil {
+						return fmt.Errorf("failed to refresh bookmark %d: %w", id, err)
+					}
+					results = append(results, result)
+				}
+			} else {
+				age, err := parseAge(olderThan)
+				if err != nil {
+					return err
+				}
+				results, err = svc.RefreshStale(age, tag)
+				if err != nil {
+					return err
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			var failed int
+			for _, r := range results {
+				switch {
+				case r.Err != nil:
+					failed++
+					fmt.Fprintf(out, "%d\terror\t%s\t%v\n", r.Bookmark.ID, r.Bookmark.URL, r.Err)
+				case r.NotModified:
+					fmt.Fprintf(out, "%d\tnot modified\t%s\n", r.Bookmark.ID, r.Bookmark.URL)
+				case len(r.Changed) == 0:
+					fmt.Fprintf(out, "%d\tunchanged\t%s\n", r.Bookmark.ID, r.Bookmark.URL)
+				default:
+					fmt.Fprintf(out, "%d\tupdated (%s)\t%s\n", r.Bookmark.ID, strings.Join(r.Changed, ", "), r.Bookmark.URL)
+				}
+			}
+			fmt.Fprintf(out, "Refreshed %d bookmarks, %d failed\n", len(results), failed)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "0", "only refresh bookmarks last fetched longer ago than this (e.g. 30d, 12h)")
+	cmd.Flags().StringVar(&tag, "tag", "", "only refresh bookmarks with this tag")
+
+	return cmd
+}
+
+// parseAge parses a duration, additionally accepting whole days ("30d") and
+// weeks ("2w").
+func parseAge(s string) (time.Duration, error) {
+	if s == "" || s == "0" {
+		return 0, nil
+	}
+
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if unit, ok := units[s[len(s)-1]]; ok {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/san-kum/bookmarker/internal/app"
+	"github.com/spf13/cobra"
+)
+
+func newRulesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rules",
+		Short: "Work with automatic tagging rules",
+	}
+
+	cmd.AddCommand(newRulesApplyCommand())
+
+	return cmd
+}
+
+func newRulesApplyCommand() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Apply the tagging rules to every bookmark",
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			results, err := a.BookmarkService().ApplyRules(dryRun)
+
+			out := cmd.OutOrStdout()
+			for _, result := range results {
+				fmt.Fprintf(out, "%d\t%s\t+%s\t(%s)\n", result.Bookmark.ID, result.Bookmark.URL,
+					strings.Join(result.Tags, ",+"), strings.Join(result.Rules, ", "))
+			}
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				fmt.Fprintf(out, "Would tag %d bookmarks\n", len(results))
+			} else {
+				fmt.Fprintf(out, "Tagged %d bookmarks\n", len(results))
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the tags that would be added without saving them")
+
+	return cmd
+}
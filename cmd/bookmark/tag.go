@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/san-kum/bookmarker/internal/app"
+	"github.com/spf13/cobra"
+)
+
+func newTagCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "tag",
+		Aliases: []string{"tags"},
+		Short:   "Work with bookmark tags",
+	}
+
+	cmd.AddCommand(newTagSuggestCommand())
+	cmd.AddCommand(newTagListCommand())
+	cmd.AddCommand(newTagRenameCommand())
+	cmd.AddCommand(newTagMergeCommand())
+	cmd.AddCommand(newTagDeleteCommand())
+	cmd.AddCommand(newTagPruneCommand())
+	cmd.AddCommand(newTagAliasCommand())
+	cmd.AddCommand(newTagUnaliasCommand())
+
+	return cmd
+}
+
+func newTagSuggestCommand() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "suggest <id>",
+		Short: "Suggest tags for a bookmark",
+		Args:  cobra.ExactArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid bookmark ID %q", args[0])
+			}
+
+			suggestions, err := a.BookmarkService().SuggestTags(id, limit)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if len(suggestions) == 0 {
+				fmt.Fprintln(out, "No suggestions")
+				return nil
+			}
+			for _, suggestion := range suggestions {
+				fmt.Fprintf(out, "%s\t%.2f\t%s\n", suggestion.Tag, suggestion.Score, strings.Join(suggestion.Reasons, ","))
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 5, "maximum number of suggestions")
+
+	return cmd
+}
+
+func newTagListCommand() *cobra.Command {
+	var byCount bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List tags with the number of bookmarks carrying each",
+		Args:  cobra.NoArgs,
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			tags, err := a.BookmarkService().GetAllTags()
+			if err != nil {
+				return err
+			}
+			counts, err := a.BookmarkService().TagCounts()
+			if err != nil {
+				return err
+			}
+			if byCount {
+				sort.SliceStable(tags, func(i, j int) bool {
+					return counts[tags[i].Name] > counts[tags[j].Name]
+				})
+			}
+
+			out := cmd.OutOrStdout()
+			for _, tag := range tags {
+				fmt.Fprintf(out, "%s\t%d\n", tag.Name, counts[tag.Name])
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&byCount, "count", "c", false, "sort by number of bookmarks, most used first")
+
+	return cmd
+}
+
+func newTagRenameCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <old> <new>",
+		Short: "Rename a tag, merging it into <new> if that tag exists",
+		Args:  cobra.ExactArgs(2),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			if err := a.BookmarkService().RenameTag(args[0], args[1]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Renamed %s to %s\n", args[0], args[1])
+			return nil
+		}),
+	}
+}
+
+func newTagMergeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "merge <source>... <target>",
+		Short: "Replace the source tags with the target tag",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			sources, target := args[:len(args)-1], args[len(args)-1]
+			if err := a.BookmarkService().MergeTags(sources, target); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Merged %s into %s\n", strings.Join(sources, ", "), target)
+			return nil
+		}),
+	}
+}
+
+func newTagDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <tag>...",
+		Short: "Remove tags from every bookmark and delete them",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			for _, name := range args {
+				if err := a.BookmarkService().DeleteTag(name); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Deleted %s\n", name)
+			}
+			return nil
+		}),
+	}
+}
+
+func newTagPruneCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune",
+		Short: "Delete tags no bookmark carries",
+		Args:  cobra.NoArgs,
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			names, err := a.BookmarkService().PruneTags()
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			for _, name := range names {
+				fmt.Fprintln(out, name)
+			}
+			fmt.Fprintf(out, "Pruned %d tags\n", len(names))
+			return nil
+		}),
+	}
+}
+
+func newTagAliasCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "alias [<alias>... <tag>]",
+		Short: "Make other names resolve to a tag, or list tag aliases",
+		Long: `Make each alias another name for the tag. Tags entered as an alias are
+saved as the tag, searching for an alias finds the tag, and bookmarks
+already tagged with an alias are retagged. Without arguments, list the
+aliases.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				return fmt.Errorf("expected at least one alias and a tag")
+			}
+			return nil
+		},
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			if len(args) == 0 {
+				aliases, err := a.BookmarkService().TagAliases()
+				if err != nil {
+					return err
+				}
+				for _, alias := range aliases {
+					fmt.Fprintf(out, "%s\t%s\n", alias.Alias, alias.Tag)
+				}
+				return nil
+			}
+
+			aliases, tag := args[:len(args)-1], args[len(args)-1]
+			for _, alias := range aliases {
+				if err := a.BookmarkService().AliasTag(alias, tag); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Aliased %s to %s\n", alias, tag)
+			}
+			return nil
+		}),
+	}
+}
+
+func newTagUnaliasCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unalias <alias>...",
+		Short: "Remove tag aliases",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: withApp(func(a *app.App, cmd *cobra.Command, args []string) error {
+			for _, alias := range args {
+				if err := a.BookmarkService().RemoveTagAlias(alias); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Removed alias %s\n", alias)
+			}
+			return nil
+		}),
+	}
+}
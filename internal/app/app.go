@@ -2,12 +2,21 @@ package app
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/san-kum/bookmarker/internal/model"
 	"github.com/san-kum/bookmarker/internal/repository"
 	"github.com/san-kum/bookmarker/internal/service"
+	"github.com/san-kum/bookmarker/internal/service/archive"
+	"github.com/san-kum/bookmarker/internal/service/canonical"
 	"github.com/san-kum/bookmarker/internal/service/extractor"
+	"github.com/san-kum/bookmarker/internal/service/favicon"
+	"github.com/san-kum/bookmarker/internal/service/linkcheck"
+	"github.com/san-kum/bookmarker/internal/service/queue"
+	"github.com/san-kum/bookmarker/internal/service/rules"
 	"github.com/san-kum/bookmarker/internal/service/search"
+	"github.com/san-kum/bookmarker/internal/service/summary"
 	"github.com/san-kum/bookmarker/internal/ui"
 )
 
@@ -16,7 +25,11 @@ type App struct {
 	database      *repository.Database
 	bookmarkRepo  *repository.BookmarkRepository
 	bookmarkSvc   *service.BookmarkService
+	collectionSvc *service.CollectionService
 	searchService *search.SearchService
+	jobQueue      *queue.Queue
+	linkChecker   *linkcheck.Checker
+	archiver      *archive.Archiver
 	ui            *ui.TUI
 }
 
@@ -32,34 +45,106 @@ func NewApp() (*App, error) {
 	}
 
 	bookmarkRepo := repository.NewBookmarkRepository(db)
-	htmlExtractor := extractor.NewHTMLExtractor()
-	bookmarkSvc := service.NewBookmarkService(bookmarkRepo, htmlExtractor)
+	jobRepo := repository.NewJobRepository(db)
+	versionRepo := repository.NewVersionRepository(db)
+	fetcher := extractor.NewFetcher()
+	htmlExtractor := extractor.NewHTMLExtractor(fetcher, extractor.NewDefaultSiteRegistry(fetcher, config.Sites))
 
 	searchService, err := search.NewSearchService(bookmarkRepo, config.IndexPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initalize search service: %w", err)
 	}
 
-	tui := ui.NewTUI(bookmarkSvc, searchService)
+	jobQueue := queue.NewQueue(jobRepo, config.Workers)
+	archiver := archive.NewArchiver(fetcher, archive.NewStore(config.ArchiveDir), config.RenderDir, repository.NewArchiveRepository(db), bookmarkRepo)
+	jobQueue.Handle(model.JobKindArchive, archiver.HandleJob)
+
+	bookmarkSvc := service.NewBookmarkService(bookmarkRepo, versionRepo, htmlExtractor, newSummarizer(config), searchService, jobQueue)
+	bookmarkSvc.SetArchiveOnAdd(config.ArchiveOnAdd)
+	bookmarkSvc.SetCanonicalizer(canonical.NewCanonicalizer(config.TrackingParams))
+
+	favicons := favicon.NewCache(fetcher, archive.NewStore(config.FaviconDir))
+	bookmarkSvc.SetFavicons(favicons)
+
+	tagRules, err := rules.NewEngine(config.TagRules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tag rules: %w", err)
+	}
+	bookmarkSvc.SetRules(tagRules)
+
+	if err := bookmarkSvc.BackfillTextStats(); err != nil {
+		log.Warn().Err(err).Msg("Failed to compute text statistics for existing bookmarks")
+	}
+	if err := bookmarkSvc.BackfillURLKeys(); err != nil {
+		log.Warn().Err(err).Msg("Failed to compute URL keys for existing bookmarks")
+	}
+	if err := bookmarkSvc.NormalizeTags(); err != nil {
+		log.Warn().Err(err).Msg("Failed to normalize existing tags")
+	}
+	if err := bookmarkSvc.LinkTagParents(); err != nil {
+		log.Warn().Err(err).Msg("Failed to link hierarchical tags to their parents")
+	}
+
+	linkChecker := linkcheck.NewChecker(fetcher, repository.NewLinkRepository(db))
+
+	collectionSvc := service.NewCollectionService(repository.NewCollectionRepository(db), bookmarkSvc)
+
+	tui := ui.NewTUI(bookmarkSvc, collectionSvc, searchService, jobQueue, linkChecker, archiver, favicons)
 
 	return &App{
 		config:        config,
 		database:      db,
 		bookmarkRepo:  bookmarkRepo,
 		bookmarkSvc:   bookmarkSvc,
+		collectionSvc: collectionSvc,
 		searchService: searchService,
+		jobQueue:      jobQueue,
+		linkChecker:   linkChecker,
+		archiver:      archiver,
 		ui:            tui,
 	}, nil
 }
 
+func (a *App) BookmarkService() *service.BookmarkService {
+	return a.bookmarkSvc
+}
+
+func (a *App) CollectionService() *service.CollectionService {
+	return a.collectionSvc
+}
+
+func (a *App) SearchService() *search.SearchService {
+	return a.searchService
+}
+
+func (a *App) LinkChecker() *linkcheck.Checker {
+	return a.linkChecker
+}
+
+func (a *App) Archiver() *archive.Archiver {
+	return a.archiver
+}
+
+// Close releases the application's resources without starting the UI. It is
+// used by CLI commands.
+func (a *App) Close() {
+	a.cleanup()
+}
+
 func (a *App) Run() error {
 	defer a.cleanup()
 	log.Info().Msg("Starting Smart bookmark manager...")
+	if err := a.jobQueue.Start(); err != nil {
+		return fmt.Errorf("failed to start job queue: %w", err)
+	}
+	a.linkChecker.Start(time.Duration(a.config.LinkCheckInterval))
 	return a.ui.Run()
 }
 
 func (a *App) cleanup() {
 	log.Info().Msg("Shutting down application...")
+	a.linkChecker.Stop()
+	a.jobQueue.Stop()
 	if err := a.searchService.Close(); err != nil {
 		log.Error().Err(err).Msg("Failed to close search service")
 	}
@@ -67,3 +152,14 @@ func (a *App) cleanup() {
 		log.Error().Err(err).Msg("Failed to close database")
 	}
 }
+
+// newSummarizer returns the built-in summarizer, or the configured summary
+// command backed by it.
+func newSummarizer(config *Config) summary.Summarizer {
+	extractive := summary.NewExtractive(config.SummaryLength)
+	if len(config.SummaryCommand) == 0 {
+		return extractive
+	}
+	command := summary.NewCommand(config.SummaryCommand, time.Duration(config.SummaryTimeout), config.SummaryMaxOutput)
+	return summary.NewFallback(command, extractive)
+}
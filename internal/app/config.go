@@ -1,15 +1,78 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
+
+	"github.com/san-kum/bookmarker/internal/service/canonical"
+	"github.com/san-kum/bookmarker/internal/service/extractor"
+	"github.com/san-kum/bookmarker/internal/service/rules"
+	"github.com/san-kum/bookmarker/internal/service/summary"
 )
 
 type Config struct {
-	DataDir   string
-	DBPath    string
-	IndexPath string
+	DataDir    string `json:"-"`
+	DBPath     string `json:"-"`
+	IndexPath  string `json:"-"`
+	ArchiveDir string `json:"-"`
+	RenderDir  string `json:"-"`
+	FaviconDir string `json:"-"`
+	Workers    int    `json:"workers"`
+
+	// LinkCheckInterval is how often bookmark links are re-checked while the
+	// TUI is running. Zero disables periodic checks.
+	LinkCheckInterval Duration `json:"link_check_interval"`
+
+	// SummaryLength is the target length, in characters, of the summaries
+	// generated for bookmarked pages.
+	SummaryLength int `json:"summary_length"`
+
+	// SummaryCommand optionally names an external program and its arguments
+	// used to summarize pages. It reads the page text on stdin and writes the
+	// summary to stdout; the built-in summarizer is used if it fails, runs
+	// longer than SummaryTimeout or writes more than SummaryMaxOutput bytes.
+	SummaryCommand   []string `json:"summary_command"`
+	SummaryTimeout   Duration `json:"summary_timeout"`
+	SummaryMaxOutput int      `json:"summary_max_output"`
+
+	// ArchiveOnAdd saves an offline snapshot of every newly added bookmark.
+	ArchiveOnAdd bool `json:"archive_on_add"`
+
+	// TagRules tag bookmarks automatically when they are added and fetched.
+	TagRules []rules.Rule `json:"tag_rules"`
+
+	// Sites configures the extractors for sites such as GitHub and arXiv,
+	// which are read through their APIs instead of their HTML.
+	Sites extractor.SiteConfig `json:"sites"`
+
+	// TrackingParams are the query parameters removed from bookmarked URLs
+	// and ignored when looking for duplicates. A trailing "*" matches any
+	// parameter with that prefix, as in "utm_*".
+	TrackingParams []string `json:"tracking_params"`
+}
+
+// Duration is a time.Duration read from the config file as a string such as
+// "24h".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"24h\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
 }
 
 func NewConfig() (*Config, error) {
@@ -22,9 +85,43 @@ func NewConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	return &Config{
-		DataDir:   dataDir,
-		DBPath:    filepath.Join(dataDir, "bookmarks.db"),
-		IndexPath: filepath.Join(dataDir, "search_index"),
-	}, nil
+	config := &Config{
+		DataDir:    dataDir,
+		DBPath:     filepath.Join(dataDir, "bookmarks.db"),
+		IndexPath:  filepath.Join(dataDir, "search_index"),
+		ArchiveDir: filepath.Join(dataDir, "archive"),
+		RenderDir:  filepath.Join(dataDir, "rendered"),
+		FaviconDir: filepath.Join(dataDir, "favicons"),
+		Workers:    2,
+
+		SummaryLength:    summary.DefaultLength,
+		SummaryTimeout:   Duration(summary.DefaultCommandTimeout),
+		SummaryMaxOutput: summary.DefaultCommandMaxOutput,
+
+		LinkCheckInterval: Duration(24 * time.Hour),
+
+		Sites:          extractor.DefaultSiteConfig(),
+		TrackingParams: canonical.DefaultTrackingParams,
+	}
+
+	if err := config.load(filepath.Join(dataDir, "config.json")); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// load overrides the defaults with any settings from the optional config file.
+func (c *Config) load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
 }
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Annotation is a timestamped remark on a bookmark, such as a thought
+// written down on a later visit. Unlike Notes, which are edited as a whole,
+// annotations accumulate.
+type Annotation struct {
+	ID         int64     `db:"id" json:"id"`
+	BookmarkID int64     `db:"bookmark_id" json:"-"`
+	Text       string    `db:"text" json:"text"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func NewAnnotation(bookmarkID int64, text string) *Annotation {
+	now := time.Now().UTC()
+	return &Annotation{
+		BookmarkID: bookmarkID,
+		Text:       strings.TrimSpace(text),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// NotesMarkdown returns the bookmark's notes followed by its annotations as
+// a dated Markdown list, or "" if it has neither. WARC exports use it to
+// carry both in a single record; bookmark files keep them in fields of their
+// own so they can be imported back.
+func (b *Bookmark) NotesMarkdown() string {
+	var sb strings.Builder
+	sb.WriteString(strings.TrimSpace(b.Notes))
+	if len(b.Annotations) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString("## Annotations\n")
+		for _, annotation := range b.Annotations {
+			text := strings.ReplaceAll(annotation.Text, "\n", "\n  ")
+			fmt.Fprintf(&sb, "\n- %s: %s", annotation.CreatedAt.Local().Format("2006-01-02 15:04"), text)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Archive is an offline snapshot of a bookmarked page. Paths are relative to
+// the archive directory. RequestPath and ResponsePath hold the raw HTTP
+// exchange for the page as fetched from TargetURL, before any rewriting.
+type Archive struct {
+	ID           int64     `db:"id" json:"id"`
+	BookmarkID   int64     `db:"bookmark_id" json:"bookmark_id"`
+	Hash         string    `db:"hash" json:"hash"`
+	Path         string    `db:"path" json:"path"`
+	ContentType  string    `db:"content_type" json:"content_type"`
+	Size         int64     `db:"size" json:"size"`
+	Assets       int       `db:"assets" json:"assets"`
+	TargetURL    string    `db:"target_url" json:"target_url"`
+	RequestPath  string    `db:"request_path" json:"request_path"`
+	ResponsePath string    `db:"response_path" json:"response_path"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+// HasExchange reports whether the raw HTTP request and response were kept.
+func (a *Archive) HasExchange() bool {
+	return a.RequestPath != "" && a.ResponsePath != ""
+}
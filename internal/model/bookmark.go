@@ -2,30 +2,124 @@ package model
 
 import "time"
 
+// Fetch states track whether a bookmark's page has been fetched and extracted
+// by the background enrichment queue.
+const (
+	FetchStatePending = "pending"
+	FetchStateDone    = "done"
+	FetchStateFailed  = "failed"
+)
+
+// Read states track a bookmark's place in the read-later queue: unread and
+// reading bookmarks are in the inbox, read and archived ones are not.
+const (
+	ReadStateUnread   = "unread"
+	ReadStateReading  = "reading"
+	ReadStateRead     = "read"
+	ReadStateArchived = "archived"
+)
+
+// MaxRating is the highest rating a bookmark can have.
+const MaxRating = 5
+
+var ReadStates = []string{ReadStateUnread, ReadStateReading, ReadStateRead, ReadStateArchived}
+
+func IsReadState(state string) bool {
+	for _, s := range ReadStates {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
 type Bookmark struct {
-	ID          int64     `db:"id" json:"id"`
-	URL         string    `db:"url" json:"url"`
-	Title       string    `db:"title" json:"title"`
-	Description string    `db:"description" json:"description"`
-	Content     string    `db:"content" json:"content"`
-	Summary     string    `db:"summary" json:"summary"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
-	Tags        []Tag     `json:"tags"`
+	ID           int64      `db:"id" json:"id"`
+	URL          string     `db:"url" json:"url"`
+	URLKey       string     `db:"url_key" json:"-"`
+	Title        string     `db:"title" json:"title"`
+	Description  string     `db:"description" json:"description"`
+	Content      string     `db:"content" json:"content"`
+	Summary      string     `db:"summary" json:"summary"`
+	Author       string     `db:"author" json:"author,omitempty"`
+	ContentType  string     `db:"content_type" json:"content_type,omitempty"`
+	PageCount    int        `db:"page_count" json:"page_count,omitempty"`
+	WordCount    int        `db:"word_count" json:"word_count"`
+	ReadingTime  int        `db:"reading_time" json:"reading_time"`
+	Lang         string     `db:"lang" json:"lang,omitempty"`
+	PublishedAt  *time.Time `db:"published_at" json:"published_at,omitempty"`
+	Favicon      string     `db:"favicon" json:"favicon,omitempty"`
+	FetchState   string     `db:"fetch_state" json:"fetch_state"`
+	ETag         string     `db:"etag" json:"-"`
+	LastModified string     `db:"last_modified" json:"-"`
+	FetchedAt    *time.Time `db:"fetched_at" json:"fetched_at,omitempty"`
+	ReadState    string     `db:"read_state" json:"read_state"`
+	Priority     int        `db:"priority" json:"priority"`
+	ReadAt       *time.Time `db:"read_at" json:"read_at,omitempty"`
+	// ReadProgress is how far the bookmark has been read, in percent.
+	ReadProgress int `db:"read_progress" json:"read_progress"`
+	// Pinned bookmarks are listed before all others. Rating is from 1 to
+	// MaxRating, or 0 if the bookmark is not rated.
+	Starred bool `db:"starred" json:"starred"`
+	Pinned  bool `db:"pinned" json:"pinned"`
+	Rating  int  `db:"rating" json:"rating,omitempty"`
+	// Notes are the user's own Markdown notes on the bookmark.
+	Notes       string       `db:"notes" json:"notes,omitempty"`
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time    `db:"updated_at" json:"updated_at"`
+	Tags        []Tag        `json:"tags"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+	Highlights  []Highlight  `json:"highlights,omitempty"`
 }
 
 func NewBookmark(url, title string) *Bookmark {
 	now := time.Now()
 	return &Bookmark{
-		URL:       url,
-		Title:     title,
-		CreatedAt: now,
-		UpdatedAt: now,
-		Tags:      make([]Tag, 0),
+		URL:        url,
+		Title:      title,
+		FetchState: FetchStatePending,
+		ReadState:  ReadStateUnread,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Tags:       make([]Tag, 0),
+	}
+}
+
+func (b *Bookmark) IsPending() bool {
+	return b.FetchState == FetchStatePending
+}
+
+// InInbox reports whether the bookmark is waiting to be read or being read.
+func (b *Bookmark) InInbox() bool {
+	return b.ReadState == ReadStateUnread || b.ReadState == ReadStateReading
+}
+
+// SetReadState moves the bookmark to state. Marking it read records when
+// and completes its progress; putting it back in the inbox forgets that it
+// was read, and marking it unread also resets its progress.
+func (b *Bookmark) SetReadState(state string, now time.Time) {
+	switch state {
+	case ReadStateRead:
+		if b.ReadAt == nil {
+			b.ReadAt = &now
+		}
+		b.ReadProgress = 100
+	case ReadStateUnread:
+		b.ReadAt = nil
+		b.ReadProgress = 0
+	case ReadStateReading:
+		b.ReadAt = nil
 	}
+	b.ReadState = state
 }
 
+// AddTag adds tag unless the bookmark already has it. The tag's name is
+// normalized first, and tags whose names normalize to nothing are dropped.
 func (b *Bookmark) AddTag(tag Tag) {
+	tag.Name = NormalizeTagName(tag.Name)
+	if tag.Name == "" {
+		return
+	}
 	for _, t := range b.Tags {
 		if t.Name == tag.Name {
 			return
@@ -34,7 +128,18 @@ func (b *Bookmark) AddTag(tag Tag) {
 	b.Tags = append(b.Tags, tag)
 }
 
+func (b *Bookmark) HasTag(tagName string) bool {
+	tagName = NormalizeTagName(tagName)
+	for _, t := range b.Tags {
+		if t.Name == tagName {
+			return true
+		}
+	}
+	return false
+}
+
 func (b *Bookmark) RemoveTag(tagName string) {
+	tagName = NormalizeTagName(tagName)
 	for i, tag := range b.Tags {
 		if tag.Name == tagName {
 			b.Tags = append(b.Tags[:i], b.Tags[i+1:]...)
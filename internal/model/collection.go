@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// Collection is a folder of bookmarks. Collections nest, and are ordered by
+// Position among their siblings. A bookmark belongs to at most one
+// collection, independently of its tags.
+type Collection struct {
+	ID        int64     `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	ParentID  *int64    `db:"parent_id" json:"parent_id,omitempty"`
+	Position  int       `db:"position" json:"position"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// CollectionEntry places a bookmark in a collection, at Position among the
+// collection's bookmarks.
+type CollectionEntry struct {
+	CollectionID int64 `db:"collection_id" json:"collection_id"`
+	BookmarkID   int64 `db:"bookmark_id" json:"bookmark_id"`
+	Position     int   `db:"position" json:"position"`
+}
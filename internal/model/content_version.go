@@ -0,0 +1,40 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// ContentVersion is a previous extracted Content of a bookmark, kept when a
+// refresh finds that the page changed.
+type ContentVersion struct {
+	ID         int64     `db:"id" json:"id"`
+	BookmarkID int64     `db:"bookmark_id" json:"bookmark_id"`
+	Hash       string    `db:"hash" json:"hash"`
+	Content    string    `db:"content" json:"content"`
+	FetchedAt  time.Time `db:"fetched_at" json:"fetched_at"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+func NewContentVersion(bookmarkID int64, content string, fetchedAt time.Time) *ContentVersion {
+	return &ContentVersion{
+		BookmarkID: bookmarkID,
+		Hash:       ContentHash(content),
+		Content:    content,
+		FetchedAt:  fetchedAt,
+		CreatedAt:  time.Now().UTC(),
+	}
+}
+
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func (v *ContentVersion) ShortHash() string {
+	if len(v.Hash) < 12 {
+		return v.Hash
+	}
+	return v.Hash[:12]
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Highlight is a passage quoted from a bookmark's Content, with an optional
+// comment. Offset is the passage's byte offset in the content it was saved
+// from; the content may have changed since.
+type Highlight struct {
+	ID         int64     `db:"id" json:"id"`
+	BookmarkID int64     `db:"bookmark_id" json:"bookmark_id"`
+	Text       string    `db:"text" json:"text"`
+	Offset     int       `db:"text_offset" json:"offset"`
+	Comment    string    `db:"comment" json:"comment,omitempty"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+func NewHighlight(bookmarkID int64, text string, offset int, comment string) *Highlight {
+	return &Highlight{
+		BookmarkID: bookmarkID,
+		Text:       text,
+		Offset:     offset,
+		Comment:    comment,
+		CreatedAt:  time.Now().UTC(),
+	}
+}
+
+// End returns the byte offset just past the passage.
+func (h *Highlight) End() int {
+	return h.Offset + len(h.Text)
+}
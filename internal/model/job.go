@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+const (
+	JobKindEnrich  = "enrich"
+	JobKindRefresh = "refresh"
+	JobKindArchive = "archive"
+)
+
+const (
+	JobStatusQueued  = "queued"
+	JobStatusRunning = "running"
+	JobStatusDone    = "done"
+	JobStatusFailed  = "failed"
+)
+
+// Job is a unit of background work persisted in the jobs table so that it
+// survives restarts.
+type Job struct {
+	ID         int64     `db:"id" json:"id"`
+	BookmarkID int64     `db:"bookmark_id" json:"bookmark_id"`
+	Kind       string    `db:"kind" json:"kind"`
+	Status     string    `db:"status" json:"status"`
+	Attempts   int       `db:"attempts" json:"attempts"`
+	LastError  string    `db:"last_error" json:"last_error"`
+	RunAt      time.Time `db:"run_at" json:"run_at"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func NewJob(bookmarkID int64, kind string) *Job {
+	now := time.Now().UTC()
+	return &Job{
+		BookmarkID: bookmarkID,
+		Kind:       kind,
+		Status:     JobStatusQueued,
+		RunAt:      now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
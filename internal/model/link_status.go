@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// BrokenAfterFailures is the number of consecutive failed checks after which
+// a link is reported as broken, so a single transient error does not flag it.
+const BrokenAfterFailures = 2
+
+const (
+	LinkHealthBroken = "broken"
+	LinkHealthMoved  = "moved"
+)
+
+// LinkStatus is the result of the most recent health check of a bookmark URL.
+type LinkStatus struct {
+	BookmarkID          int64     `db:"bookmark_id" json:"bookmark_id"`
+	StatusCode          int       `db:"status_code" json:"status_code"`
+	FinalURL            string    `db:"final_url" json:"final_url"`
+	Error               string    `db:"error" json:"error,omitempty"`
+	Moved               bool      `db:"moved" json:"moved"`
+	ConsecutiveFailures int       `db:"consecutive_failures" json:"consecutive_failures"`
+	CheckedAt           time.Time `db:"checked_at" json:"checked_at"`
+}
+
+func (s *LinkStatus) IsBroken() bool {
+	return s.ConsecutiveFailures >= BrokenAfterFailures
+}
+
+func (s *LinkStatus) IsMoved() bool {
+	return s.Moved && s.ConsecutiveFailures == 0
+}
@@ -1,12 +1,117 @@
 package model
 
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// TagSeparator separates the levels of hierarchical tag names such as
+// "lang/go". Tags are named by their full path; "lang/go" is a child of the
+// tag "lang".
+const TagSeparator = "/"
+
+// MaxTagLength is the longest a tag name may be, in characters. Longer names
+// are cut short.
+const MaxTagLength = 64
+
 type Tag struct {
-	ID   int64  `db:"id" json:"id"`
-	Name string `db:"name" json:"name"`
+	ID       int64  `db:"id" json:"id"`
+	Name     string `db:"name" json:"name"`
+	ParentID *int64 `db:"parent_id" json:"parent_id,omitempty"`
 }
 
 func NewTag(name string) Tag {
 	return Tag{
-		Name: name,
+		Name: NormalizeTagName(name),
+	}
+}
+
+// NormalizeTagName returns the canonical form of a tag name, so that "Go",
+// " go " and "#go" are the same tag. Names are NFKC-normalized and
+// lowercased. In each level of a hierarchical name, runs of spaces and
+// underscores become a single hyphen, punctuation other than "-", ".", "+"
+// and "#" is dropped, as are a leading "#" and trailing dots. Empty levels
+// are removed, and the result is cut to MaxTagLength characters.
+func NormalizeTagName(name string) string {
+	name = strings.ToLower(norm.NFKC.String(name))
+
+	var levels []string
+	for _, level := range strings.Split(name, TagSeparator) {
+		var b strings.Builder
+		hyphen := false
+		for _, r := range level {
+			switch {
+			case unicode.IsSpace(r) || r == '_' || r == '-':
+				hyphen = b.Len() > 0
+				continue
+			case unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r),
+				r == '.' || r == '+' || r == '#' && b.Len() > 0:
+			default:
+				continue
+			}
+			if hyphen {
+				b.WriteByte('-')
+				hyphen = false
+			}
+			b.WriteRune(r)
+		}
+		if level := strings.TrimRight(b.String(), "."); level != "" {
+			levels = append(levels, level)
+		}
+	}
+	name = strings.Join(levels, TagSeparator)
+
+	if utf8.RuneCountInString(name) > MaxTagLength {
+		name = string([]rune(name)[:MaxTagLength])
+		name = strings.TrimRight(name, "-."+TagSeparator)
+	}
+	return name
+}
+
+// ParentTagName returns the name of the parent of the tag called name, or ""
+// for a top-level tag.
+func ParentTagName(name string) string {
+	i := strings.LastIndex(name, TagSeparator)
+	if i <= 0 {
+		return ""
+	}
+	return name[:i]
+}
+
+// TagPath returns name followed by the names of all its ancestors, e.g.
+// "lang/go" and "lang".
+func TagPath(name string) []string {
+	path := []string{name}
+	for parent := ParentTagName(name); parent != ""; parent = ParentTagName(parent) {
+		path = append(path, parent)
+	}
+	return path
+}
+
+// InTagSubtree reports whether the tag called name is root or one of its
+// descendants.
+func InTagSubtree(name, root string) bool {
+	return name == root || strings.HasPrefix(name, root+TagSeparator)
+}
+
+// TagAlias makes Alias another name for the tag Tag.
+type TagAlias struct {
+	Alias string `db:"alias" json:"alias"`
+	Tag   string `db:"tag" json:"tag"`
+}
+
+// ResolveTagAlias returns the tag that name stands for given aliases, a map
+// from aliases to tags. An alias also covers the descendants of its name, so
+// with "golang" aliased to "lang/go", "golang/generics" resolves to
+// "lang/go/generics". Names that are not aliases are returned unchanged.
+func ResolveTagAlias(name string, aliases map[string]string) string {
+	for prefix := name; prefix != ""; prefix = ParentTagName(prefix) {
+		if tag, ok := aliases[prefix]; ok {
+			return tag + name[len(prefix):]
+		}
 	}
+	return name
 }
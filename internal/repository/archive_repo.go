@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/san-kum/bookmarker/internal/model"
+)
+
+type ArchiveRepository struct {
+	db *Database
+}
+
+func NewArchiveRepository(db *Database) *ArchiveRepository {
+	return &ArchiveRepository{
+		db: db,
+	}
+}
+
+func (r *ArchiveRepository) Create(archive *model.Archive) error {
+	query := `
+    INSERT INTO archives (bookmark_id, hash, path, content_type, size, assets, target_url, request_path, response_path, created_at)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `
+	res, err := r.db.GetDB().Exec(query, archive.BookmarkID, archive.Hash, archive.Path, archive.ContentType, archive.Size, archive.Assets,
+		archive.TargetURL, archive.RequestPath, archive.ResponsePath, archive.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert archive: %w", err)
+	}
+
+	archive.ID, err = res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+	return nil
+}
+
+// Latest returns the most recent snapshot of a bookmark, or nil if it has
+// never been archived.
+func (r *ArchiveRepository) Latest(bookmarkID int64) (*model.Archive, error) {
+	var archive model.Archive
+	query := `
+    SELECT * FROM archives
+    WHERE bookmark_id = ?
+    ORDER BY created_at DESC, id DESC
+    LIMIT 1
+    `
+	err := r.db.GetDB().Get(&archive, query, bookmarkID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get archive: %w", err)
+	}
+	return &archive, nil
+}
+
+// ListUnarchived returns bookmarks without any snapshot.
+func (r *ArchiveRepository) ListUnarchived() ([]*model.Bookmark, error) {
+	var bookmarks []*model.Bookmark
+	query := `
+    SELECT b.* FROM bookmarks b
+    WHERE NOT EXISTS (SELECT 1 FROM archives a WHERE a.bookmark_id = b.id)
+    ORDER BY b.created_at
+    `
+	if err := r.db.GetDB().Select(&bookmarks, query); err != nil {
+		return nil, fmt.Errorf("failed to list unarchived bookmarks: %w", err)
+	}
+	return bookmarks, nil
+}
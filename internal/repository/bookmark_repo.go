@@ -3,8 +3,11 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/san-kum/bookmarker/internal/model"
 )
 
@@ -27,10 +30,10 @@ func (r *BookmarkRepository) Create(bookmark *model.Bookmark) error {
 
 	// Insert bookmark
 	query := `
-    INSERT INTO bookmarks (url, title, description, content, summary, created_at, updated_at)
-    VALUES (?, ?, ?, ?, ?, ?, ?)
+    INSERT INTO bookmarks (url, url_key, title, description, content, summary, fetch_state, created_at, updated_at)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
     `
-	res, err := tx.Exec(query, bookmark.URL, bookmark.Title, bookmark.Description, bookmark.Content, bookmark.Summary, bookmark.CreatedAt, bookmark.UpdatedAt)
+	res, err := tx.Exec(query, bookmark.URL, bookmark.URLKey, bookmark.Title, bookmark.Description, bookmark.Content, bookmark.Summary, bookmark.FetchState, bookmark.CreatedAt, bookmark.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to insert bookmark: %w", err)
 	}
@@ -44,10 +47,9 @@ func (r *BookmarkRepository) Create(bookmark *model.Bookmark) error {
 	for i := range bookmark.Tags {
 		tag := &bookmark.Tags[i]
 		if tag.ID == 0 {
-			tagQuery := `INSERT INTO tags (name) VALUES (?) ON CONFLICT(name) DO UPDATE SET name=name RETURNING id`
-			err := tx.Get(&tag.ID, tagQuery, tag.Name)
+			tag.ID, err = ensureTag(tx, tag.Name)
 			if err != nil {
-				return fmt.Errorf("failed to insert tag: %w", err)
+				return err
 			}
 		}
 
@@ -86,6 +88,12 @@ func (r *BookmarkRepository) GetByID(id int64) (*model.Bookmark, error) {
 	if err := r.db.GetDB().Select(&bookmark.Tags, tagsQuery, id); err != nil {
 		return nil, fmt.Errorf("failed to get bookmark tags: %w", err)
 	}
+	if err := r.getAnnotations(&bookmark); err != nil {
+		return nil, err
+	}
+	if err := r.getHighlights(&bookmark); err != nil {
+		return nil, err
+	}
 
 	return &bookmark, nil
 }
@@ -111,55 +119,436 @@ func (r *BookmarkRepository) GetByURL(url string) (*model.Bookmark, error) {
 	if err := r.db.GetDB().Select(&bookmark.Tags, tagsQuery, bookmark.ID); err != nil {
 		return nil, fmt.Errorf("failed to get bookmark tags: %w", err)
 	}
+	if err := r.getAnnotations(&bookmark); err != nil {
+		return nil, err
+	}
+	if err := r.getHighlights(&bookmark); err != nil {
+		return nil, err
+	}
 
 	return &bookmark, nil
 }
 
+// GetByURLKey returns the oldest bookmark with the given duplicate detection
+// key, or nil.
+func (r *BookmarkRepository) GetByURLKey(key string) (*model.Bookmark, error) {
+	var id int64
+	query := `SELECT id FROM bookmarks WHERE url_key = ? ORDER BY created_at, id LIMIT 1`
+	err := r.db.GetDB().Get(&id, query, key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get bookmark: %w", err)
+	}
+	return r.GetByID(id)
+}
+
+// DuplicateGroups returns the IDs of bookmarks sharing a URL key, one group
+// per key, oldest bookmark first.
+func (r *BookmarkRepository) DuplicateGroups() ([][]int64, error) {
+	var rows []struct {
+		ID     int64  `db:"id"`
+		URLKey string `db:"url_key"`
+	}
+	query := `
+    SELECT id, url_key FROM bookmarks
+    WHERE url_key IN (SELECT url_key FROM bookmarks WHERE url_key != '' GROUP BY url_key HAVING COUNT(*) > 1)
+    ORDER BY url_key, created_at, id
+    `
+	if err := r.db.GetDB().Select(&rows, query); err != nil {
+		return nil, fmt.Errorf("failed to find duplicate bookmarks: %w", err)
+	}
+
+	var groups [][]int64
+	for i, row := range rows {
+		if i == 0 || row.URLKey != rows[i-1].URLKey {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], row.ID)
+	}
+	return groups, nil
+}
+
+// Merge folds the bookmark dropID into keepID and deletes it. The kept
+// bookmark gains the other's tags, the earlier creation date, its
+// description and notes, its star, pin and rating if it has none, and
+// takes over its annotations, highlights, content versions and archives;
+// the other's last content is kept as a version too. The dropped URL is
+// recorded so GetMergedInto finds the kept bookmark.
+func (r *BookmarkRepository) Merge(keepID, dropID int64) error {
+	if keepID == dropID {
+		return fmt.Errorf("cannot merge a bookmark into itself")
+	}
+
+	tx, err := r.db.GetDB().Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var keep, drop model.Bookmark
+	for _, b := range []struct {
+		dst *model.Bookmark
+		id  int64
+	}{{&keep, keepID}, {&drop, dropID}} {
+		if err := tx.Get(b.dst, `SELECT * FROM bookmarks WHERE id = ?`, b.id); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("bookmark %d not found", b.id)
+			}
+			return fmt.Errorf("failed to get bookmark: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`
+    INSERT OR IGNORE INTO bookmark_tags (bookmark_id, tag_id)
+    SELECT ?, tag_id FROM bookmark_tags WHERE bookmark_id = ?
+    `, keepID, dropID)
+	if err != nil {
+		return fmt.Errorf("failed to merge bookmark tags: %w", err)
+	}
+
+	createdAt := keep.CreatedAt
+	if drop.CreatedAt.Before(createdAt) {
+		createdAt = drop.CreatedAt
+	}
+	rating := keep.Rating
+	if rating == 0 {
+		rating = drop.Rating
+	}
+	_, err = tx.Exec(`
+    UPDATE bookmarks
+       SET created_at = ?, description = ?, notes = ?, starred = ?, pinned = ?, rating = ?, updated_at = ?
+     WHERE id = ?
+    `, createdAt, mergeText(keep.Description, drop.Description), mergeText(keep.Notes, drop.Notes),
+		keep.Starred || drop.Starred, keep.Pinned || drop.Pinned, rating, time.Now(), keepID)
+	if err != nil {
+		return fmt.Errorf("failed to update merged bookmark: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE annotations SET bookmark_id = ? WHERE bookmark_id = ?`, keepID, dropID); err != nil {
+		return fmt.Errorf("failed to move annotations: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE highlights SET bookmark_id = ? WHERE bookmark_id = ?`, keepID, dropID); err != nil {
+		return fmt.Errorf("failed to move highlights: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE bookmark_content_versions SET bookmark_id = ? WHERE bookmark_id = ?`, keepID, dropID); err != nil {
+		return fmt.Errorf("failed to move content versions: %w", err)
+	}
+	if drop.Content != "" && drop.Content != keep.Content {
+		fetchedAt := drop.CreatedAt
+		if drop.FetchedAt != nil {
+			fetchedAt = *drop.FetchedAt
+		}
+		version := model.NewContentVersion(keepID, drop.Content, fetchedAt)
+		_, err := tx.Exec(`
+      INSERT INTO bookmark_content_versions (bookmark_id, hash, content, fetched_at, created_at)
+      VALUES (?, ?, ?, ?, ?)
+      `, version.BookmarkID, version.Hash, version.Content, version.FetchedAt, version.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert content version: %w", err)
+		}
+	}
+	if _, err := tx.Exec(`UPDATE archives SET bookmark_id = ? WHERE bookmark_id = ?`, keepID, dropID); err != nil {
+		return fmt.Errorf("failed to move archives: %w", err)
+	}
+
+	// Bookmarks merged into the dropped one now belong to the kept one.
+	if _, err := tx.Exec(`UPDATE bookmark_merges SET bookmark_id = ? WHERE bookmark_id = ?`, keepID, dropID); err != nil {
+		return fmt.Errorf("failed to update merge records: %w", err)
+	}
+	_, err = tx.Exec(`INSERT OR REPLACE INTO bookmark_merges (url, url_key, bookmark_id, merged_at) VALUES (?, ?, ?, ?)`,
+		drop.URL, drop.URLKey, keepID, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record merge: %w", err)
+	}
+
+	// The kept bookmark takes the dropped one's place in a collection if it
+	// is in none itself.
+	_, err = tx.Exec(`
+    INSERT OR IGNORE INTO collection_bookmarks (bookmark_id, collection_id, position)
+    SELECT ?, collection_id, position FROM collection_bookmarks WHERE bookmark_id = ?
+    `, keepID, dropID)
+	if err != nil {
+		return fmt.Errorf("failed to move collection entry: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM bookmarks WHERE id = ?`, dropID); err != nil {
+		return fmt.Errorf("failed to delete merged bookmark: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// mergeText joins two texts, leaving out the second if the first already
+// contains it.
+func mergeText(a, b string) string {
+	switch {
+	case b == "" || strings.Contains(a, b):
+		return a
+	case a == "" || strings.Contains(b, a):
+		return b
+	default:
+		return a + "\n\n" + b
+	}
+}
+
+// GetMergedInto returns the bookmark that a bookmark with the given URL or
+// URL key was merged into, or nil.
+func (r *BookmarkRepository) GetMergedInto(url, key string) (*model.Bookmark, error) {
+	var id int64
+	query := `SELECT bookmark_id FROM bookmark_merges WHERE url = ? OR (url_key != '' AND url_key = ?) LIMIT 1`
+	err := r.db.GetDB().Get(&id, query, url, key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to look up merged bookmark: %w", err)
+	}
+	return r.GetByID(id)
+}
+
+// Sort orders accepted by ListOptions.
+const (
+	SortNewest      = "newest"
+	SortOldest      = "oldest"
+	SortTitle       = "title"
+	SortReadingTime = "readtime"
+	SortWordCount   = "words"
+	// SortPriority orders the read-later queue: highest priority first,
+	// then oldest first.
+	SortPriority = "priority"
+	SortRating   = "rating"
+)
+
+var sortClauses = map[string]string{
+	"":              "b.created_at DESC",
+	SortNewest:      "b.created_at DESC",
+	SortOldest:      "b.created_at ASC",
+	SortTitle:       "b.title COLLATE NOCASE ASC",
+	SortReadingTime: "b.reading_time ASC, b.created_at DESC",
+	SortPriority:    "b.priority DESC, b.created_at ASC",
+	SortWordCount:   "b.word_count ASC, b.created_at DESC",
+	SortRating:      "b.rating DESC, b.created_at DESC",
+}
+
+// ListOptions filters and pages the bookmarks returned by Find.
+type ListOptions struct {
+	Tag string
+	// Collection keeps the bookmarks of a collection, in the collection's
+	// order unless sorted otherwise.
+	Collection int64
+	// ReadStates keeps bookmarks in any of the given read states.
+	ReadStates []string
+	Health     string
+	Lang       string
+	// A zero MinReadingTime or nil MaxReadingTime leaves the reading time
+	// range open on that side.
+	MinReadingTime int
+	MaxReadingTime *int
+	Starred        bool
+	Pinned         bool
+	// MinRating and MaxRating work alike, but any rating range leaves out
+	// unrated bookmarks.
+	MinRating int
+	MaxRating *int
+	// PinnedFirst lists pinned bookmarks before the others, except in a
+	// collection's own order.
+	PinnedFirst bool
+	Sort        string
+	// A negative Limit returns all matching bookmarks.
+	Limit  int
+	Offset int
+}
+
+// List returns the bookmarks with a tag, or all of them, pinned ones first.
 func (r *BookmarkRepository) List(tag string, limit, offset int) ([]*model.Bookmark, error) {
+	return r.Find(ListOptions{Tag: tag, PinnedFirst: true, Limit: limit, Offset: offset})
+}
+
+func (r *BookmarkRepository) Find(opts ListOptions) ([]*model.Bookmark, error) {
 	var bookmarks []*model.Bookmark
-	var query string
+	var joins, conditions []string
 	var args []interface{}
 
-	if tag != "" {
-		// filter by tag
-		query = `
-     SELECT b.*
-      FROM bookmarks b
-      JOIN bookmark_tags bt ON bt.bookmark_id = b.id
-      JOIN tags t ON t.id = bt.tag_id
-      WHERE t.name = ?
-      ORDER BY b.created_at DESC
-      LIMIT ? OFFSET ?
-      `
-		args = []interface{}{tag, limit, offset}
-	} else {
-		// get all bookmarks
-		query = `
-    SELECT * FROM bookmarks
-    ORDER BY created_at DESC
-    LIMIT ? OFFSET ?
-    `
-		args = []interface{}{limit, offset}
+	if opts.Tag != "" {
+		// filter by tag, including its descendants
+		conditions = append(conditions, `EXISTS (
+      SELECT 1 FROM bookmark_tags bt JOIN tags t ON t.id = bt.tag_id
+      WHERE bt.bookmark_id = b.id AND `+tagSubtree("t.name")+`)`)
+		args = append(args, subtreeArgs(model.NormalizeTagName(opts.Tag))...)
+	}
+
+	if opts.Collection != 0 {
+		joins = append(joins, "JOIN collection_bookmarks cb ON cb.bookmark_id = b.id")
+		conditions = append(conditions, "cb.collection_id = ?")
+		args = append(args, opts.Collection)
+	}
+
+	switch opts.Health {
+	case "":
+	case model.LinkHealthBroken:
+		joins = append(joins, "JOIN link_status ls ON ls.bookmark_id = b.id")
+		conditions = append(conditions, "ls.consecutive_failures >= ?")
+		args = append(args, model.BrokenAfterFailures)
+	case model.LinkHealthMoved:
+		joins = append(joins, "JOIN link_status ls ON ls.bookmark_id = b.id")
+		conditions = append(conditions, "ls.moved = 1 AND ls.consecutive_failures = 0")
+	default:
+		return nil, fmt.Errorf("unknown link health filter %q", opts.Health)
 	}
 
+	if len(opts.ReadStates) > 0 {
+		conditions = append(conditions, "b.read_state IN (?"+strings.Repeat(", ?", len(opts.ReadStates)-1)+")")
+		for _, state := range opts.ReadStates {
+			args = append(args, state)
+		}
+	}
+
+	if opts.Lang != "" {
+		conditions = append(conditions, "b.lang = ?")
+		args = append(args, opts.Lang)
+	}
+	if opts.MinReadingTime > 0 {
+		conditions = append(conditions, "b.reading_time >= ?")
+		args = append(args, opts.MinReadingTime)
+	}
+	if opts.MaxReadingTime != nil {
+		conditions = append(conditions, "b.reading_time <= ?")
+		args = append(args, *opts.MaxReadingTime)
+	}
+
+	if opts.Starred {
+		conditions = append(conditions, "b.starred = 1")
+	}
+	if opts.Pinned {
+		conditions = append(conditions, "b.pinned = 1")
+	}
+	if opts.MinRating > 0 || opts.MaxRating != nil {
+		conditions = append(conditions, "b.rating >= ?")
+		args = append(args, max(opts.MinRating, 1))
+	}
+	if opts.MaxRating != nil {
+		conditions = append(conditions, "b.rating <= ?")
+		args = append(args, *opts.MaxRating)
+	}
+
+	order, ok := sortClauses[opts.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort order %q", opts.Sort)
+	}
+	if opts.Collection != 0 && opts.Sort == "" {
+		order = "cb.position, b.id"
+	} else if opts.PinnedFirst {
+		order = "b.pinned DESC, " + order
+	}
+
+	query := "SELECT b.* FROM bookmarks b"
+	if len(joins) > 0 {
+		query += " " + strings.Join(joins, " ")
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY " + order + " LIMIT ? OFFSET ?"
+	args = append(args, opts.Limit, opts.Offset)
+
 	if err := r.db.GetDB().Select(&bookmarks, query, args...); err != nil {
 		return nil, fmt.Errorf("failed to list bookmarks: %w", err)
 	}
 
-	for _, bookmark := range bookmarks {
-		tagsQuery := `
-    SELECT t.id, t.name
+	if err := r.loadRelated(bookmarks); err != nil {
+		return nil, err
+	}
+
+	return bookmarks, nil
+}
+
+// relatedBatchSize caps the bookmark IDs loadRelated puts in one query, well
+// below SQLite's limit on query parameters.
+const relatedBatchSize = 500
+
+// loadRelated loads the tags, annotations and highlights of bookmarks with a
+// query for each per batch of bookmarks, rather than per bookmark.
+func (r *BookmarkRepository) loadRelated(bookmarks []*model.Bookmark) error {
+	byID := make(map[int64]*model.Bookmark, len(bookmarks))
+	ids := make([]int64, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		byID[bookmark.ID] = bookmark
+		ids[i] = bookmark.ID
+	}
+
+	for len(ids) > 0 {
+		batch := ids[:min(relatedBatchSize, len(ids))]
+		ids = ids[len(batch):]
+
+		var tags []struct {
+			BookmarkID int64 `db:"bookmark_id"`
+			model.Tag
+		}
+		if err := r.selectIn(&tags, `
+    SELECT bt.bookmark_id, t.id, t.name
     FROM tags t
     JOIN bookmark_tags bt ON bt.tag_id = t.id
-    WHERE bt.bookmark_id = ?
-    `
-		if err := r.db.GetDB().Select(&bookmark.Tags, tagsQuery, bookmark.ID); err != nil {
-			return nil, fmt.Errorf("failed to get bookmark tags: %w", err)
+    WHERE bt.bookmark_id IN (?)
+    `, batch); err != nil {
+			return fmt.Errorf("failed to get bookmark tags: %w", err)
+		}
+		for _, tag := range tags {
+			bookmark := byID[tag.BookmarkID]
+			bookmark.Tags = append(bookmark.Tags, tag.Tag)
 		}
+
+		var annotations []model.Annotation
+		if err := r.selectIn(&annotations, `SELECT * FROM annotations WHERE bookmark_id IN (?) ORDER BY created_at, id`, batch); err != nil {
+			return fmt.Errorf("failed to get bookmark annotations: %w", err)
+		}
+		for _, annotation := range annotations {
+			bookmark := byID[annotation.BookmarkID]
+			bookmark.Annotations = append(bookmark.Annotations, annotation)
+		}
+
+		var highlights []model.Highlight
+		if err := r.selectIn(&highlights, `SELECT * FROM highlights WHERE bookmark_id IN (?) ORDER BY text_offset, id`, batch); err != nil {
+			return fmt.Errorf("failed to get bookmark highlights: %w", err)
+		}
+		for _, highlight := range highlights {
+			bookmark := byID[highlight.BookmarkID]
+			bookmark.Highlights = append(bookmark.Highlights, highlight)
+		}
+	}
+	return nil
+}
+
+// selectIn runs a query whose IN (?) placeholders are expanded to args.
+func (r *BookmarkRepository) selectIn(dest interface{}, query string, args ...interface{}) error {
+	query, args, err := sqlx.In(query, args...)
+	if err != nil {
+		return err
 	}
+	return r.db.GetDB().Select(dest, query, args...)
+}
 
-	return bookmarks, nil
+// getAnnotations loads a bookmark's annotations, oldest first.
+func (r *BookmarkRepository) getAnnotations(bookmark *model.Bookmark) error {
+	query := `SELECT * FROM annotations WHERE bookmark_id = ? ORDER BY created_at, id`
+	if err := r.db.GetDB().Select(&bookmark.Annotations, query, bookmark.ID); err != nil {
+		return fmt.Errorf("failed to get bookmark annotations: %w", err)
+	}
+	return nil
+}
 
+// getHighlights loads a bookmark's highlights in the order they appear in
+// its content.
+func (r *BookmarkRepository) getHighlights(bookmark *model.Bookmark) error {
+	query := `SELECT * FROM highlights WHERE bookmark_id = ? ORDER BY text_offset, id`
+	if err := r.db.GetDB().Select(&bookmark.Highlights, query, bookmark.ID); err != nil {
+		return fmt.Errorf("failed to get bookmark highlights: %w", err)
+	}
+	return nil
 }
 
 func (r *BookmarkRepository) Update(bookmark *model.Bookmark) error {
@@ -172,10 +561,10 @@ func (r *BookmarkRepository) Update(bookmark *model.Bookmark) error {
 	bookmark.UpdatedAt = time.Now()
 	query := `
    UPDATE bookmarks
-   SET url = ?, title = ?, description = ?, content = ?, summary = ?, updated_at = ?
+   SET url = ?, url_key = ?, title = ?, description = ?, content = ?, summary = ?, fetch_state = ?, updated_at = ?
    WHERE id = ?
   `
-	_, err = tx.Exec(query, bookmark.URL, bookmark.Title, bookmark.Description, bookmark.Content, bookmark.Summary, bookmark.UpdatedAt, bookmark.ID)
+	_, err = tx.Exec(query, bookmark.URL, bookmark.URLKey, bookmark.Title, bookmark.Description, bookmark.Content, bookmark.Summary, bookmark.FetchState, bookmark.UpdatedAt, bookmark.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update bookmark: %w", err)
 	}
@@ -188,10 +577,9 @@ func (r *BookmarkRepository) Update(bookmark *model.Bookmark) error {
 	for i := range bookmark.Tags {
 		tag := &bookmark.Tags[i]
 		if tag.ID == 0 {
-			tagQuery := `INSERT INTO tags (name) VALUES (?) ON CONFLICT(name) DO UPDATE SET name=name repository id`
-			err := tx.Get(&tag.ID, tagQuery, tag.Name)
+			tag.ID, err = ensureTag(tx, tag.Name)
 			if err != nil {
-				return fmt.Errorf("failed to insert tag: %w", err)
+				return err
 			}
 		}
 
@@ -208,6 +596,150 @@ func (r *BookmarkRepository) Update(bookmark *model.Bookmark) error {
 	return nil
 }
 
+// UpdateExtracted writes only the fields produced by content extraction, so a
+// background fetch does not clobber tag edits made while it was running.
+func (r *BookmarkRepository) UpdateExtracted(bookmark *model.Bookmark) error {
+	bookmark.UpdatedAt = time.Now()
+	query := `
+   UPDATE bookmarks
+   SET title = ?, description = ?, content = ?, summary = ?, author = ?, content_type = ?, page_count = ?,
+       word_count = ?, reading_time = ?, lang = ?, published_at = ?, favicon = ?, url_key = ?,
+       fetch_state = ?, etag = ?, last_modified = ?, fetched_at = ?, updated_at = ?
+   WHERE id = ?
+  `
+	_, err := r.db.GetDB().Exec(query, bookmark.Title, bookmark.Description, bookmark.Content, bookmark.Summary,
+		bookmark.Author, bookmark.ContentType, bookmark.PageCount,
+		bookmark.WordCount, bookmark.ReadingTime, bookmark.Lang, bookmark.PublishedAt, bookmark.Favicon, bookmark.URLKey, bookmark.FetchState,
+		bookmark.ETag, bookmark.LastModified, bookmark.FetchedAt, bookmark.UpdatedAt, bookmark.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update bookmark content: %w", err)
+	}
+	return nil
+}
+
+// updatableColumns are the bookmark columns UpdateFields may write.
+var updatableColumns = map[string]bool{
+	"title":         true,
+	"description":   true,
+	"content":       true,
+	"summary":       true,
+	"author":        true,
+	"content_type":  true,
+	"page_count":    true,
+	"word_count":    true,
+	"reading_time":  true,
+	"lang":          true,
+	"published_at":  true,
+	"favicon":       true,
+	"notes":         true,
+	"read_state":    true,
+	"priority":      true,
+	"read_at":       true,
+	"read_progress": true,
+	"starred":       true,
+	"pinned":        true,
+	"rating":        true,
+	"created_at":    true,
+	"url_key":       true,
+	"fetch_state":   true,
+	"etag":          true,
+	"last_modified": true,
+	"fetched_at":    true,
+	"updated_at":    true,
+}
+
+// UpdateFields writes only the given columns of a bookmark.
+func (r *BookmarkRepository) UpdateFields(id int64, fields map[string]interface{}) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	columns := make([]string, 0, len(fields))
+	for column := range fields {
+		if !updatableColumns[column] {
+			return fmt.Errorf("column %q cannot be updated", column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	assignments := make([]string, len(columns))
+	args := make([]interface{}, 0, len(columns)+1)
+	for i, column := range columns {
+		assignments[i] = column + " = ?"
+		args = append(args, fields[column])
+	}
+	args = append(args, id)
+
+	query := fmt.Sprintf(`UPDATE bookmarks SET %s WHERE id = ?`, strings.Join(assignments, ", "))
+	if _, err := r.db.GetDB().Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to update bookmark: %w", err)
+	}
+	return nil
+}
+
+// ListStale returns bookmarks that have not been fetched since before,
+// optionally restricted to those carrying tag.
+func (r *BookmarkRepository) ListStale(tag string, before time.Time) ([]*model.Bookmark, error) {
+	var bookmarks []*model.Bookmark
+	var query string
+	var args []interface{}
+
+	if tag != "" {
+		query = `
+    SELECT b.*
+    FROM bookmarks b
+    WHERE EXISTS (
+      SELECT 1 FROM bookmark_tags bt JOIN tags t ON t.id = bt.tag_id
+      WHERE bt.bookmark_id = b.id AND ` + tagSubtree("t.name") + `
+    ) AND (b.fetched_at IS NULL OR b.fetched_at < ?)
+    ORDER BY b.fetched_at
+    `
+		args = append(subtreeArgs(model.NormalizeTagName(tag)), before)
+	} else {
+		query = `
+    SELECT * FROM bookmarks
+    WHERE fetched_at IS NULL OR fetched_at < ?
+    ORDER BY fetched_at
+    `
+		args = []interface{}{before}
+	}
+
+	if err := r.db.GetDB().Select(&bookmarks, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list stale bookmarks: %w", err)
+	}
+	return bookmarks, nil
+}
+
+// ListMissingStats returns fetched bookmarks whose word count, reading time
+// and language have not been computed yet.
+func (r *BookmarkRepository) ListMissingStats() ([]*model.Bookmark, error) {
+	var bookmarks []*model.Bookmark
+	query := `SELECT * FROM bookmarks WHERE content != '' AND word_count = 0`
+	if err := r.db.GetDB().Select(&bookmarks, query); err != nil {
+		return nil, fmt.Errorf("failed to list bookmarks without stats: %w", err)
+	}
+	return bookmarks, nil
+}
+
+// ListMissingURLKey returns bookmarks saved before URL keys were stored.
+func (r *BookmarkRepository) ListMissingURLKey() ([]*model.Bookmark, error) {
+	var bookmarks []*model.Bookmark
+	query := `SELECT * FROM bookmarks WHERE url_key = ''`
+	if err := r.db.GetDB().Select(&bookmarks, query); err != nil {
+		return nil, fmt.Errorf("failed to list bookmarks without URL key: %w", err)
+	}
+	return bookmarks, nil
+}
+
+func (r *BookmarkRepository) SetFetchState(id int64, state string) error {
+	_, err := r.db.GetDB().Exec(`UPDATE bookmarks SET fetch_state = ? WHERE id = ?`, state, id)
+	if err != nil {
+		return fmt.Errorf("failed to update fetch state: %w", err)
+	}
+	return nil
+}
+
 func (r *BookmarkRepository) Delete(id int64) error {
 	_, err := r.db.GetDB().Exec(`DELETE FROM bookmarks WHERE id = ?`, id)
 	if err != nil {
@@ -216,6 +748,133 @@ func (r *BookmarkRepository) Delete(id int64) error {
 	return nil
 }
 
+func (r *BookmarkRepository) AddAnnotation(annotation *model.Annotation) error {
+	res, err := r.db.GetDB().Exec(`
+    INSERT INTO annotations (bookmark_id, text, created_at, updated_at)
+    VALUES (?, ?, ?, ?)
+    `, annotation.BookmarkID, annotation.Text, annotation.CreatedAt, annotation.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert annotation: %w", err)
+	}
+	annotation.ID, err = res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+	return nil
+}
+
+func (r *BookmarkRepository) GetAnnotation(id int64) (*model.Annotation, error) {
+	var annotation model.Annotation
+	err := r.db.GetDB().Get(&annotation, `SELECT * FROM annotations WHERE id = ?`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get annotation: %w", err)
+	}
+	return &annotation, nil
+}
+
+func (r *BookmarkRepository) UpdateAnnotation(annotation *model.Annotation) error {
+	annotation.UpdatedAt = time.Now().UTC()
+	_, err := r.db.GetDB().Exec(`UPDATE annotations SET text = ?, updated_at = ? WHERE id = ?`,
+		annotation.Text, annotation.UpdatedAt, annotation.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update annotation: %w", err)
+	}
+	return nil
+}
+
+func (r *BookmarkRepository) DeleteAnnotation(id int64) error {
+	if _, err := r.db.GetDB().Exec(`DELETE FROM annotations WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete annotation: %w", err)
+	}
+	return nil
+}
+
+func (r *BookmarkRepository) AddHighlight(highlight *model.Highlight) error {
+	res, err := r.db.GetDB().Exec(`
+    INSERT INTO highlights (bookmark_id, text, text_offset, comment, created_at)
+    VALUES (?, ?, ?, ?, ?)
+    `, highlight.BookmarkID, highlight.Text, highlight.Offset, highlight.Comment, highlight.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert highlight: %w", err)
+	}
+	highlight.ID, err = res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+	return nil
+}
+
+func (r *BookmarkRepository) GetHighlight(id int64) (*model.Highlight, error) {
+	var highlight model.Highlight
+	err := r.db.GetDB().Get(&highlight, `SELECT * FROM highlights WHERE id = ?`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get highlight: %w", err)
+	}
+	return &highlight, nil
+}
+
+// ListHighlights returns the highlights across all bookmarks whose passage
+// or comment contains query, ignoring case, newest first. An empty query
+// returns every highlight.
+func (r *BookmarkRepository) ListHighlights(query string) ([]*model.Highlight, error) {
+	var highlights []*model.Highlight
+	err := r.db.GetDB().Select(&highlights, `
+    SELECT * FROM highlights
+    WHERE ? = '' OR instr(lower(text), lower(?)) > 0 OR instr(lower(comment), lower(?)) > 0
+    ORDER BY created_at DESC, id DESC
+    `, query, query, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list highlights: %w", err)
+	}
+	return highlights, nil
+}
+
+func (r *BookmarkRepository) SetHighlightComment(id int64, comment string) error {
+	if _, err := r.db.GetDB().Exec(`UPDATE highlights SET comment = ? WHERE id = ?`, comment, id); err != nil {
+		return fmt.Errorf("failed to update highlight: %w", err)
+	}
+	return nil
+}
+
+func (r *BookmarkRepository) DeleteHighlight(id int64) error {
+	if _, err := r.db.GetDB().Exec(`DELETE FROM highlights WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete highlight: %w", err)
+	}
+	return nil
+}
+
+// AddTags attaches tags to a bookmark, creating them as needed. Unlike Update
+// it leaves the bookmark's other fields and tags alone.
+func (r *BookmarkRepository) AddTags(bookmarkID int64, names []string) error {
+	tx, err := r.db.GetDB().Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, name := range names {
+		tagID, err := ensureTag(tx, name)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT OR IGNORE INTO bookmark_tags (bookmark_id, tag_id) VALUES (?, ?)`, bookmarkID, tagID)
+		if err != nil {
+			return fmt.Errorf("failed to insert bookmark-tag relation: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
 func (r *BookmarkRepository) GetAllTags() ([]model.Tag, error) {
 	var tags []model.Tag
 	query := `SELECT * FROM tags ORDER BY name`
@@ -224,3 +883,558 @@ func (r *BookmarkRepository) GetAllTags() ([]model.Tag, error) {
 	}
 	return tags, nil
 }
+
+// GetTagByName returns the tag with the given name, or nil.
+func (r *BookmarkRepository) GetTagByName(name string) (*model.Tag, error) {
+	var tag model.Tag
+	err := r.db.GetDB().Get(&tag, `SELECT * FROM tags WHERE name = ?`, model.NormalizeTagName(name))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	return &tag, nil
+}
+
+// ensureTag returns the ID of the tag called name, once normalized, creating
+// it and any missing ancestors.
+func ensureTag(tx *sqlx.Tx, name string) (int64, error) {
+	name = model.NormalizeTagName(name)
+	if name == "" {
+		return 0, fmt.Errorf("invalid tag name")
+	}
+
+	var parentID *int64
+	if parent := model.ParentTagName(name); parent != "" {
+		id, err := ensureTag(tx, parent)
+		if err != nil {
+			return 0, err
+		}
+		parentID = &id
+	}
+
+	var id int64
+	query := `INSERT INTO tags (name, parent_id) VALUES (?, ?) ON CONFLICT(name) DO UPDATE SET parent_id = excluded.parent_id RETURNING id`
+	if err := tx.Get(&id, query, name, parentID); err != nil {
+		return 0, fmt.Errorf("failed to insert tag: %w", err)
+	}
+	return id, nil
+}
+
+// tagSubtreeOf is a condition matching the tag name expression name against
+// the tag root and its descendants.
+func tagSubtreeOf(name, root string) string {
+	return fmt.Sprintf("(%[1]s = %[2]s OR substr(%[1]s, 1, length(%[2]s) + 1) = %[2]s || '%[3]s')", name, root, model.TagSeparator)
+}
+
+// tagSubtree is tagSubtreeOf a placeholder. It takes the root tag name three
+// times as arguments; see subtreeArgs.
+func tagSubtree(name string) string {
+	return tagSubtreeOf(name, "?")
+}
+
+func subtreeArgs(tag string) []interface{} {
+	return []interface{}{tag, tag, tag}
+}
+
+// taggedBookmarkIDs returns the IDs of the bookmarks carrying any of the tags.
+func taggedBookmarkIDs(tx *sqlx.Tx, tagIDs []int64) ([]int64, error) {
+	if len(tagIDs) == 0 {
+		return nil, nil
+	}
+	query, args, err := sqlx.In(`SELECT DISTINCT bookmark_id FROM bookmark_tags WHERE tag_id IN (?) ORDER BY bookmark_id`, tagIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tagged bookmarks query: %w", err)
+	}
+	var ids []int64
+	if err := tx.Select(&ids, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list tagged bookmarks: %w", err)
+	}
+	return ids, nil
+}
+
+// moveTagTree renames the tag oldName and its descendants to live under
+// newName, merging them into tags that already exist. It returns the IDs of
+// the bookmarks carrying any of the moved tags.
+func moveTagTree(tx *sqlx.Tx, oldName, newName string) ([]int64, error) {
+	oldName, newName = model.NormalizeTagName(oldName), model.NormalizeTagName(newName)
+	if newName == "" {
+		return nil, fmt.Errorf("invalid tag name")
+	}
+	if oldName == newName {
+		return nil, nil
+	}
+	if model.InTagSubtree(newName, oldName) {
+		return nil, fmt.Errorf("cannot move tag %q under itself", oldName)
+	}
+
+	// Parents sort before their children, so each child's new parent
+	// exists by the time it is moved.
+	var tags []model.Tag
+	if err := tx.Select(&tags, `SELECT * FROM tags t WHERE `+tagSubtree("t.name")+` ORDER BY name`, subtreeArgs(oldName)...); err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	if len(tags) == 0 || tags[0].Name != oldName {
+		return nil, fmt.Errorf("tag %q not found", oldName)
+	}
+
+	tagIDs := make([]int64, len(tags))
+	for i, tag := range tags {
+		tagIDs[i] = tag.ID
+	}
+	ids, err := taggedBookmarkIDs(tx, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`UPDATE tag_aliases SET tag = ? || substr(tag, length(?) + 1) WHERE `+tagSubtree("tag"),
+		append([]interface{}{newName, oldName}, subtreeArgs(oldName)...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update tag aliases: %w", err)
+	}
+
+	for _, tag := range tags {
+		target := newName + strings.TrimPrefix(tag.Name, oldName)
+
+		var targetID int64
+		err := tx.Get(&targetID, `SELECT id FROM tags WHERE name = ?`, target)
+		if err == sql.ErrNoRows {
+			var parentID *int64
+			if parent := model.ParentTagName(target); parent != "" {
+				id, err := ensureTag(tx, parent)
+				if err != nil {
+					return nil, err
+				}
+				parentID = &id
+			}
+			if _, err := tx.Exec(`UPDATE tags SET name = ?, parent_id = ? WHERE id = ?`, target, parentID, tag.ID); err != nil {
+				return nil, fmt.Errorf("failed to rename tag: %w", err)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tag: %w", err)
+		}
+
+		_, err = tx.Exec(`
+      INSERT OR IGNORE INTO bookmark_tags (bookmark_id, tag_id)
+      SELECT bookmark_id, ? FROM bookmark_tags WHERE tag_id = ?
+      `, targetID, tag.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to move tagged bookmarks: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM tags WHERE id = ?`, tag.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete tag: %w", err)
+		}
+	}
+	return ids, nil
+}
+
+// RenameTag renames a tag along with its descendants, so renaming "lang" to
+// "language" turns "lang/go" into "language/go". Tags that already exist
+// under the new name are merged. It returns the IDs of the bookmarks
+// carrying any of the renamed tags.
+func (r *BookmarkRepository) RenameTag(oldName, newName string) ([]int64, error) {
+	tx, err := r.db.GetDB().Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ids, err := moveTagTree(tx, oldName, newName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return ids, nil
+}
+
+// MergeTags moves every bookmark tagged with one of sources to target,
+// creating target if needed, and deletes the sources. Descendants of a
+// source move under target. It returns the IDs of the bookmarks whose tags
+// changed.
+func (r *BookmarkRepository) MergeTags(sources []string, target string) ([]int64, error) {
+	tx, err := r.db.GetDB().Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := ensureTag(tx, target); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool)
+	var ids []int64
+	for _, source := range sources {
+		moved, err := moveTagTree(tx, source, target)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range moved {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return ids, nil
+}
+
+// DeleteTag removes a tag and its descendants from every bookmark and
+// deletes them. It returns the IDs of the bookmarks that carried them.
+func (r *BookmarkRepository) DeleteTag(name string) ([]int64, error) {
+	tx, err := r.db.GetDB().Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	name = model.NormalizeTagName(name)
+	var tagIDs []int64
+	if err := tx.Select(&tagIDs, `SELECT id FROM tags t WHERE `+tagSubtree("t.name"), subtreeArgs(name)...); err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	if len(tagIDs) == 0 {
+		return nil, fmt.Errorf("tag %q not found", name)
+	}
+	ids, err := taggedBookmarkIDs(tx, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM tags WHERE `+tagSubtree("name"), subtreeArgs(name)...); err != nil {
+		return nil, fmt.Errorf("failed to delete tag: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM tag_aliases WHERE `+tagSubtree("tag"), subtreeArgs(name)...); err != nil {
+		return nil, fmt.Errorf("failed to delete tag aliases: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return ids, nil
+}
+
+// PruneTags deletes tags that neither they nor any of their descendants are
+// carried by a bookmark, and returns their names.
+func (r *BookmarkRepository) PruneTags() ([]string, error) {
+	tx, err := r.db.GetDB().Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var names []string
+	unused := `
+    FROM tags WHERE NOT EXISTS (
+      SELECT 1 FROM bookmark_tags bt JOIN tags used ON used.id = bt.tag_id
+      WHERE ` + tagSubtreeOf("used.name", "tags.name") + `
+    )`
+	if err := tx.Select(&names, `SELECT name `+unused+` ORDER BY name`); err != nil {
+		return nil, fmt.Errorf("failed to list unused tags: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE ` + unused); err != nil {
+		return nil, fmt.Errorf("failed to delete unused tags: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return names, nil
+}
+
+// TagAliases returns every tag alias, ordered by tag and alias.
+func (r *BookmarkRepository) TagAliases() ([]model.TagAlias, error) {
+	var aliases []model.TagAlias
+	if err := r.db.GetDB().Select(&aliases, `SELECT alias, tag FROM tag_aliases ORDER BY tag, alias`); err != nil {
+		return nil, fmt.Errorf("failed to list tag aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// ResolveTags normalizes names and replaces tag aliases among them with the
+// tags they stand for.
+func (r *BookmarkRepository) ResolveTags(names ...string) ([]string, error) {
+	aliases, err := r.TagAliases()
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string, len(aliases))
+	for _, alias := range aliases {
+		tags[alias.Alias] = alias.Tag
+	}
+	resolved := make([]string, len(names))
+	for i, name := range names {
+		resolved[i] = model.ResolveTagAlias(model.NormalizeTagName(name), tags)
+	}
+	return resolved, nil
+}
+
+// SetTagAlias makes alias another name for tag. Aliases of alias are pointed
+// at tag, and bookmarks already tagged with alias, or one of its
+// descendants, are retagged. It returns the IDs of the retagged bookmarks.
+func (r *BookmarkRepository) SetTagAlias(alias, tag string) ([]int64, error) {
+	alias, tag = model.NormalizeTagName(alias), model.NormalizeTagName(tag)
+	if alias == "" || tag == "" {
+		return nil, fmt.Errorf("invalid tag name")
+	}
+	if model.InTagSubtree(tag, alias) {
+		return nil, fmt.Errorf("cannot alias tag %q to itself", alias)
+	}
+
+	tx, err := r.db.GetDB().Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE tag_aliases SET tag = ? WHERE tag = ?`, tag, alias); err != nil {
+		return nil, fmt.Errorf("failed to update tag aliases: %w", err)
+	}
+	query := `INSERT INTO tag_aliases (alias, tag) VALUES (?, ?) ON CONFLICT(alias) DO UPDATE SET tag = excluded.tag`
+	if _, err := tx.Exec(query, alias, tag); err != nil {
+		return nil, fmt.Errorf("failed to save tag alias: %w", err)
+	}
+
+	var ids []int64
+	var existing int
+	if err := tx.Get(&existing, `SELECT COUNT(*) FROM tags WHERE name = ?`, alias); err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	if existing > 0 {
+		if ids, err = moveTagTree(tx, alias, tag); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return ids, nil
+}
+
+// DeleteTagAlias removes a tag alias. Bookmarks tagged through it keep the
+// tag it stood for.
+func (r *BookmarkRepository) DeleteTagAlias(alias string) error {
+	result, err := r.db.GetDB().Exec(`DELETE FROM tag_aliases WHERE alias = ?`, model.NormalizeTagName(alias))
+	if err != nil {
+		return fmt.Errorf("failed to delete tag alias: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("tag alias %q not found", alias)
+	}
+	return nil
+}
+
+// NormalizeTags renames tags saved before tag names were normalized,
+// merging tags whose names normalize to the same one, such as "Go" and
+// "go ". Tags that normalize to nothing are deleted. Tag aliases are
+// normalized too. It returns the IDs of the bookmarks whose tags changed.
+func (r *BookmarkRepository) NormalizeTags() ([]int64, error) {
+	tx, err := r.db.GetDB().Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var tags []model.Tag
+	if err := tx.Select(&tags, `SELECT * FROM tags ORDER BY name`); err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	var renamed []int64
+	for _, tag := range tags {
+		name := model.NormalizeTagName(tag.Name)
+		if name == tag.Name {
+			continue
+		}
+		renamed = append(renamed, tag.ID)
+		if name != "" {
+			targetID, err := ensureTag(tx, name)
+			if err != nil {
+				return nil, err
+			}
+			_, err = tx.Exec(`
+        INSERT OR IGNORE INTO bookmark_tags (bookmark_id, tag_id)
+        SELECT bookmark_id, ? FROM bookmark_tags WHERE tag_id = ?
+        `, targetID, tag.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to move tagged bookmarks: %w", err)
+			}
+		}
+	}
+	ids, err := taggedBookmarkIDs(tx, renamed)
+	if err != nil {
+		return nil, err
+	}
+	if len(renamed) > 0 {
+		query, args, err := sqlx.In(`DELETE FROM tags WHERE id IN (?)`, renamed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build delete tags query: %w", err)
+		}
+		if _, err := tx.Exec(query, args...); err != nil {
+			return nil, fmt.Errorf("failed to delete tags: %w", err)
+		}
+	}
+
+	var aliases []model.TagAlias
+	if err := tx.Select(&aliases, `SELECT alias, tag FROM tag_aliases`); err != nil {
+		return nil, fmt.Errorf("failed to list tag aliases: %w", err)
+	}
+	for _, alias := range aliases {
+		name, tag := model.NormalizeTagName(alias.Alias), model.NormalizeTagName(alias.Tag)
+		if name == alias.Alias && tag == alias.Tag {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM tag_aliases WHERE alias = ?`, alias.Alias); err != nil {
+			return nil, fmt.Errorf("failed to delete tag alias: %w", err)
+		}
+		if name == "" || tag == "" || model.InTagSubtree(tag, name) {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO tag_aliases (alias, tag) VALUES (?, ?)`, name, tag); err != nil {
+			return nil, fmt.Errorf("failed to save tag alias: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return ids, nil
+}
+
+// LinkTagParents sets the parents of hierarchical tags created before tags
+// had parents, creating missing ancestors. It returns the number of tags
+// linked.
+func (r *BookmarkRepository) LinkTagParents() (int, error) {
+	tx, err := r.db.GetDB().Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var names []string
+	query := `SELECT name FROM tags WHERE parent_id IS NULL AND instr(substr(name, 2), ?) > 0`
+	if err := tx.Select(&names, query, model.TagSeparator); err != nil {
+		return 0, fmt.Errorf("failed to list unlinked tags: %w", err)
+	}
+	for _, name := range names {
+		if _, err := ensureTag(tx, name); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(names), nil
+}
+
+type tagCount struct {
+	Name  string `db:"name"`
+	Count int    `db:"count"`
+}
+
+func (r *BookmarkRepository) selectTagCounts(what, query string, args ...interface{}) (map[string]int, error) {
+	var rows []tagCount
+	if err := r.db.GetDB().Select(&rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to count %s: %w", what, err)
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Name] = row.Count
+	}
+	return counts, nil
+}
+
+// TagCounts returns the number of bookmarks carrying each tag.
+func (r *BookmarkRepository) TagCounts() (map[string]int, error) {
+	query := `
+    SELECT t.name, COUNT(*) AS count
+    FROM tags t
+    JOIN bookmark_tags bt ON bt.tag_id = t.id
+    GROUP BY t.id
+    `
+	return r.selectTagCounts("tags", query)
+}
+
+// SubtreeTagCounts returns, for every tag, the number of bookmarks carrying
+// the tag or one of its descendants. Unused tags are left out.
+func (r *BookmarkRepository) SubtreeTagCounts() (map[string]int, error) {
+	query := `
+    SELECT t.name, COUNT(DISTINCT bt.bookmark_id) AS count
+    FROM tags t
+    JOIN tags d ON ` + tagSubtreeOf("d.name", "t.name") + `
+    JOIN bookmark_tags bt ON bt.tag_id = d.id
+    GROUP BY t.id
+    `
+	return r.selectTagCounts("tags", query)
+}
+
+// CooccurringTags returns, for every other tag used alongside any of tags, the
+// number of bookmarks the two share.
+func (r *BookmarkRepository) CooccurringTags(tags []string) (map[string]int, error) {
+	if len(tags) == 0 {
+		return map[string]int{}, nil
+	}
+	query, args, err := sqlx.In(`
+    SELECT other.name, COUNT(DISTINCT bt.bookmark_id) AS count
+    FROM bookmark_tags bt
+    JOIN tags t ON t.id = bt.tag_id
+    JOIN bookmark_tags obt ON obt.bookmark_id = bt.bookmark_id AND obt.tag_id != bt.tag_id
+    JOIN tags other ON other.id = obt.tag_id
+    WHERE t.name IN (?) AND other.name NOT IN (?)
+    GROUP BY other.id
+    `, tags, tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build related tags query: %w", err)
+	}
+	return r.selectTagCounts("related tags", query, args...)
+}
+
+// HostTagCounts returns how many bookmarks on host carry each tag, along with
+// the number of bookmarks on host. Bookmarks are matched on the host their
+// duplicate detection key starts with, which is lowercased and has no
+// "www." prefix.
+func (r *BookmarkRepository) HostTagCounts(host string) (map[string]int, int, error) {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	where := `(b.url_key = ? OR b.url_key LIKE ? ESCAPE '\' OR b.url_key LIKE ? ESCAPE '\')`
+	args := []interface{}{host, escapeLike(host) + "/%", escapeLike(host) + "?%"}
+
+	var total int
+	if err := r.db.GetDB().Get(&total, `SELECT COUNT(*) FROM bookmarks b WHERE `+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count bookmarks for host: %w", err)
+	}
+	if total == 0 {
+		return map[string]int{}, 0, nil
+	}
+
+	query := `
+    SELECT t.name, COUNT(*) AS count
+    FROM bookmarks b
+    JOIN bookmark_tags bt ON bt.bookmark_id = b.id
+    JOIN tags t ON t.id = bt.tag_id
+    WHERE ` + where + `
+    GROUP BY t.id
+    `
+	counts, err := r.selectTagCounts("host tags", query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return counts, total, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, for use with
+// ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
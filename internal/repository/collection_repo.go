@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/san-kum/bookmarker/internal/model"
+)
+
+type CollectionRepository struct {
+	db *Database
+}
+
+func NewCollectionRepository(db *Database) *CollectionRepository {
+	return &CollectionRepository{
+		db: db,
+	}
+}
+
+// Create saves a new collection after the last of its siblings. CreatedAt is
+// set to now unless already given.
+func (r *CollectionRepository) Create(collection *model.Collection) error {
+	if collection.CreatedAt.IsZero() {
+		collection.CreatedAt = time.Now().UTC()
+	}
+	query := `
+    INSERT INTO collections (name, parent_id, position, created_at)
+    SELECT ?, ?, COALESCE(MAX(position) + 1, 0), ?
+    FROM collections WHERE parent_id IS ?
+    RETURNING id, position
+    `
+	row := r.db.GetDB().QueryRowx(query, collection.Name, collection.ParentID, collection.CreatedAt, collection.ParentID)
+	if err := row.Scan(&collection.ID, &collection.Position); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return fmt.Errorf("collection %q already exists", collection.Name)
+		}
+		return fmt.Errorf("failed to create collection: %w", err)
+	}
+	return nil
+}
+
+func (r *CollectionRepository) GetByID(id int64) (*model.Collection, error) {
+	var collection model.Collection
+	err := r.db.GetDB().Get(&collection, `SELECT * FROM collections WHERE id = ?`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	return &collection, nil
+}
+
+// Find returns the child of parentID called name, or the top-level
+// collection called name if parentID is nil.
+func (r *CollectionRepository) Find(parentID *int64, name string) (*model.Collection, error) {
+	var collection model.Collection
+	err := r.db.GetDB().Get(&collection, `SELECT * FROM collections WHERE parent_id IS ? AND name = ?`, parentID, name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	return &collection, nil
+}
+
+// List returns every collection, each level ordered by position.
+func (r *CollectionRepository) List() ([]*model.Collection, error) {
+	var collections []*model.Collection
+	if err := r.db.GetDB().Select(&collections, `SELECT * FROM collections ORDER BY position, id`); err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	return collections, nil
+}
+
+func (r *CollectionRepository) Rename(id int64, name string) error {
+	_, err := r.db.GetDB().Exec(`UPDATE collections SET name = ? WHERE id = ?`, name, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return fmt.Errorf("collection %q already exists", name)
+		}
+		return fmt.Errorf("failed to rename collection: %w", err)
+	}
+	return nil
+}
+
+// Move makes a collection the last child of parentID, or a top-level
+// collection if parentID is nil.
+func (r *CollectionRepository) Move(id int64, parentID *int64) error {
+	tx, err := r.db.GetDB().Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for ancestor := parentID; ancestor != nil; {
+		if *ancestor == id {
+			return fmt.Errorf("cannot move a collection into itself")
+		}
+		var next *int64
+		if err := tx.Get(&next, `SELECT parent_id FROM collections WHERE id = ?`, *ancestor); err != nil {
+			return fmt.Errorf("failed to get collection: %w", err)
+		}
+		ancestor = next
+	}
+
+	query := `
+    UPDATE collections
+    SET parent_id = ?, position = (SELECT COALESCE(MAX(position) + 1, 0) FROM collections WHERE parent_id IS ?)
+    WHERE id = ?
+    `
+	if _, err := tx.Exec(query, parentID, parentID, id); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return fmt.Errorf("a collection of the same name already exists there")
+		}
+		return fmt.Errorf("failed to move collection: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a collection and its subcollections. Their bookmarks are
+// kept, outside any collection.
+func (r *CollectionRepository) Delete(id int64) error {
+	if _, err := r.db.GetDB().Exec(`DELETE FROM collections WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	return nil
+}
+
+// Entries returns where every bookmark in a collection is placed.
+func (r *CollectionRepository) Entries() ([]model.CollectionEntry, error) {
+	var entries []model.CollectionEntry
+	query := `SELECT collection_id, bookmark_id, position FROM collection_bookmarks ORDER BY collection_id, position, bookmark_id`
+	if err := r.db.GetDB().Select(&entries, query); err != nil {
+		return nil, fmt.Errorf("failed to list collection entries: %w", err)
+	}
+	return entries, nil
+}
+
+// GetEntry returns where a bookmark is placed, or nil if it is in no
+// collection.
+func (r *CollectionRepository) GetEntry(bookmarkID int64) (*model.CollectionEntry, error) {
+	var entry model.CollectionEntry
+	query := `SELECT collection_id, bookmark_id, position FROM collection_bookmarks WHERE bookmark_id = ?`
+	if err := r.db.GetDB().Get(&entry, query, bookmarkID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get collection entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// AddBookmark puts a bookmark at the end of a collection, taking it out of
+// any other. A bookmark already in the collection keeps its place.
+func (r *CollectionRepository) AddBookmark(collectionID, bookmarkID int64) error {
+	query := `
+    INSERT INTO collection_bookmarks (bookmark_id, collection_id, position)
+    SELECT ?, ?, COALESCE(MAX(position) + 1, 0) FROM collection_bookmarks WHERE collection_id = ?
+    ON CONFLICT(bookmark_id) DO UPDATE SET
+      collection_id = excluded.collection_id,
+      position = excluded.position
+    WHERE collection_id != excluded.collection_id
+    `
+	if _, err := r.db.GetDB().Exec(query, bookmarkID, collectionID, collectionID); err != nil {
+		return fmt.Errorf("failed to add bookmark to collection: %w", err)
+	}
+	return nil
+}
+
+// RemoveBookmark takes a bookmark out of its collection.
+func (r *CollectionRepository) RemoveBookmark(bookmarkID int64) error {
+	if _, err := r.db.GetDB().Exec(`DELETE FROM collection_bookmarks WHERE bookmark_id = ?`, bookmarkID); err != nil {
+		return fmt.Errorf("failed to remove bookmark from collection: %w", err)
+	}
+	return nil
+}
+
+// MoveBookmark moves a bookmark to index position among the bookmarks of its
+// collection, clamped to the collection's size.
+func (r *CollectionRepository) MoveBookmark(bookmarkID int64, position int) error {
+	tx, err := r.db.GetDB().Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var collectionID int64
+	err = tx.Get(&collectionID, `SELECT collection_id FROM collection_bookmarks WHERE bookmark_id = ?`, bookmarkID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("bookmark is not in a collection")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get collection entry: %w", err)
+	}
+
+	var ids []int64
+	query := `SELECT bookmark_id FROM collection_bookmarks WHERE collection_id = ? AND bookmark_id != ? ORDER BY position, bookmark_id`
+	if err := tx.Select(&ids, query, collectionID, bookmarkID); err != nil {
+		return fmt.Errorf("failed to list collection entries: %w", err)
+	}
+	position = max(0, min(position, len(ids)))
+	ids = append(ids[:position], append([]int64{bookmarkID}, ids[position:]...)...)
+
+	for i, id := range ids {
+		if _, err := tx.Exec(`UPDATE collection_bookmarks SET position = ? WHERE bookmark_id = ?`, i, id); err != nil {
+			return fmt.Errorf("failed to reorder collection: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
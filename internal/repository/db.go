@@ -18,8 +18,11 @@ func NewDatabase(dataDir string) (*Database, error) {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
+	// The background job workers write concurrently with the UI, so wait on
+	// locks instead of failing and let readers proceed under WAL.
 	dbPath := filepath.Join(dataDir, "bookmarks.db")
-	db, err := sqlx.Connect("sqlite3", dbPath)
+	dsn := dbPath + "?_busy_timeout=5000&_journal_mode=WAL&_foreign_keys=on"
+	db, err := sqlx.Connect("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -90,6 +93,179 @@ func initScheme(db *sqlx.DB) error {
 	if err != nil {
 		return err
 	}
+	_, err = db.Exec(`
+  CREATE TABLE IF NOT EXISTS jobs (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        bookmark_id INTEGER NOT NULL,
+        kind TEXT NOT NULL,
+        status TEXT NOT NULL,
+        attempts INTEGER NOT NULL DEFAULT 0,
+        last_error TEXT NOT NULL DEFAULT '',
+        run_at TIMESTAMP NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        updated_at TIMESTAMP NOT NULL,
+        FOREIGN KEY (bookmark_id) REFERENCES bookmarks(id) ON DELETE CASCADE
+      );
+  CREATE INDEX IF NOT EXISTS idx_jobs_status_run_at ON jobs(status, run_at);
+  `)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+  CREATE TABLE IF NOT EXISTS bookmark_content_versions (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        bookmark_id INTEGER NOT NULL,
+        hash TEXT NOT NULL,
+        content TEXT NOT NULL,
+        fetched_at TIMESTAMP NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        FOREIGN KEY (bookmark_id) REFERENCES bookmarks(id) ON DELETE CASCADE
+      );
+  CREATE INDEX IF NOT EXISTS idx_content_versions_bookmark ON bookmark_content_versions(bookmark_id);
+  `)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+  CREATE TABLE IF NOT EXISTS link_status (
+        bookmark_id INTEGER PRIMARY KEY,
+        status_code INTEGER NOT NULL DEFAULT 0,
+        final_url TEXT NOT NULL DEFAULT '',
+        error TEXT NOT NULL DEFAULT '',
+        moved BOOLEAN NOT NULL DEFAULT 0,
+        consecutive_failures INTEGER NOT NULL DEFAULT 0,
+        checked_at TIMESTAMP NOT NULL,
+        FOREIGN KEY (bookmark_id) REFERENCES bookmarks(id) ON DELETE CASCADE
+      );
+  `)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+  CREATE TABLE IF NOT EXISTS archives (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        bookmark_id INTEGER NOT NULL,
+        hash TEXT NOT NULL,
+        path TEXT NOT NULL,
+        content_type TEXT NOT NULL DEFAULT '',
+        size INTEGER NOT NULL DEFAULT 0,
+        assets INTEGER NOT NULL DEFAULT 0,
+        created_at TIMESTAMP NOT NULL,
+        FOREIGN KEY (bookmark_id) REFERENCES bookmarks(id) ON DELETE CASCADE
+      );
+  CREATE INDEX IF NOT EXISTS idx_archives_bookmark ON archives(bookmark_id);
+  `)
+	if err != nil {
+		return err
+	}
+
+	// bookmark_merges remembers the URLs of bookmarks merged into others, so
+	// adding or importing them again finds the surviving bookmark.
+	_, err = db.Exec(`
+  CREATE TABLE IF NOT EXISTS bookmark_merges (
+        url TEXT PRIMARY KEY,
+        url_key TEXT NOT NULL DEFAULT '',
+        bookmark_id INTEGER NOT NULL,
+        merged_at TIMESTAMP NOT NULL,
+        FOREIGN KEY (bookmark_id) REFERENCES bookmarks(id) ON DELETE CASCADE
+      );
+  CREATE INDEX IF NOT EXISTS idx_bookmark_merges_url_key ON bookmark_merges(url_key);
+  CREATE INDEX IF NOT EXISTS idx_bookmark_merges_bookmark ON bookmark_merges(bookmark_id);
+  `)
+	if err != nil {
+		return err
+	}
+
+	// collections nest through parent_id; deleting a collection deletes its
+	// subcollections and takes its bookmarks out of it.
+	_, err = db.Exec(`
+  CREATE TABLE IF NOT EXISTS collections (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        name TEXT NOT NULL,
+        parent_id INTEGER,
+        position INTEGER NOT NULL DEFAULT 0,
+        created_at TIMESTAMP NOT NULL,
+        FOREIGN KEY (parent_id) REFERENCES collections(id) ON DELETE CASCADE
+      );
+  CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_parent_name ON collections(COALESCE(parent_id, 0), name);
+
+  CREATE TABLE IF NOT EXISTS collection_bookmarks (
+        bookmark_id INTEGER PRIMARY KEY,
+        collection_id INTEGER NOT NULL,
+        position INTEGER NOT NULL DEFAULT 0,
+        FOREIGN KEY (bookmark_id) REFERENCES bookmarks(id) ON DELETE CASCADE,
+        FOREIGN KEY (collection_id) REFERENCES collections(id) ON DELETE CASCADE
+      );
+  CREATE INDEX IF NOT EXISTS idx_collection_bookmarks_collection ON collection_bookmarks(collection_id, position);
+  `)
+	if err != nil {
+		return err
+	}
+
+	// tag_aliases maps alternative spellings of a tag to the tag itself.
+	_, err = db.Exec(`
+  CREATE TABLE IF NOT EXISTS tag_aliases (
+        alias TEXT PRIMARY KEY,
+        tag TEXT NOT NULL
+      );
+  `)
+	if err != nil {
+		return err
+	}
+
+	// annotations are timestamped remarks on a bookmark, alongside the
+	// free-form notes kept in bookmarks.notes.
+	_, err = db.Exec(`
+  CREATE TABLE IF NOT EXISTS annotations (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        bookmark_id INTEGER NOT NULL,
+        text TEXT NOT NULL,
+        created_at TIMESTAMP NOT NULL,
+        updated_at TIMESTAMP NOT NULL,
+        FOREIGN KEY (bookmark_id) REFERENCES bookmarks(id) ON DELETE CASCADE
+      );
+  CREATE INDEX IF NOT EXISTS idx_annotations_bookmark ON annotations(bookmark_id, created_at);
+  `)
+	if err != nil {
+		return err
+	}
+
+	// highlights are passages quoted from a bookmark's content.
+	_, err = db.Exec(`
+  CREATE TABLE IF NOT EXISTS highlights (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        bookmark_id INTEGER NOT NULL,
+        text TEXT NOT NULL,
+        text_offset INTEGER NOT NULL DEFAULT 0,
+        comment TEXT NOT NULL DEFAULT '',
+        created_at TIMESTAMP NOT NULL,
+        FOREIGN KEY (bookmark_id) REFERENCES bookmarks(id) ON DELETE CASCADE
+      );
+  CREATE INDEX IF NOT EXISTS idx_highlights_bookmark ON highlights(bookmark_id, text_offset);
+  `)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range addedColumns {
+		if err := addColumn(db, c.table, c.name, c.definition); err != nil {
+			return err
+		}
+	}
+
+	// Indexes on added columns can only be created once the columns exist.
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_bookmarks_url_key ON bookmarks(url_key);`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_bookmarks_read_state ON bookmarks(read_state, priority);`)
+	if err != nil {
+		return err
+	}
+
 	log.Info().Msg("Database schema initialized successfully.")
 	return nil
 }
@@ -97,3 +273,54 @@ func initScheme(db *sqlx.DB) error {
 
 
 
+
+// addedColumns lists columns added to tables after their initial schema.
+// They are applied on startup so existing databases pick them up.
+var addedColumns = []struct {
+	table      string
+	name       string
+	definition string
+}{
+	{"bookmarks", "fetch_state", "TEXT NOT NULL DEFAULT 'done'"},
+	{"bookmarks", "etag", "TEXT NOT NULL DEFAULT ''"},
+	{"bookmarks", "last_modified", "TEXT NOT NULL DEFAULT ''"},
+	{"bookmarks", "fetched_at", "TIMESTAMP"},
+	{"bookmarks", "author", "TEXT NOT NULL DEFAULT ''"},
+	{"bookmarks", "content_type", "TEXT NOT NULL DEFAULT ''"},
+	{"bookmarks", "page_count", "INTEGER NOT NULL DEFAULT 0"},
+	{"bookmarks", "word_count", "INTEGER NOT NULL DEFAULT 0"},
+	{"bookmarks", "reading_time", "INTEGER NOT NULL DEFAULT 0"},
+	{"bookmarks", "lang", "TEXT NOT NULL DEFAULT ''"},
+	{"bookmarks", "published_at", "TIMESTAMP"},
+	{"bookmarks", "favicon", "TEXT NOT NULL DEFAULT ''"},
+	{"bookmarks", "url_key", "TEXT NOT NULL DEFAULT ''"},
+	{"bookmarks", "notes", "TEXT NOT NULL DEFAULT ''"},
+	{"bookmarks", "read_state", "TEXT NOT NULL DEFAULT 'unread'"},
+	{"bookmarks", "priority", "INTEGER NOT NULL DEFAULT 0"},
+	{"bookmarks", "read_at", "TIMESTAMP"},
+	{"bookmarks", "read_progress", "INTEGER NOT NULL DEFAULT 0"},
+	{"bookmarks", "starred", "INTEGER NOT NULL DEFAULT 0"},
+	{"bookmarks", "pinned", "INTEGER NOT NULL DEFAULT 0"},
+	{"bookmarks", "rating", "INTEGER NOT NULL DEFAULT 0"},
+	{"tags", "parent_id", "INTEGER REFERENCES tags(id) ON DELETE SET NULL"},
+	{"archives", "target_url", "TEXT NOT NULL DEFAULT ''"},
+	{"archives", "request_path", "TEXT NOT NULL DEFAULT ''"},
+	{"archives", "response_path", "TEXT NOT NULL DEFAULT ''"},
+}
+
+func addColumn(db *sqlx.DB, table, name, definition string) error {
+	var count int
+	err := db.Get(&count, `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, name)
+	if err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, name, definition))
+	if err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, name, err)
+	}
+	return nil
+}
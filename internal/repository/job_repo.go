@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/san-kum/bookmarker/internal/model"
+)
+
+type JobRepository struct {
+	db *Database
+}
+
+func NewJobRepository(db *Database) *JobRepository {
+	return &JobRepository{
+		db: db,
+	}
+}
+
+// Enqueue stores a new job unless an identical one is already waiting or
+// running, in which case the existing job is returned.
+func (r *JobRepository) Enqueue(job *model.Job) error {
+	tx, err := r.db.GetDB().Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+    SELECT * FROM jobs
+    WHERE bookmark_id = ? AND kind = ? AND status IN (?, ?)
+    LIMIT 1
+    `
+	var existing model.Job
+	err = tx.Get(&existing, query, job.BookmarkID, job.Kind, model.JobStatusQueued, model.JobStatusRunning)
+	if err == nil {
+		*job = existing
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check existing jobs: %w", err)
+	}
+
+	query = `
+    INSERT INTO jobs (bookmark_id, kind, status, attempts, last_error, run_at, created_at, updated_at)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    `
+	res, err := tx.Exec(query, job.BookmarkID, job.Kind, job.Status, job.Attempts, job.LastError, job.RunAt, job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert job: %w", err)
+	}
+
+	job.ID, err = res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Claim atomically marks the next due job as running and returns it. It
+// returns nil when no job is due.
+func (r *JobRepository) Claim(now time.Time) (*model.Job, error) {
+	var job model.Job
+
+	query := `
+    UPDATE jobs SET status = ?, updated_at = ?
+    WHERE id = (
+      SELECT id FROM jobs
+      WHERE status = ? AND run_at <= ?
+      ORDER BY run_at, id
+      LIMIT 1
+    )
+    RETURNING *
+    `
+	err := r.db.GetDB().Get(&job, query, model.JobStatusRunning, now, model.JobStatusQueued, now)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return &job, nil
+}
+
+func (r *JobRepository) Complete(job *model.Job) error {
+	job.Status = model.JobStatusDone
+	job.LastError = ""
+	return r.save(job)
+}
+
+// Retry puts the job back on the queue to run again at runAt.
+func (r *JobRepository) Retry(job *model.Job, runAt time.Time, reason string) error {
+	job.Status = model.JobStatusQueued
+	job.RunAt = runAt
+	job.LastError = reason
+	return r.save(job)
+}
+
+func (r *JobRepository) Fail(job *model.Job, reason string) error {
+	job.Status = model.JobStatusFailed
+	job.LastError = reason
+	return r.save(job)
+}
+
+// ResetRunning requeues jobs left running by a process that exited before
+// finishing them.
+func (r *JobRepository) ResetRunning() error {
+	_, err := r.db.GetDB().Exec(`UPDATE jobs SET status = ? WHERE status = ?`, model.JobStatusQueued, model.JobStatusRunning)
+	if err != nil {
+		return fmt.Errorf("failed to reset running jobs: %w", err)
+	}
+	return nil
+}
+
+func (r *JobRepository) save(job *model.Job) error {
+	job.UpdatedAt = time.Now().UTC()
+	query := `
+    UPDATE jobs
+    SET status = ?, attempts = ?, last_error = ?, run_at = ?, updated_at = ?
+    WHERE id = ?
+    `
+	_, err := r.db.GetDB().Exec(query, job.Status, job.Attempts, job.LastError, job.RunAt, job.UpdatedAt, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/san-kum/bookmarker/internal/model"
+)
+
+type LinkRepository struct {
+	db *Database
+}
+
+func NewLinkRepository(db *Database) *LinkRepository {
+	return &LinkRepository{
+		db: db,
+	}
+}
+
+func (r *LinkRepository) Get(bookmarkID int64) (*model.LinkStatus, error) {
+	var status model.LinkStatus
+	err := r.db.GetDB().Get(&status, `SELECT * FROM link_status WHERE bookmark_id = ?`, bookmarkID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get link status: %w", err)
+	}
+	return &status, nil
+}
+
+func (r *LinkRepository) Save(status *model.LinkStatus) error {
+	query := `
+    INSERT INTO link_status (bookmark_id, status_code, final_url, error, moved, consecutive_failures, checked_at)
+    VALUES (?, ?, ?, ?, ?, ?, ?)
+    ON CONFLICT(bookmark_id) DO UPDATE SET
+      status_code = excluded.status_code,
+      final_url = excluded.final_url,
+      error = excluded.error,
+      moved = excluded.moved,
+      consecutive_failures = excluded.consecutive_failures,
+      checked_at = excluded.checked_at
+    `
+	_, err := r.db.GetDB().Exec(query, status.BookmarkID, status.StatusCode, status.FinalURL, status.Error, status.Moved, status.ConsecutiveFailures, status.CheckedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save link status: %w", err)
+	}
+	return nil
+}
+
+// ListDue returns bookmarks that have never been checked or whose last check
+// was before the given time.
+func (r *LinkRepository) ListDue(before time.Time) ([]*model.Bookmark, error) {
+	var bookmarks []*model.Bookmark
+	query := `
+    SELECT b.*
+    FROM bookmarks b
+    LEFT JOIN link_status ls ON ls.bookmark_id = b.id
+    WHERE ls.checked_at IS NULL OR ls.checked_at < ?
+    ORDER BY ls.checked_at
+    `
+	if err := r.db.GetDB().Select(&bookmarks, query, before); err != nil {
+		return nil, fmt.Errorf("failed to list bookmarks due for link check: %w", err)
+	}
+	return bookmarks, nil
+}
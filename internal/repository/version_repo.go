@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/san-kum/bookmarker/internal/model"
+)
+
+type VersionRepository struct {
+	db *Database
+}
+
+func NewVersionRepository(db *Database) *VersionRepository {
+	return &VersionRepository{
+		db: db,
+	}
+}
+
+func (r *VersionRepository) Create(version *model.ContentVersion) error {
+	query := `
+    INSERT INTO bookmark_content_versions (bookmark_id, hash, content, fetched_at, created_at)
+    VALUES (?, ?, ?, ?, ?)
+    `
+	res, err := r.db.GetDB().Exec(query, version.BookmarkID, version.Hash, version.Content, version.FetchedAt, version.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert content version: %w", err)
+	}
+
+	version.ID, err = res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+	return nil
+}
+
+func (r *VersionRepository) GetByID(id int64) (*model.ContentVersion, error) {
+	var version model.ContentVersion
+	err := r.db.GetDB().Get(&version, `SELECT * FROM bookmark_content_versions WHERE id = ?`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get content version: %w", err)
+	}
+	return &version, nil
+}
+
+// ListByBookmark returns a bookmark's stored versions, newest first.
+func (r *VersionRepository) ListByBookmark(bookmarkID int64) ([]*model.ContentVersion, error) {
+	var versions []*model.ContentVersion
+	query := `
+    SELECT * FROM bookmark_content_versions
+    WHERE bookmark_id = ?
+    ORDER BY fetched_at DESC, id DESC
+    `
+	if err := r.db.GetDB().Select(&versions, query, bookmarkID); err != nil {
+		return nil, fmt.Errorf("failed to list content versions: %w", err)
+	}
+	return versions, nil
+}
@@ -0,0 +1,390 @@
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/san-kum/bookmarker/internal/repository"
+	"github.com/san-kum/bookmarker/internal/service/extractor"
+	"golang.org/x/net/html"
+)
+
+const maxAssets = 200
+
+var cssURLPattern = regexp.MustCompile(`url\(\s*(['"]?)([^'")]+)(['"]?)\s*\)`)
+
+// Archiver saves offline snapshots of bookmarked pages. Stylesheets are
+// inlined into the snapshot and images, fonts and other assets are stored as
+// separate blobs referenced by relative path, so identical assets are stored
+// only once. Render turns a snapshot into a single self-contained file with
+// its assets inlined as data URIs, for viewing or copying elsewhere.
+// Snapshots rendered for opening in a browser are kept in renderDir.
+type Archiver struct {
+	fetcher   *extractor.Fetcher
+	store     *Store
+	renderDir string
+	archives  *repository.ArchiveRepository
+	bookmarks *repository.BookmarkRepository
+}
+
+func NewArchiver(fetcher *extractor.Fetcher, store *Store, renderDir string, archives *repository.ArchiveRepository, bookmarks *repository.BookmarkRepository) *Archiver {
+	return &Archiver{
+		fetcher:   fetcher,
+		store:     store,
+		renderDir: renderDir,
+		archives:  archives,
+		bookmarks: bookmarks,
+	}
+}
+
+// Latest returns the most recent snapshot of a bookmark, or nil.
+func (a *Archiver) Latest(bookmarkID int64) (*model.Archive, error) {
+	return a.archives.Latest(bookmarkID)
+}
+
+// Path returns the absolute path of a snapshot file.
+func (a *Archiver) Path(archive *model.Archive) string {
+	return a.store.Abs(archive.Path)
+}
+
+func (a *Archiver) ListUnarchived() ([]*model.Bookmark, error) {
+	return a.archives.ListUnarchived()
+}
+
+// HandleJob is the queue handler for archive jobs.
+func (a *Archiver) HandleJob(job *model.Job) error {
+	bookmark, err := a.bookmarks.GetByID(job.BookmarkID)
+	if err != nil {
+		return err
+	}
+	if bookmark == nil {
+		return nil
+	}
+	_, err = a.Archive(bookmark)
+	return err
+}
+
+// Archive fetches the bookmark's page with its assets and records a new
+// snapshot.
+func (a *Archiver) Archive(bookmark *model.Bookmark) (*model.Archive, error) {
+	resp, err := a.fetcher.Get(bookmark.URL, extractor.Validators{})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch URL, status: %d", resp.StatusCode)
+	}
+
+	contentType := mediaType(resp.Header.Get("Content-Type"))
+	body := resp.Body
+	assets := 0
+
+	if contentType == "text/html" || contentType == "application/xhtml+xml" {
+		base, err := url.Parse(resp.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		s := &snapshot{archiver: a, assets: make(map[string]string)}
+		body, err = s.render(base, resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		assets = len(s.assets)
+	}
+
+	hash, path, err := a.store.Put(body, extension(contentType, resp.URL))
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the untouched exchange as well so it can be exported to WARC.
+	_, requestPath, err := a.store.Put(resp.WireRequest(), ".http")
+	if err != nil {
+		return nil, err
+	}
+	_, responsePath, err := a.store.Put(resp.WireResponse(), ".http")
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &model.Archive{
+		BookmarkID:   bookmark.ID,
+		Hash:         hash,
+		Path:         path,
+		ContentType:  contentType,
+		Size:         int64(len(body)),
+		Assets:       assets,
+		TargetURL:    resp.URL,
+		RequestPath:  requestPath,
+		ResponsePath: responsePath,
+		CreatedAt:    resp.FetchedAt,
+	}
+	if err := a.archives.Create(archive); err != nil {
+		return nil, err
+	}
+
+	log.Info().Int64("id", bookmark.ID).Str("path", path).Int("assets", assets).Msg("Archived bookmark")
+	return archive, nil
+}
+
+// snapshot rewrites a single page, fetching each referenced asset once.
+type snapshot struct {
+	archiver *Archiver
+	assets   map[string]string
+}
+
+func (s *snapshot) render(base *url.URL, body []byte) ([]byte, error) {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	s.rewrite(doc, base)
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return nil, fmt.Errorf("failed to render snapshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *snapshot) rewrite(n *html.Node, base *url.URL) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+
+		if c.Type == html.ElementNode {
+			switch c.Data {
+			case "script", "base":
+				n.RemoveChild(c)
+				c = next
+				continue
+			case "link":
+				if replacement := s.rewriteLink(c, base); replacement != nil {
+					n.InsertBefore(replacement, c)
+					n.RemoveChild(c)
+					c = next
+					continue
+				}
+			case "style":
+				if c.FirstChild != nil && c.FirstChild.Type == html.TextNode {
+					c.FirstChild.Data = s.rewriteCSS(c.FirstChild.Data, base, 0)
+				}
+			}
+			s.rewriteAttrs(c, base)
+		}
+
+		s.rewrite(c, base)
+		c = next
+	}
+}
+
+func (s *snapshot) rewriteAttrs(n *html.Node, base *url.URL) {
+	attrs := n.Attr[:0]
+	for _, a := range n.Attr {
+		key := strings.ToLower(a.Key)
+		switch {
+		case strings.HasPrefix(key, "on"), key == "srcset", key == "integrity":
+			// Scripts are stripped and responsive sources would point back
+			// at the live site.
+			continue
+		case key == "style":
+			a.Val = s.rewriteCSS(a.Val, base, 0)
+		case key == "href" && (n.Data == "a" || n.Data == "area"):
+			a.Val = resolve(base, a.Val)
+		case key == "src" && (n.Data == "img" || n.Data == "source" || n.Data == "input"):
+			a.Val = s.asset(base, a.Val)
+		case key == "poster" && n.Data == "video":
+			a.Val = s.asset(base, a.Val)
+		}
+		attrs = append(attrs, a)
+	}
+	n.Attr = attrs
+}
+
+// rewriteLink inlines a stylesheet link as a <style> element and stores icons
+// as assets. It returns the replacement node, if any.
+func (s *snapshot) rewriteLink(n *html.Node, base *url.URL) *html.Node {
+	rel := strings.ToLower(attr(n, "rel"))
+	href := attr(n, "href")
+	if href == "" {
+		return nil
+	}
+
+	switch {
+	case strings.Contains(rel, "stylesheet"):
+		cssURL, err := base.Parse(href)
+		if err != nil {
+			return nil
+		}
+		css, err := s.fetchText(cssURL.String())
+		if err != nil {
+			log.Debug().Err(err).Str("url", cssURL.String()).Msg("Failed to archive stylesheet")
+			return nil
+		}
+
+		style := &html.Node{Type: html.ElementNode, Data: "style"}
+		if media := attr(n, "media"); media != "" {
+			style.Attr = []html.Attribute{{Key: "media", Val: media}}
+		}
+		style.AppendChild(&html.Node{Type: html.TextNode, Data: s.rewriteCSS(css, cssURL, 0)})
+		return style
+	case strings.Contains(rel, "icon"):
+		setAttr(n, "href", s.asset(base, href))
+	}
+	return nil
+}
+
+// rewriteCSS points url() references at stored assets. Imported stylesheets
+// are rewritten recursively and stored as blobs themselves.
+func (s *snapshot) rewriteCSS(css string, base *url.URL, depth int) string {
+	return cssURLPattern.ReplaceAllStringFunc(css, func(match string) string {
+		ref := cssURLPattern.FindStringSubmatch(match)[2]
+		if strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "#") {
+			return match
+		}
+
+		if strings.HasSuffix(strings.ToLower(strings.SplitN(ref, "?", 2)[0]), ".css") && depth < 3 {
+			return fmt.Sprintf("url(%q)", s.stylesheet(base, ref, depth+1))
+		}
+		return fmt.Sprintf("url(%q)", s.asset(base, ref))
+	})
+}
+
+func (s *snapshot) stylesheet(base *url.URL, ref string, depth int) string {
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	if p, ok := s.assets[u.String()]; ok {
+		return p
+	}
+
+	css, err := s.fetchText(u.String())
+	if err != nil {
+		return u.String()
+	}
+
+	_, path, err := s.archiver.store.Put([]byte(s.rewriteCSS(css, u, depth)), ".css")
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to store stylesheet")
+		return u.String()
+	}
+	s.assets[u.String()] = siblingPath(path)
+	return s.assets[u.String()]
+}
+
+// asset stores the resource at ref and returns its path relative to the
+// snapshot. References that cannot be fetched are left pointing at the live
+// URL.
+func (s *snapshot) asset(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "data:") {
+		return ref
+	}
+
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ref
+	}
+	if p, ok := s.assets[u.String()]; ok {
+		return p
+	}
+	if len(s.assets) >= maxAssets {
+		return u.String()
+	}
+
+	resp, err := s.archiver.fetcher.Get(u.String(), extractor.Validators{})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		s.assets[u.String()] = u.String()
+		return u.String()
+	}
+
+	_, path, err := s.archiver.store.Put(resp.Body, extension(mediaType(resp.Header.Get("Content-Type")), u.String()))
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to store asset")
+		return u.String()
+	}
+	s.assets[u.String()] = siblingPath(path)
+	return s.assets[u.String()]
+}
+
+func (s *snapshot) fetchText(u string) (string, error) {
+	resp, err := s.archiver.fetcher.Get(u, extractor.Validators{})
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return string(resp.Body), nil
+}
+
+func resolve(base *url.URL, ref string) string {
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(n *html.Node, key, val string) {
+	for i, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+}
+
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	}
+	return mt
+}
+
+// extension picks a file extension so snapshots and assets open with the
+// right application when viewed from disk.
+func extension(mediaType, rawURL string) string {
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		return ".html"
+	case "text/css":
+		return ".css"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/svg+xml":
+		return ".svg"
+	case "application/pdf":
+		return ".pdf"
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		if ext := path.Ext(u.Path); len(ext) > 1 && len(ext) <= 6 {
+			return strings.ToLower(ext)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package archive
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/san-kum/bookmarker/internal/model"
+)
+
+// blobRefPattern matches the references to stored assets that snapshots and
+// their stylesheets are written with, as returned by siblingPath.
+var blobRefPattern = regexp.MustCompile(`\.\./[0-9a-f]{2}/[0-9a-f]{64}(\.[0-9A-Za-z]+)?`)
+
+// Render returns a snapshot as a single self-contained file: every stored
+// asset it references, including those its stylesheets reference, is inlined
+// as a data URI. Snapshots of anything but HTML pages are returned as stored.
+func (a *Archiver) Render(archive *model.Archive) ([]byte, error) {
+	data, err := a.store.Get(archive.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if archive.ContentType != "text/html" && archive.ContentType != "application/xhtml+xml" {
+		return data, nil
+	}
+
+	r := &renderer{store: a.store, inlined: make(map[string]string)}
+	return r.inline(data, 0), nil
+}
+
+// RenderFile writes a rendered snapshot to the render directory, for opening
+// in a browser, and returns its path. The file is named after the snapshot's
+// content hash, so opening a snapshot again reuses it.
+func (a *Archiver) RenderFile(archive *model.Archive) (string, error) {
+	name := archive.Hash
+	if name == "" {
+		name = fmt.Sprintf("snapshot-%d", archive.ID)
+	}
+	path := filepath.Join(a.renderDir, name+extension(archive.ContentType, archive.TargetURL))
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	data, err := a.Render(archive)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(a.renderDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create render directory: %w", err)
+	}
+
+	// Written under a temporary name first, so a partly written file is
+	// never reused.
+	f, err := os.CreateTemp(a.renderDir, name+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	return path, nil
+}
+
+// renderer inlines the assets of one snapshot, encoding each only once.
+type renderer struct {
+	store   *Store
+	inlined map[string]string
+}
+
+func (r *renderer) inline(data []byte, depth int) []byte {
+	return blobRefPattern.ReplaceAllFunc(data, func(ref []byte) []byte {
+		return []byte(r.dataURI(string(ref), depth))
+	})
+}
+
+// dataURI returns the stored asset at ref as a data URI. Stylesheets have
+// their own assets inlined first. Assets missing from the store are left as
+// they are.
+func (r *renderer) dataURI(ref string, depth int) string {
+	if uri, ok := r.inlined[ref]; ok {
+		return uri
+	}
+
+	data, err := r.store.Get(filepath.Join("objects", filepath.FromSlash(strings.TrimPrefix(ref, "../"))))
+	if err != nil {
+		log.Warn().Err(err).Str("asset", ref).Msg("Failed to inline snapshot asset")
+		return ref
+	}
+
+	ext := filepath.Ext(ref)
+	if ext == ".css" && depth < 3 {
+		data = r.inline(data, depth+1)
+	}
+	contentType := mediaType(mime.TypeByExtension(ext))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	uri := "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data)
+	r.inlined[ref] = uri
+	return uri
+}
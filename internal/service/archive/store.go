@@ -0,0 +1,77 @@
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Store is a content-addressed blob store. Every blob lives at
+// objects/<first two hex digits>/<sha256><ext>, so identical assets shared by
+// many snapshots are stored once and blobs can reference each other with
+// relative paths of the form ../xx/<sha256><ext>.
+type Store struct {
+	root string
+}
+
+func NewStore(root string) *Store {
+	return &Store{root: root}
+}
+
+func (s *Store) Root() string {
+	return s.root
+}
+
+// Put stores data unless a blob with the same content already exists. It
+// returns the hash and the blob path relative to the store root.
+func (s *Store) Put(data []byte, ext string) (hash, path string, err error) {
+	sum := sha256.Sum256(data)
+	hash = hex.EncodeToString(sum[:])
+	path = filepath.Join("objects", hash[:2], hash+ext)
+
+	full := filepath.Join(s.root, path)
+	if _, err := os.Stat(full); err == nil {
+		return hash, path, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated
+	// blob under its final, content-derived name.
+	tmp, err := os.CreateTemp(filepath.Dir(full), ".tmp-*")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", "", fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", "", fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), full); err != nil {
+		return "", "", fmt.Errorf("failed to store archive file: %w", err)
+	}
+	return hash, path, nil
+}
+
+// Get reads a blob by its path relative to the store root.
+func (s *Store) Get(path string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.root, path))
+}
+
+// Abs returns the absolute filesystem path of a blob.
+func (s *Store) Abs(path string) string {
+	return filepath.Join(s.root, path)
+}
+
+// siblingPath is the path of blob relative to any other blob in the store.
+func siblingPath(path string) string {
+	return "../" + filepath.ToSlash(filepath.Join(filepath.Base(filepath.Dir(path)), filepath.Base(path)))
+}
@@ -0,0 +1,79 @@
+package archive
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/san-kum/bookmarker/internal/service/export"
+	"github.com/san-kum/bookmarker/internal/service/extractor"
+)
+
+// WARCStats summarizes a WARC export.
+type WARCStats struct {
+	Written int
+	Fetched int
+	Skipped int
+	// Notes counts the bookmarks whose notes were written.
+	Notes int
+}
+
+// ExportWARC writes the request and response records of each bookmark's
+// latest snapshot. Bookmarks that have no stored exchange are fetched live
+// when fetchMissing is set and skipped otherwise. Notes and annotations are
+// written as a Markdown metadata record, whether or not the page is.
+func (a *Archiver) ExportWARC(w *export.WARCWriter, bookmarks []*model.Bookmark, fetchMissing bool) (*WARCStats, error) {
+	stats := &WARCStats{}
+
+	for _, bookmark := range bookmarks {
+		if notes := bookmark.NotesMarkdown(); notes != "" {
+			if err := w.WriteMetadata(bookmark.URL, bookmark.UpdatedAt, "text/markdown; charset=utf-8", []byte(notes+"\n")); err != nil {
+				return stats, fmt.Errorf("failed to write WARC notes record: %w", err)
+			}
+			stats.Notes++
+		}
+
+		snapshot, err := a.archives.Latest(bookmark.ID)
+		if err != nil {
+			return stats, err
+		}
+
+		if snapshot != nil && snapshot.HasExchange() {
+			request, err := a.store.Get(snapshot.RequestPath)
+			if err != nil {
+				return stats, fmt.Errorf("failed to read archived request: %w", err)
+			}
+			response, err := a.store.Get(snapshot.ResponsePath)
+			if err != nil {
+				return stats, fmt.Errorf("failed to read archived response: %w", err)
+			}
+
+			if err := w.WriteExchange(snapshot.TargetURL, snapshot.CreatedAt, request, response); err != nil {
+				return stats, fmt.Errorf("failed to write WARC records: %w", err)
+			}
+			stats.Written++
+			continue
+		}
+
+		if !fetchMissing {
+			stats.Skipped++
+			continue
+		}
+
+		resp, err := a.fetcher.Get(bookmark.URL, extractor.Validators{})
+		if err != nil || resp.StatusCode != http.StatusOK {
+			log.Warn().Err(err).Str("url", bookmark.URL).Msg("Failed to fetch bookmark for WARC export")
+			stats.Skipped++
+			continue
+		}
+
+		if err := w.WriteExchange(resp.URL, resp.FetchedAt, resp.WireRequest(), resp.WireResponse()); err != nil {
+			return stats, fmt.Errorf("failed to write WARC records: %w", err)
+		}
+		stats.Written++
+		stats.Fetched++
+	}
+
+	return stats, nil
+}
@@ -3,23 +3,59 @@ package service
 import (
 	"fmt"
 	"net/url"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/rs/zerolog/log"
 	"github.com/san-kum/bookmarker/internal/model"
 	"github.com/san-kum/bookmarker/internal/repository"
+	"github.com/san-kum/bookmarker/internal/service/canonical"
+	"github.com/san-kum/bookmarker/internal/service/diff"
 	"github.com/san-kum/bookmarker/internal/service/extractor"
+	"github.com/san-kum/bookmarker/internal/service/favicon"
+	"github.com/san-kum/bookmarker/internal/service/queue"
+	"github.com/san-kum/bookmarker/internal/service/rules"
+	"github.com/san-kum/bookmarker/internal/service/search"
+	"github.com/san-kum/bookmarker/internal/service/summary"
+	"github.com/san-kum/bookmarker/internal/service/tagsuggest"
+	"github.com/san-kum/bookmarker/internal/service/textstats"
 )
 
 type BookmarkService struct {
-	repo      *repository.BookmarkRepository
-	extractor *extractor.HTMLExtractor
+	repo       *repository.BookmarkRepository
+	versions   *repository.VersionRepository
+	extractor  *extractor.HTMLExtractor
+	summarizer summary.Summarizer
+	suggester  *tagsuggest.Suggester
+	search     *search.SearchService
+	queue      *queue.Queue
+
+	archiveOnAdd bool
+	rules        *rules.Engine
+	favicons     *favicon.Cache
+
+	canonicalizer *canonical.Canonicalizer
 }
 
-func NewBookmarkService(repo *repository.BookmarkRepository, extractor *extractor.HTMLExtractor) *BookmarkService {
-	return &BookmarkService{
-		repo:      repo,
-		extractor: extractor,
+func NewBookmarkService(repo *repository.BookmarkRepository, versions *repository.VersionRepository, extractor *extractor.HTMLExtractor, summarizer summary.Summarizer, search *search.SearchService, queue *queue.Queue) *BookmarkService {
+	s := &BookmarkService{
+		repo:       repo,
+		versions:   versions,
+		extractor:  extractor,
+		summarizer: summarizer,
+		suggester:  tagsuggest.NewSuggester(repo),
+		search:     search,
+		queue:      queue,
+
+		canonicalizer: canonical.NewCanonicalizer(canonical.DefaultTrackingParams),
 	}
+
+	queue.Handle(model.JobKindEnrich, s.enrichJob)
+	queue.Handle(model.JobKindRefresh, s.refreshJob)
+	queue.OnDone(s.jobDone)
+
+	return s
 }
 
 func (s *BookmarkService) Add(urlStr string, tags []string) (*model.Bookmark, error) {
@@ -28,91 +64,1223 @@ func (s *BookmarkService) Add(urlStr string, tags []string) (*model.Bookmark, er
 		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
-	existing, err := s.repo.GetByURL(urlStr)
+	urlStr, err = s.canonicalizer.Clean(urlStr)
 	if err != nil {
 		return nil, err
 	}
-	if existing != nil {
-		return existing, nil
+	key, err := s.canonicalizer.Key(urlStr)
+	if err != nil {
+		return nil, err
 	}
 
-	title, description, content, err := s.extractor.ExtractContent(urlStr)
+	existing, err := s.repo.GetByURL(urlStr)
 	if err != nil {
-		log.Warn().Err(err).Str("url", urlStr).Msg("Content extraction failed, creating bookmark with minimal info")
-		bookmark := model.NewBookmark(urlStr, urlStr)
-		err = s.repo.Create(bookmark)
+		return nil, err
+	}
+	if existing == nil {
+		existing, err = s.repo.GetByURLKey(key)
 		if err != nil {
 			return nil, err
 		}
-		return bookmark, nil
+	}
+	if existing == nil {
+		existing, err = s.repo.GetMergedInto(urlStr, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if existing != nil {
+		return existing, nil
 	}
 
-	summary := s.extractor.GenerateSummary(content)
-
-	bookmark := model.NewBookmark(urlStr, title)
-	bookmark.Description = description
-	bookmark.Content = content
-	bookmark.Summary = summary
-
+	// The page is fetched by the background queue; save what we know now so
+	// the caller is not blocked on the network.
+	bookmark := model.NewBookmark(urlStr, urlStr)
+	bookmark.URLKey = key
+	tags, err = s.repo.ResolveTags(tags...)
+	if err != nil {
+		return nil, err
+	}
 	for _, tagName := range tags {
 		if tagName != "" {
 			bookmark.AddTag(model.NewTag(tagName))
 		}
 	}
+	ruleTags, err := s.repo.ResolveTags(s.rules.Tags(bookmark)...)
+	if err != nil {
+		return nil, err
+	}
+	for _, tagName := range ruleTags {
+		bookmark.AddTag(model.NewTag(tagName))
+	}
 
 	err = s.repo.Create(bookmark)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.search.IndexBookmark(bookmark); err != nil {
+		log.Warn().Err(err).Int64("id", bookmark.ID).Msg("Failed to index bookmark")
+	}
+
+	if err := s.queue.Enqueue(model.JobKindEnrich, bookmark.ID); err != nil {
+		return bookmark, fmt.Errorf("bookmark saved but fetch could not be scheduled: %w", err)
+	}
+
+	if s.archiveOnAdd {
+		if err := s.QueueArchive(bookmark.ID); err != nil {
+			return bookmark, fmt.Errorf("bookmark saved but archiving could not be scheduled: %w", err)
+		}
+	}
+
 	return bookmark, nil
 }
 
-func (s *BookmarkService) Get(id int64) (*model.Bookmark, error) {
-	return s.repo.GetByID(id)
+// SetArchiveOnAdd controls whether Add also schedules an offline snapshot of
+// each new bookmark.
+func (s *BookmarkService) SetArchiveOnAdd(enabled bool) {
+	s.archiveOnAdd = enabled
 }
 
-func (s *BookmarkService) List(tag string, limit, offset int) ([]*model.Bookmark, error) {
-	if limit <= 0 {
-		limit = 20
+// SetRules sets the tagging rules applied to new bookmarks once their URL is
+// known and again once their page has been fetched.
+func (s *BookmarkService) SetRules(engine *rules.Engine) {
+	s.rules = engine
+}
+
+// SetCanonicalizer sets how URLs are cleaned and compared to detect
+// duplicates.
+func (s *BookmarkService) SetCanonicalizer(canonicalizer *canonical.Canonicalizer) {
+	s.canonicalizer = canonicalizer
+}
+
+// urlKey returns the duplicate detection key of a bookmark. A canonical URL
+// declared by the page is preferred, unless it points to another site or,
+// as happens on badly configured sites, to the home page.
+func (s *BookmarkService) urlKey(bookmark *model.Bookmark, canonicalURL string) string {
+	key, err := s.canonicalizer.Key(bookmark.URL)
+	if err != nil {
+		return bookmark.URLKey
+	}
+	if canonicalURL == "" {
+		return key
 	}
-	return s.repo.List(tag, limit, offset)
+
+	canonicalKey, err := s.canonicalizer.Key(canonicalURL)
+	if err != nil {
+		return key
+	}
+	host, _, _ := strings.Cut(key, "/")
+	canonicalHost, canonicalPath, _ := strings.Cut(canonicalKey, "/")
+	if canonicalHost != host || (canonicalPath == "" && strings.Contains(key, "/")) {
+		return key
+	}
+	return canonicalKey
 }
 
-func (s *BookmarkService) Update(bookmark *model.Bookmark) error {
-	return s.repo.Update(bookmark)
+// BackfillURLKeys computes the duplicate detection key of bookmarks saved
+// before keys were stored.
+func (s *BookmarkService) BackfillURLKeys() error {
+	bookmarks, err := s.repo.ListMissingURLKey()
+	if err != nil {
+		return err
+	}
+
+	for _, bookmark := range bookmarks {
+		key := s.urlKey(bookmark, "")
+		if key == "" {
+			continue
+		}
+		if err := s.repo.UpdateFields(bookmark.ID, map[string]interface{}{"url_key": key}); err != nil {
+			return err
+		}
+	}
+
+	if len(bookmarks) > 0 {
+		log.Info().Int("count", len(bookmarks)).Msg("Computed URL keys for existing bookmarks")
+	}
+	return nil
 }
 
-func (s *BookmarkService) Delete(id int64) error {
-	return s.repo.Delete(id)
+// NormalizeTags brings tags saved before tag names were normalized in line,
+// merging variants of the same tag.
+func (s *BookmarkService) NormalizeTags() error {
+	ids, err := s.repo.NormalizeTags()
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		log.Info().Int("count", len(ids)).Msg("Normalized the tags of existing bookmarks")
+	}
+	return s.reindex(ids)
 }
 
-func (s *BookmarkService) AddTag(bookmarkID int64, tagName string) error {
-	bookmark, err := s.repo.GetByID(bookmarkID)
+// LinkTagParents links hierarchical tags created before tags had parents to
+// their parent tags.
+func (s *BookmarkService) LinkTagParents() error {
+	count, err := s.repo.LinkTagParents()
 	if err != nil {
 		return err
 	}
+	if count > 0 {
+		log.Info().Int("count", count).Msg("Linked hierarchical tags to their parents")
+	}
+	return nil
+}
+
+// DuplicateGroup is a set of bookmarks of the same page. Keep is the oldest,
+// which the others are merged into.
+type DuplicateGroup struct {
+	Keep       *model.Bookmark
+	Duplicates []*model.Bookmark
+}
+
+// Dedupe finds bookmarks with the same URL key and merges each group into
+// its oldest bookmark. With dryRun set nothing is changed and the groups
+// show what would be merged.
+func (s *BookmarkService) Dedupe(dryRun bool) ([]*DuplicateGroup, error) {
+	ids, err := s.repo.DuplicateGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]*DuplicateGroup, 0, len(ids))
+	for _, group := range ids {
+		bookmarks := make([]*model.Bookmark, 0, len(group))
+		for _, id := range group {
+			bookmark, err := s.repo.GetByID(id)
+			if err != nil {
+				return groups, err
+			}
+			if bookmark != nil {
+				bookmarks = append(bookmarks, bookmark)
+			}
+		}
+		if len(bookmarks) < 2 {
+			continue
+		}
+		result := &DuplicateGroup{Keep: bookmarks[0], Duplicates: bookmarks[1:]}
+		groups = append(groups, result)
+
+		if dryRun {
+			continue
+		}
+		for _, duplicate := range result.Duplicates {
+			merged, err := s.Merge(result.Keep.ID, duplicate.ID)
+			if err != nil {
+				return groups, err
+			}
+			result.Keep = merged
+		}
+	}
+	return groups, nil
+}
+
+// Merge folds the bookmark dropID into keepID and deletes it. The kept
+// bookmark gains the other's tags, description, notes, annotations,
+// highlights, content history and archives, and keeps the earlier creation
+// date. Adding the dropped URL again returns the kept bookmark.
+func (s *BookmarkService) Merge(keepID, dropID int64) (*model.Bookmark, error) {
+	if err := s.repo.Merge(keepID, dropID); err != nil {
+		return nil, err
+	}
+	if err := s.search.DeleteBookmark(dropID); err != nil {
+		log.Warn().Err(err).Int64("id", dropID).Msg("Failed to remove bookmark from index")
+	}
+
+	bookmark, err := s.repo.GetByID(keepID)
+	if err != nil {
+		return nil, err
+	}
 	if bookmark == nil {
-		return fmt.Errorf("bookmark not found.")
+		return nil, fmt.Errorf("bookmark not found.")
 	}
-	bookmark.AddTag(model.NewTag(tagName))
-	return s.repo.Update(bookmark)
+	if err := s.search.IndexBookmark(bookmark); err != nil {
+		log.Warn().Err(err).Int64("id", bookmark.ID).Msg("Failed to index bookmark")
+	}
+	return bookmark, nil
 }
 
-func (s *BookmarkService) RemoveTag(bookmarkID int64, tagName string) error {
-	bookmark, err := s.repo.GetByID(bookmarkID)
+// SetFavicons sets the cache site icons are fetched into when bookmarks are
+// fetched. Without one no icons are fetched.
+func (s *BookmarkService) SetFavicons(cache *favicon.Cache) {
+	s.favicons = cache
+}
+
+// fetchFavicon fetches the icon of the bookmark's site. An icon is only
+// decoration, so failures are logged and the previous icon is kept.
+func (s *BookmarkService) fetchFavicon(bookmark *model.Bookmark, iconURL string) string {
+	if s.favicons == nil {
+		return bookmark.Favicon
+	}
+	path, err := s.favicons.Fetch(bookmark.URL, iconURL)
+	if err != nil {
+		log.Warn().Err(err).Int64("id", bookmark.ID).Msg("Failed to fetch favicon")
+		return bookmark.Favicon
+	}
+	if path == "" {
+		return bookmark.Favicon
+	}
+	return path
+}
+
+// RuleResult lists the tags the tagging rules add to a bookmark.
+type RuleResult struct {
+	Bookmark *model.Bookmark
+	Tags     []string
+	Rules    []string
+}
+
+// ApplyRules evaluates the tagging rules over the whole library and adds the
+// missing tags. With dryRun set nothing is changed and the results show what
+// would be added.
+func (s *BookmarkService) ApplyRules(dryRun bool) ([]*RuleResult, error) {
+	bookmarks, err := s.repo.Find(repository.ListOptions{Limit: -1})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*RuleResult
+	for _, bookmark := range bookmarks {
+		// Rule tags may be aliases of tags the bookmark already has.
+		found, err := s.repo.ResolveTags(s.rules.Tags(bookmark)...)
+		if err != nil {
+			return results, err
+		}
+		var tags []string
+		for _, tagName := range found {
+			if !bookmark.HasTag(tagName) && !containsString(tags, tagName) {
+				tags = append(tags, tagName)
+			}
+		}
+		if len(tags) == 0 {
+			continue
+		}
+		result := &RuleResult{Bookmark: bookmark, Tags: tags}
+		for _, match := range s.rules.Evaluate(bookmark) {
+			result.Rules = append(result.Rules, match.Rule)
+		}
+		results = append(results, result)
+
+		if dryRun {
+			continue
+		}
+		if err := s.addRuleTags(bookmark, tags); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// addRuleTags saves tags added by rules and reindexes the bookmark.
+func (s *BookmarkService) addRuleTags(bookmark *model.Bookmark, tags []string) error {
+	if err := s.repo.AddTags(bookmark.ID, tags); err != nil {
+		return err
+	}
+	for _, tagName := range tags {
+		bookmark.AddTag(model.NewTag(tagName))
+	}
+	return s.search.IndexBookmark(bookmark)
+}
+
+// QueueArchive schedules an offline snapshot of the bookmark on the
+// background queue.
+func (s *BookmarkService) QueueArchive(id int64) error {
+	return s.queue.Enqueue(model.JobKindArchive, id)
+}
+
+// Enrich fetches the bookmark's page and stores the extracted title,
+// description, content and summary, then reindexes it.
+func (s *BookmarkService) Enrich(id int64) (*model.Bookmark, error) {
+	bookmark, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if bookmark == nil {
+		return nil, fmt.Errorf("bookmark not found.")
+	}
+
+	page, err := s.extractor.Extract(bookmark.URL, extractor.Validators{})
+	if err != nil {
+		return nil, fmt.Errorf("content extraction failed: %w", err)
+	}
+
+	if page.Title != "" {
+		bookmark.Title = page.Title
+	}
+	bookmark.Description = page.Description
+	bookmark.Content = page.Content
+	bookmark.Summary = s.summarize(bookmark, page.Content)
+	bookmark.Author = page.Author
+	bookmark.ContentType = page.ContentType
+	bookmark.PageCount = page.PageCount
+	bookmark.PublishedAt = page.PublishedAt
+	bookmark.Favicon = s.fetchFavicon(bookmark, page.IconURL)
+	bookmark.URLKey = s.urlKey(bookmark, page.CanonicalURL)
+	setTextStats(bookmark, textstats.Analyze(page.Content))
+	bookmark.FetchState = model.FetchStateDone
+	bookmark.ETag = page.Validators.ETag
+	bookmark.LastModified = page.Validators.LastModified
+	fetchedAt := time.Now().UTC()
+	bookmark.FetchedAt = &fetchedAt
+
+	if err := s.repo.UpdateExtracted(bookmark); err != nil {
+		return nil, err
+	}
+
+	// Tags from the site itself, such as a repository's topics, go in
+	// alongside the ones from tagging rules.
+	found, err := s.repo.ResolveTags(append(s.rules.Tags(bookmark), page.Tags...)...)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, tagName := range found {
+		if !bookmark.HasTag(tagName) && !containsString(tags, tagName) {
+			tags = append(tags, tagName)
+		}
+	}
+	if len(tags) > 0 {
+		if err := s.repo.AddTags(bookmark.ID, tags); err != nil {
+			return nil, err
+		}
+		for _, tagName := range tags {
+			bookmark.AddTag(model.NewTag(tagName))
+		}
+	}
+
+	if err := s.search.IndexBookmark(bookmark); err != nil {
+		return nil, fmt.Errorf("failed to index bookmark: %w", err)
+	}
+
+	return bookmark, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func setTextStats(bookmark *model.Bookmark, stats textstats.Stats) {
+	bookmark.WordCount = stats.Words
+	bookmark.ReadingTime = stats.ReadingTime
+	bookmark.Lang = stats.Lang
+}
+
+// BackfillTextStats computes the word count, reading time and language of
+// bookmarks fetched before these were tracked.
+func (s *BookmarkService) BackfillTextStats() error {
+	bookmarks, err := s.repo.ListMissingStats()
 	if err != nil {
 		return err
 	}
 
+	for _, bookmark := range bookmarks {
+		setTextStats(bookmark, textstats.Analyze(bookmark.Content))
+		fields := map[string]interface{}{
+			"word_count":   bookmark.WordCount,
+			"reading_time": bookmark.ReadingTime,
+			"lang":         bookmark.Lang,
+		}
+		if err := s.repo.UpdateFields(bookmark.ID, fields); err != nil {
+			return err
+		}
+
+		// The listing does not load tags, which the index needs.
+		indexed, err := s.repo.GetByID(bookmark.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.search.IndexBookmark(indexed); err != nil {
+			log.Warn().Err(err).Int64("id", bookmark.ID).Msg("Failed to index bookmark")
+		}
+	}
+
+	if len(bookmarks) > 0 {
+		log.Info().Int("count", len(bookmarks)).Msg("Computed text statistics for existing bookmarks")
+	}
+	return nil
+}
+
+// summarize returns a summary of a bookmark's content. A failing summarizer
+// leaves the bookmark without a summary rather than failing the fetch.
+func (s *BookmarkService) summarize(bookmark *model.Bookmark, content string) string {
+	text, err := s.summarizer.Summarize(content)
+	if err != nil {
+		log.Warn().Err(err).Int64("id", bookmark.ID).Msg("Failed to summarize bookmark")
+		return ""
+	}
+	return text
+}
+
+// RefreshResult describes the outcome of refreshing a single bookmark.
+type RefreshResult struct {
+	Bookmark    *model.Bookmark
+	NotModified bool
+	Changed     []string
+	Err         error
+}
+
+// Refresh re-fetches a bookmark's page using the validators from the last
+// fetch and writes back only the fields whose extracted value changed.
+func (s *BookmarkService) Refresh(id int64) (*RefreshResult, error) {
+	bookmark, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
 	if bookmark == nil {
-		return fmt.Errorf("bookmark not found.")
+		return nil, fmt.Errorf("bookmark not found.")
 	}
 
-	bookmark.RemoveTag(tagName)
-	return s.repo.Update(bookmark)
+	validators := extractor.Validators{ETag: bookmark.ETag, LastModified: bookmark.LastModified}
+	if bookmark.Content == "" {
+		// Nothing usable was stored, so a 304 would leave us with nothing.
+		validators = extractor.Validators{}
+	}
+
+	page, err := s.extractor.Extract(bookmark.URL, validators)
+	if err != nil {
+		return nil, fmt.Errorf("content extraction failed: %w", err)
+	}
+
+	previousContent := bookmark.Content
+	previousFetchedAt := bookmark.CreatedAt
+	if bookmark.FetchedAt != nil {
+		previousFetchedAt = *bookmark.FetchedAt
+	}
+
+	fetchedAt := time.Now().UTC()
+	bookmark.FetchedAt = &fetchedAt
+	fields := map[string]interface{}{"fetched_at": bookmark.FetchedAt}
+	result := &RefreshResult{Bookmark: bookmark, NotModified: page.NotModified}
+
+	if !page.NotModified {
+		set := func(column string, current *string, value string) {
+			if *current != value {
+				*current = value
+				fields[column] = value
+				result.Changed = append(result.Changed, column)
+			}
+		}
+
+		if page.Title != "" {
+			set("title", &bookmark.Title, page.Title)
+		}
+		set("description", &bookmark.Description, page.Description)
+		set("content", &bookmark.Content, page.Content)
+		if _, ok := fields["content"]; ok {
+			set("summary", &bookmark.Summary, s.summarize(bookmark, page.Content))
+		}
+		set("author", &bookmark.Author, page.Author)
+		set("content_type", &bookmark.ContentType, page.ContentType)
+		set("favicon", &bookmark.Favicon, s.fetchFavicon(bookmark, page.IconURL))
+		set("url_key", &bookmark.URLKey, s.urlKey(bookmark, page.CanonicalURL))
+		setInt := func(column string, current *int, value int) {
+			if *current != value {
+				*current = value
+				fields[column] = value
+				result.Changed = append(result.Changed, column)
+			}
+		}
+		setInt("page_count", &bookmark.PageCount, page.PageCount)
+		if page.PublishedAt != nil && (bookmark.PublishedAt == nil || !page.PublishedAt.Equal(*bookmark.PublishedAt)) {
+			bookmark.PublishedAt = page.PublishedAt
+			fields["published_at"] = bookmark.PublishedAt
+			result.Changed = append(result.Changed, "published_at")
+		}
+		if _, ok := fields["content"]; ok {
+			stats := textstats.Analyze(page.Content)
+			setInt("word_count", &bookmark.WordCount, stats.Words)
+			setInt("reading_time", &bookmark.ReadingTime, stats.ReadingTime)
+			set("lang", &bookmark.Lang, stats.Lang)
+		}
+
+		if bookmark.FetchState != model.FetchStateDone {
+			bookmark.FetchState = model.FetchStateDone
+			fields["fetch_state"] = bookmark.FetchState
+		}
+		if page.Validators.ETag != bookmark.ETag {
+			bookmark.ETag = page.Validators.ETag
+			fields["etag"] = bookmark.ETag
+		}
+		if page.Validators.LastModified != bookmark.LastModified {
+			bookmark.LastModified = page.Validators.LastModified
+			fields["last_modified"] = bookmark.LastModified
+		}
+	}
+
+	if len(result.Changed) > 0 {
+		bookmark.UpdatedAt = time.Now()
+		fields["updated_at"] = bookmark.UpdatedAt
+	}
+
+	if _, ok := fields["content"]; ok && previousContent != "" {
+		version := model.NewContentVersion(bookmark.ID, previousContent, previousFetchedAt)
+		if err := s.versions.Create(version); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateFields(bookmark.ID, fields); err != nil {
+		return nil, err
+	}
+
+	if len(result.Changed) > 0 {
+		if err := s.search.IndexBookmark(bookmark); err != nil {
+			return result, fmt.Errorf("failed to index bookmark: %w", err)
+		}
+	}
+
+	return result, nil
 }
 
-func (s *BookmarkService) GetAllTags() ([]model.Tag, error) {
-	return s.repo.GetAllTags()
+// RefreshStale refreshes every bookmark not fetched within olderThan,
+// optionally restricted to a tag. Per-bookmark failures are reported in the
+// results rather than aborting the run.
+func (s *BookmarkService) RefreshStale(olderThan time.Duration, tag string) ([]*RefreshResult, error) {
+	if tag != "" {
+		tags, err := s.repo.ResolveTags(tag)
+		if err != nil {
+			return nil, err
+		}
+		tag = tags[0]
+	}
+	bookmarks, err := s.repo.ListStale(tag, time.Now().UTC().Add(-olderThan))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*RefreshResult, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		result, err := s.Refresh(bookmark.ID)
+		if result == nil {
+			result = &RefreshResult{Bookmark: bookmark}
+		}
+		result.Err = err
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ContentVersions returns the previous contents kept for a bookmark, newest
+// first.
+func (s *BookmarkService) ContentVersions(bookmarkID int64) ([]*model.ContentVersion, error) {
+	return s.versions.ListByBookmark(bookmarkID)
+}
+
+// DiffVersions returns a unified diff between two content versions of a
+// bookmark. A version ID of 0 refers to the bookmark's current content.
+func (s *BookmarkService) DiffVersions(bookmarkID, fromID, toID int64) (string, error) {
+	bookmark, err := s.repo.GetByID(bookmarkID)
+	if err != nil {
+		return "", err
+	}
+	if bookmark == nil {
+		return "", fmt.Errorf("bookmark not found.")
+	}
+
+	load := func(id int64) (content, name string, err error) {
+		if id == 0 {
+			return bookmark.Content, "current", nil
+		}
+		version, err := s.versions.GetByID(id)
+		if err != nil {
+			return "", "", err
+		}
+		if version == nil || version.BookmarkID != bookmarkID {
+			return "", "", fmt.Errorf("content version %d not found", id)
+		}
+		return version.Content, fmt.Sprintf("%s (%s)", version.ShortHash(), version.FetchedAt.Format("2006-01-02 15:04")), nil
+	}
+
+	from, fromName, err := load(fromID)
+	if err != nil {
+		return "", err
+	}
+	to, toName, err := load(toID)
+	if err != nil {
+		return "", err
+	}
+
+	return diff.Unified(from, to, fromName, toName, 3), nil
+}
+
+// QueueRefresh schedules a refresh on the background queue.
+func (s *BookmarkService) QueueRefresh(id int64) error {
+	return s.queue.Enqueue(model.JobKindRefresh, id)
+}
+
+func (s *BookmarkService) refreshJob(job *model.Job) error {
+	_, err := s.Refresh(job.BookmarkID)
+	return err
+}
+
+func (s *BookmarkService) enrichJob(job *model.Job) error {
+	_, err := s.Enrich(job.BookmarkID)
+	return err
+}
+
+func (s *BookmarkService) jobDone(job *model.Job) {
+	if job.Kind != model.JobKindEnrich || job.Status != model.JobStatusFailed {
+		return
+	}
+	if err := s.repo.SetFetchState(job.BookmarkID, model.FetchStateFailed); err != nil {
+		log.Error().Err(err).Int64("id", job.BookmarkID).Msg("Failed to mark bookmark fetch as failed")
+	}
+}
+
+func (s *BookmarkService) Get(id int64) (*model.Bookmark, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *BookmarkService) List(tag string, limit, offset int) ([]*model.Bookmark, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	tags, err := s.repo.ResolveTags(tag)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.List(tags[0], limit, offset)
+}
+
+func (s *BookmarkService) Find(opts repository.ListOptions) ([]*model.Bookmark, error) {
+	if opts.Limit == 0 {
+		opts.Limit = 20
+	}
+	if opts.Tag != "" {
+		tags, err := s.repo.ResolveTags(opts.Tag)
+		if err != nil {
+			return nil, err
+		}
+		opts.Tag = tags[0]
+	}
+	return s.repo.Find(opts)
+}
+
+func (s *BookmarkService) Update(bookmark *model.Bookmark) error {
+	return s.repo.Update(bookmark)
+}
+
+func (s *BookmarkService) Delete(id int64) error {
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	if err := s.search.DeleteBookmark(id); err != nil {
+		log.Warn().Err(err).Int64("id", id).Msg("Failed to remove bookmark from index")
+	}
+	return nil
+}
+
+func (s *BookmarkService) AddTag(bookmarkID int64, tagName string) error {
+	bookmark, err := s.repo.GetByID(bookmarkID)
+	if err != nil {
+		return err
+	}
+	if bookmark == nil {
+		return fmt.Errorf("bookmark not found.")
+	}
+	tags, err := s.repo.ResolveTags(tagName)
+	if err != nil {
+		return err
+	}
+	bookmark.AddTag(model.NewTag(tags[0]))
+	if err := s.repo.Update(bookmark); err != nil {
+		return err
+	}
+	return s.reindex([]int64{bookmarkID})
+}
+
+func (s *BookmarkService) RemoveTag(bookmarkID int64, tagName string) error {
+	bookmark, err := s.repo.GetByID(bookmarkID)
+	if err != nil {
+		return err
+	}
+
+	if bookmark == nil {
+		return fmt.Errorf("bookmark not found.")
+	}
+	tags, err := s.repo.ResolveTags(tagName)
+	if err != nil {
+		return err
+	}
+
+	bookmark.RemoveTag(tags[0])
+	if err := s.repo.Update(bookmark); err != nil {
+		return err
+	}
+	return s.reindex([]int64{bookmarkID})
+}
+
+// SetNotes replaces a bookmark's notes.
+func (s *BookmarkService) SetNotes(bookmarkID int64, notes string) error {
+	bookmark, err := s.repo.GetByID(bookmarkID)
+	if err != nil {
+		return err
+	}
+	if bookmark == nil {
+		return fmt.Errorf("bookmark not found.")
+	}
+	notes = strings.TrimSpace(notes)
+	if notes == bookmark.Notes {
+		return nil
+	}
+	if err := s.repo.UpdateFields(bookmarkID, map[string]interface{}{"notes": notes, "updated_at": time.Now()}); err != nil {
+		return err
+	}
+	return s.reindex([]int64{bookmarkID})
+}
+
+// AddAnnotation adds a timestamped annotation to a bookmark.
+func (s *BookmarkService) AddAnnotation(bookmarkID int64, text string) (*model.Annotation, error) {
+	bookmark, err := s.repo.GetByID(bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+	if bookmark == nil {
+		return nil, fmt.Errorf("bookmark not found.")
+	}
+	annotation := model.NewAnnotation(bookmarkID, text)
+	if annotation.Text == "" {
+		return nil, fmt.Errorf("annotation cannot be empty")
+	}
+	if err := s.repo.AddAnnotation(annotation); err != nil {
+		return nil, err
+	}
+	return annotation, s.reindex([]int64{bookmarkID})
+}
+
+func (s *BookmarkService) GetAnnotation(id int64) (*model.Annotation, error) {
+	return s.repo.GetAnnotation(id)
+}
+
+// UpdateAnnotation replaces the text of an annotation. Empty text deletes
+// it.
+func (s *BookmarkService) UpdateAnnotation(id int64, text string) error {
+	annotation, err := s.repo.GetAnnotation(id)
+	if err != nil {
+		return err
+	}
+	if annotation == nil {
+		return fmt.Errorf("annotation not found")
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return s.DeleteAnnotation(id)
+	}
+	annotation.Text = text
+	if err := s.repo.UpdateAnnotation(annotation); err != nil {
+		return err
+	}
+	return s.reindex([]int64{annotation.BookmarkID})
+}
+
+func (s *BookmarkService) DeleteAnnotation(id int64) error {
+	annotation, err := s.repo.GetAnnotation(id)
+	if err != nil {
+		return err
+	}
+	if annotation == nil {
+		return fmt.Errorf("annotation not found")
+	}
+	if err := s.repo.DeleteAnnotation(id); err != nil {
+		return err
+	}
+	return s.reindex([]int64{annotation.BookmarkID})
+}
+
+// AddHighlight saves a passage of a bookmark's content with an optional
+// comment. offset is where the passage starts in the content; a negative
+// offset, or one the passage is not at, looks the passage up instead.
+// Whitespace around the passage is left out.
+func (s *BookmarkService) AddHighlight(bookmarkID int64, text string, offset int, comment string) (*model.Highlight, error) {
+	bookmark, err := s.repo.GetByID(bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+	if bookmark == nil {
+		return nil, fmt.Errorf("bookmark not found.")
+	}
+
+	trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+	offset += len(text) - len(trimmed)
+	text = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+	if text == "" {
+		return nil, fmt.Errorf("highlight cannot be empty")
+	}
+	content := bookmark.Content
+	if offset < 0 || offset+len(text) > len(content) || content[offset:offset+len(text)] != text {
+		offset = strings.Index(content, text)
+		if offset < 0 {
+			return nil, fmt.Errorf("passage not found in the bookmark's content")
+		}
+	}
+
+	highlight := model.NewHighlight(bookmarkID, text, offset, strings.TrimSpace(comment))
+	if err := s.repo.AddHighlight(highlight); err != nil {
+		return nil, err
+	}
+	return highlight, s.reindex([]int64{bookmarkID})
+}
+
+func (s *BookmarkService) GetHighlight(id int64) (*model.Highlight, error) {
+	return s.repo.GetHighlight(id)
+}
+
+func (s *BookmarkService) SetHighlightComment(id int64, comment string) error {
+	highlight, err := s.repo.GetHighlight(id)
+	if err != nil {
+		return err
+	}
+	if highlight == nil {
+		return fmt.Errorf("highlight not found")
+	}
+	if err := s.repo.SetHighlightComment(id, strings.TrimSpace(comment)); err != nil {
+		return err
+	}
+	return s.reindex([]int64{highlight.BookmarkID})
+}
+
+func (s *BookmarkService) DeleteHighlight(id int64) error {
+	highlight, err := s.repo.GetHighlight(id)
+	if err != nil {
+		return err
+	}
+	if highlight == nil {
+		return fmt.Errorf("highlight not found")
+	}
+	if err := s.repo.DeleteHighlight(id); err != nil {
+		return err
+	}
+	return s.reindex([]int64{highlight.BookmarkID})
+}
+
+// Highlights returns the bookmarks with highlights whose passage or comment
+// contains query, most recently highlighted first. Each bookmark carries
+// only its matching highlights, in content order. An empty query matches
+// every highlight.
+func (s *BookmarkService) Highlights(query string) ([]*model.Bookmark, error) {
+	highlights, err := s.repo.ListHighlights(strings.TrimSpace(query))
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make(map[int64]bool, len(highlights))
+	seen := make(map[int64]bool)
+	var bookmarks []*model.Bookmark
+	for _, highlight := range highlights {
+		matched[highlight.ID] = true
+		if seen[highlight.BookmarkID] {
+			continue
+		}
+		seen[highlight.BookmarkID] = true
+		bookmark, err := s.repo.GetByID(highlight.BookmarkID)
+		if err != nil {
+			return nil, err
+		}
+		if bookmark != nil {
+			bookmarks = append(bookmarks, bookmark)
+		}
+	}
+
+	for _, bookmark := range bookmarks {
+		var kept []model.Highlight
+		for _, highlight := range bookmark.Highlights {
+			if matched[highlight.ID] {
+				kept = append(kept, highlight)
+			}
+		}
+		bookmark.Highlights = kept
+	}
+	return bookmarks, nil
+}
+
+// SetReadState moves a bookmark to a read state, recording when it was
+// read.
+func (s *BookmarkService) SetReadState(bookmarkID int64, state string) (*model.Bookmark, error) {
+	if !model.IsReadState(state) {
+		return nil, fmt.Errorf("invalid read state %q, expected one of %s", state, strings.Join(model.ReadStates, ", "))
+	}
+	bookmark, err := s.repo.GetByID(bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+	if bookmark == nil {
+		return nil, fmt.Errorf("bookmark not found.")
+	}
+	if bookmark.ReadState == state {
+		return bookmark, nil
+	}
+	bookmark.SetReadState(state, time.Now())
+	if err := s.saveReadState(bookmark); err != nil {
+		return nil, err
+	}
+	return bookmark, nil
+}
+
+func (s *BookmarkService) saveReadState(bookmark *model.Bookmark) error {
+	fields := map[string]interface{}{
+		"read_state":    bookmark.ReadState,
+		"read_at":       bookmark.ReadAt,
+		"read_progress": bookmark.ReadProgress,
+	}
+	if err := s.repo.UpdateFields(bookmark.ID, fields); err != nil {
+		return err
+	}
+	return s.reindex([]int64{bookmark.ID})
+}
+
+// SetPriority sets where a bookmark comes in the read-later queue when it is
+// ordered by priority. Higher priorities come first.
+func (s *BookmarkService) SetPriority(bookmarkID int64, priority int) error {
+	bookmark, err := s.repo.GetByID(bookmarkID)
+	if err != nil {
+		return err
+	}
+	if bookmark == nil {
+		return fmt.Errorf("bookmark not found.")
+	}
+	if bookmark.Priority == priority {
+		return nil
+	}
+	if err := s.repo.UpdateFields(bookmarkID, map[string]interface{}{"priority": priority}); err != nil {
+		return err
+	}
+	return s.reindex([]int64{bookmarkID})
+}
+
+// SetReadProgress records how far a bookmark has been read, in percent. An
+// unread bookmark with some progress is being read, and one read to the end
+// is read.
+func (s *BookmarkService) SetReadProgress(bookmarkID int64, progress int) (*model.Bookmark, error) {
+	progress = max(0, min(progress, 100))
+	bookmark, err := s.repo.GetByID(bookmarkID)
+	if err != nil {
+		return nil, err
+	}
+	if bookmark == nil {
+		return nil, fmt.Errorf("bookmark not found.")
+	}
+	if progress == bookmark.ReadProgress {
+		return bookmark, nil
+	}
+
+	bookmark.ReadProgress = progress
+	switch {
+	case progress == 100 && bookmark.InInbox():
+		bookmark.SetReadState(model.ReadStateRead, time.Now())
+	case progress > 0 && bookmark.ReadState == model.ReadStateUnread:
+		bookmark.ReadState = model.ReadStateReading
+	}
+	if err := s.saveReadState(bookmark); err != nil {
+		return nil, err
+	}
+	return bookmark, nil
+}
+
+// Inbox returns the read-later queue: bookmarks that are unread or being
+// read, ordered by repository.SortOldest or repository.SortPriority.
+func (s *BookmarkService) Inbox(order, tag string, limit int) ([]*model.Bookmark, error) {
+	if err := checkQueueOrder(order); err != nil {
+		return nil, err
+	}
+	return s.Find(repository.ListOptions{
+		Tag:        tag,
+		ReadStates: []string{model.ReadStateUnread, model.ReadStateReading},
+		Sort:       order,
+		Limit:      limit,
+	})
+}
+
+func checkQueueOrder(order string) error {
+	if order != repository.SortOldest && order != repository.SortPriority {
+		return fmt.Errorf("invalid queue order %q, expected %s or %s", order, repository.SortOldest, repository.SortPriority)
+	}
+	return nil
+}
+
+// Next returns the next unread bookmark in the queue and marks it as being
+// read, or nil if nothing is left to read. With peek it is left unread.
+func (s *BookmarkService) Next(order, tag string, peek bool) (*model.Bookmark, error) {
+	if err := checkQueueOrder(order); err != nil {
+		return nil, err
+	}
+	bookmarks, err := s.Find(repository.ListOptions{
+		Tag:        tag,
+		ReadStates: []string{model.ReadStateUnread},
+		Sort:       order,
+		Limit:      1,
+	})
+	if err != nil || len(bookmarks) == 0 {
+		return nil, err
+	}
+	if peek {
+		return bookmarks[0], nil
+	}
+	return s.SetReadState(bookmarks[0].ID, model.ReadStateReading)
+}
+
+// SetStarred stars or unstars a bookmark.
+func (s *BookmarkService) SetStarred(bookmarkID int64, starred bool) error {
+	return s.setMark(bookmarkID, "starred", starred)
+}
+
+// SetPinned pins a bookmark to the top of the list, or unpins it.
+func (s *BookmarkService) SetPinned(bookmarkID int64, pinned bool) error {
+	return s.setMark(bookmarkID, "pinned", pinned)
+}
+
+// SetRating rates a bookmark from 1 to model.MaxRating. A rating of 0
+// clears it.
+func (s *BookmarkService) SetRating(bookmarkID int64, rating int) error {
+	if rating < 0 || rating > model.MaxRating {
+		return fmt.Errorf("invalid rating %d, expected 1 to %d, or 0 to clear it", rating, model.MaxRating)
+	}
+	return s.setMark(bookmarkID, "rating", rating)
+}
+
+func (s *BookmarkService) setMark(bookmarkID int64, column string, value interface{}) error {
+	bookmark, err := s.repo.GetByID(bookmarkID)
+	if err != nil {
+		return err
+	}
+	if bookmark == nil {
+		return fmt.Errorf("bookmark not found.")
+	}
+	if err := s.repo.UpdateFields(bookmarkID, map[string]interface{}{column: value}); err != nil {
+		return err
+	}
+	return s.reindex([]int64{bookmarkID})
+}
+
+// SuggestTags proposes up to limit tags for a bookmark.
+func (s *BookmarkService) SuggestTags(id int64, limit int) ([]tagsuggest.Suggestion, error) {
+	bookmark, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if bookmark == nil {
+		return nil, fmt.Errorf("bookmark not found.")
+	}
+	return s.suggester.Suggest(bookmark, limit)
+}
+
+// SuggestTagsForURL proposes tags for a URL that is about to be added with
+// the given tags. Only the URL is known at that point, unless it was
+// bookmarked before.
+func (s *BookmarkService) SuggestTagsForURL(urlStr string, tags []string, limit int) ([]tagsuggest.Suggestion, error) {
+	bookmark, err := s.repo.GetByURL(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	if bookmark == nil {
+		bookmark = model.NewBookmark(urlStr, urlStr)
+	}
+	for _, name := range tags {
+		bookmark.AddTag(model.NewTag(name))
+	}
+	return s.suggester.Suggest(bookmark, limit)
+}
+
+func (s *BookmarkService) GetAllTags() ([]model.Tag, error) {
+	return s.repo.GetAllTags()
+}
+
+// TagCounts returns the number of bookmarks carrying each tag or one of its
+// descendants. Unused tags are left out.
+func (s *BookmarkService) TagCounts() (map[string]int, error) {
+	return s.repo.SubtreeTagCounts()
+}
+
+// RenameTag renames a tag and its descendants on every bookmark. Renaming to
+// the name of another tag merges the two.
+func (s *BookmarkService) RenameTag(oldName, newName string) error {
+	oldName, newName = strings.TrimSpace(oldName), strings.TrimSpace(newName)
+	if oldName == "" || newName == "" {
+		return fmt.Errorf("tag name cannot be empty")
+	}
+	ids, err := s.repo.RenameTag(oldName, newName)
+	if err != nil {
+		return err
+	}
+	return s.reindex(ids)
+}
+
+// MergeTags replaces the source tags with target on every bookmark and
+// deletes them.
+func (s *BookmarkService) MergeTags(sources []string, target string) error {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return fmt.Errorf("tag name cannot be empty")
+	}
+	ids, err := s.repo.MergeTags(sources, target)
+	if err != nil {
+		return err
+	}
+	return s.reindex(ids)
+}
+
+// DeleteTag removes a tag and its descendants from every bookmark and deletes
+// them.
+func (s *BookmarkService) DeleteTag(name string) error {
+	ids, err := s.repo.DeleteTag(name)
+	if err != nil {
+		return err
+	}
+	return s.reindex(ids)
+}
+
+// PruneTags deletes tags that no bookmark carries, directly or through a
+// descendant, and returns their names.
+func (s *BookmarkService) PruneTags() ([]string, error) {
+	return s.repo.PruneTags()
+}
+
+func (s *BookmarkService) TagAliases() ([]model.TagAlias, error) {
+	return s.repo.TagAliases()
+}
+
+// AliasTag makes alias another name for tag: tags entered as alias are saved
+// as tag, searches for alias find tag, and bookmarks already tagged with
+// alias are retagged.
+func (s *BookmarkService) AliasTag(alias, tag string) error {
+	alias, tag = strings.TrimSpace(alias), strings.TrimSpace(tag)
+	if alias == "" || tag == "" {
+		return fmt.Errorf("tag name cannot be empty")
+	}
+	// Aliasing to an alias means aliasing to the tag it stands for.
+	tags, err := s.repo.ResolveTags(tag)
+	if err != nil {
+		return err
+	}
+	ids, err := s.repo.SetTagAlias(alias, tags[0])
+	if err != nil {
+		return err
+	}
+	return s.reindex(ids)
+}
+
+func (s *BookmarkService) RemoveTagAlias(alias string) error {
+	return s.repo.DeleteTagAlias(strings.TrimSpace(alias))
+}
+
+// reindex updates the search index entries of bookmarks whose tags changed.
+func (s *BookmarkService) reindex(ids []int64) error {
+	for _, id := range ids {
+		bookmark, err := s.repo.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if bookmark == nil {
+			continue
+		}
+		if err := s.search.IndexBookmark(bookmark); err != nil {
+			return fmt.Errorf("failed to index bookmark: %w", err)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,118 @@
+// Package bookmarkfile reads and writes the bookmark files browsers import
+// and export: the Netscape bookmark file format, which every major browser
+// understands, and the JSON file Chrome and other Chromium browsers keep in
+// their profile directory.
+package bookmarkfile
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ToolbarNames are the names browsers give the folder shown on their
+// bookmarks toolbar.
+var ToolbarNames = []string{"Bookmarks bar", "Bookmarks Toolbar", "Favorites bar"}
+
+// Folder is a folder of bookmarks. The root folder of a file has no name.
+type Folder struct {
+	Name       string
+	AddedAt    time.Time
+	ModifiedAt time.Time
+	// Toolbar marks the folder shown on the browser's bookmarks toolbar.
+	Toolbar   bool
+	Folders   []*Folder
+	Bookmarks []*Bookmark
+}
+
+type Bookmark struct {
+	URL         string
+	Title       string
+	Description string
+	// Notes are the user's own Markdown notes, which browsers ignore.
+	Notes string
+	// Annotations are the user's dated remarks, which browsers ignore too.
+	Annotations []Annotation
+	Tags        []string
+	AddedAt     time.Time
+}
+
+// Annotation is a remark on a bookmark and the time it was written.
+type Annotation struct {
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsToolbarName reports whether name is one browsers give their toolbar
+// folder.
+func IsToolbarName(name string) bool {
+	for _, toolbar := range ToolbarNames {
+		if strings.EqualFold(name, toolbar) {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the number of bookmarks in the folder and its subfolders.
+func (f *Folder) Count() int {
+	n := len(f.Bookmarks)
+	for _, folder := range f.Folders {
+		n += folder.Count()
+	}
+	return n
+}
+
+// Parse reads a bookmark file in either format, telling them apart by the
+// JSON object Chrome files start with.
+func Parse(r io.Reader) (*Folder, error) {
+	br := bufio.NewReader(r)
+	for {
+		c, _, err := br.ReadRune()
+		if err != nil {
+			return ParseNetscape(br)
+		}
+		if !unicode.IsSpace(c) && c != '\uFEFF' {
+			br.UnreadRune()
+			if c == '{' {
+				return ParseChrome(br)
+			}
+			return ParseNetscape(br)
+		}
+	}
+}
+
+// encodeAnnotations returns annotations as the JSON array both formats keep
+// them in, or "" if there are none.
+func encodeAnnotations(annotations []Annotation) string {
+	if len(annotations) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(annotations)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// decodeAnnotations reads annotations written by encodeAnnotations, leaving
+// out empty ones. Anything else yields none.
+func decodeAnnotations(s string) []Annotation {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var decoded []Annotation
+	if err := json.Unmarshal([]byte(s), &decoded); err != nil {
+		return nil
+	}
+	var annotations []Annotation
+	for _, annotation := range decoded {
+		if annotation.Text = strings.TrimSpace(annotation.Text); annotation.Text != "" {
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations
+}
@@ -0,0 +1,210 @@
+package bookmarkfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// chromeEpochOffset is the number of microseconds between 1601-01-01, the
+// epoch of Chrome's timestamps, and the Unix epoch.
+const chromeEpochOffset = 11644473600 * 1000000
+
+// The names Chrome shows for its root folders.
+const (
+	chromeBarName    = "Bookmarks bar"
+	chromeOtherName  = "Other bookmarks"
+	chromeSyncedName = "Mobile bookmarks"
+)
+
+type chromeFile struct {
+	Roots   chromeRoots `json:"roots"`
+	Version int         `json:"version"`
+}
+
+type chromeRoots struct {
+	BookmarkBar *chromeNode `json:"bookmark_bar"`
+	Other       *chromeNode `json:"other"`
+	Synced      *chromeNode `json:"synced"`
+}
+
+type chromeNode struct {
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Type         string            `json:"type"`
+	URL          string            `json:"url,omitempty"`
+	DateAdded    string            `json:"date_added,omitempty"`
+	DateModified string            `json:"date_modified,omitempty"`
+	MetaInfo     map[string]string `json:"meta_info,omitempty"`
+	Children     []*chromeNode     `json:"children,omitempty"`
+}
+
+// The keys of a bookmark's notes and annotations in its meta_info, the
+// string values Chrome keeps on behalf of extensions.
+const (
+	chromeNotesKey       = "notes"
+	chromeAnnotationsKey = "annotations"
+)
+
+// ParseChrome reads a Chrome "Bookmarks" file. Chrome's root folders become
+// folders of the returned root, leaving out empty ones other than the
+// bookmarks bar.
+func ParseChrome(r io.Reader) (*Folder, error) {
+	var file chromeFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to decode bookmark file: %w", err)
+	}
+
+	root := &Folder{}
+	for _, node := range []struct {
+		node *chromeNode
+		name string
+	}{
+		{file.Roots.BookmarkBar, chromeBarName},
+		{file.Roots.Other, chromeOtherName},
+		{file.Roots.Synced, chromeSyncedName},
+	} {
+		if node.node == nil {
+			continue
+		}
+		folder := chromeFolder(node.node)
+		if folder.Name == "" {
+			folder.Name = node.name
+		}
+		if node.node == file.Roots.BookmarkBar {
+			folder.Toolbar = true
+		} else if len(folder.Folders) == 0 && len(folder.Bookmarks) == 0 {
+			continue
+		}
+		root.Folders = append(root.Folders, folder)
+	}
+	return root, nil
+}
+
+func chromeFolder(node *chromeNode) *Folder {
+	folder := &Folder{
+		Name:       node.Name,
+		AddedAt:    chromeTime(node.DateAdded),
+		ModifiedAt: chromeTime(node.DateModified),
+	}
+	for _, child := range node.Children {
+		switch child.Type {
+		case "folder":
+			folder.Folders = append(folder.Folders, chromeFolder(child))
+		case "url":
+			folder.Bookmarks = append(folder.Bookmarks, &Bookmark{
+				URL:         child.URL,
+				Title:       child.Name,
+				Notes:       strings.TrimSpace(child.MetaInfo[chromeNotesKey]),
+				Annotations: decodeAnnotations(child.MetaInfo[chromeAnnotationsKey]),
+				AddedAt:     chromeTime(child.DateAdded),
+			})
+		}
+	}
+	return folder
+}
+
+// WriteChrome writes folder as a Chrome "Bookmarks" file. Its toolbar folder
+// becomes the bookmarks bar and a folder called "Mobile bookmarks" the
+// mobile bookmarks. Everything else goes in other bookmarks. Chrome has no
+// tags or descriptions, so those are left out; notes and annotations are
+// kept in meta_info.
+func WriteChrome(w io.Writer, folder *Folder) error {
+	var id int
+	nextID := func() string {
+		id++
+		return strconv.Itoa(id)
+	}
+
+	var bar, other, synced *chromeNode
+	var rest []*chromeNode
+	for _, sub := range folder.Folders {
+		node := chromeFolderNode(sub, nextID)
+		switch {
+		case bar == nil && (sub.Toolbar || sub.Name == chromeBarName):
+			bar = node
+		case synced == nil && sub.Name == chromeSyncedName:
+			synced = node
+		case other == nil && sub.Name == chromeOtherName:
+			other = node
+		default:
+			rest = append(rest, node)
+		}
+	}
+	if bar == nil {
+		bar = chromeFolderNode(&Folder{Name: chromeBarName}, nextID)
+	}
+	if other == nil {
+		other = chromeFolderNode(&Folder{Name: chromeOtherName}, nextID)
+	}
+	if synced == nil {
+		synced = chromeFolderNode(&Folder{Name: chromeSyncedName}, nextID)
+	}
+	other.Children = append(other.Children, rest...)
+	for _, b := range folder.Bookmarks {
+		other.Children = append(other.Children, chromeBookmarkNode(b, nextID))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "   ")
+	return enc.Encode(chromeFile{
+		Roots:   chromeRoots{BookmarkBar: bar, Other: other, Synced: synced},
+		Version: 1,
+	})
+}
+
+func chromeFolderNode(folder *Folder, nextID func() string) *chromeNode {
+	node := &chromeNode{
+		ID:           nextID(),
+		Name:         folder.Name,
+		Type:         "folder",
+		DateAdded:    chromeTimestamp(folder.AddedAt),
+		DateModified: chromeTimestamp(folder.ModifiedAt),
+		Children:     []*chromeNode{},
+	}
+	for _, sub := range folder.Folders {
+		node.Children = append(node.Children, chromeFolderNode(sub, nextID))
+	}
+	for _, b := range folder.Bookmarks {
+		node.Children = append(node.Children, chromeBookmarkNode(b, nextID))
+	}
+	return node
+}
+
+func chromeBookmarkNode(b *Bookmark, nextID func() string) *chromeNode {
+	node := &chromeNode{
+		ID:        nextID(),
+		Name:      b.Title,
+		Type:      "url",
+		URL:       b.URL,
+		DateAdded: chromeTimestamp(b.AddedAt),
+	}
+	if b.Notes != "" {
+		node.MetaInfo = map[string]string{chromeNotesKey: b.Notes}
+	}
+	if annotations := encodeAnnotations(b.Annotations); annotations != "" {
+		if node.MetaInfo == nil {
+			node.MetaInfo = make(map[string]string)
+		}
+		node.MetaInfo[chromeAnnotationsKey] = annotations
+	}
+	return node
+}
+
+func chromeTime(s string) time.Time {
+	micros, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || micros <= chromeEpochOffset {
+		return time.Time{}
+	}
+	return time.UnixMicro(micros - chromeEpochOffset).UTC()
+}
+
+func chromeTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return strconv.FormatInt(t.UnixMicro()+chromeEpochOffset, 10)
+}
@@ -0,0 +1,204 @@
+package bookmarkfile
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ParseNetscape reads a Netscape bookmark file. The format is loose HTML:
+// folders are an H3 heading followed by a DL list, bookmarks are links in
+// DT items, and a DD item after a link holds its description. Notes and
+// annotations are read from the links' NOTES and ANNOTATIONS attributes,
+// which WriteNetscape adds.
+func ParseNetscape(r io.Reader) (*Folder, error) {
+	root := &Folder{}
+	var stack []*Folder
+	var pending *Folder       // folder whose DL list comes next
+	var bookmark *Bookmark    // bookmark a DD item describes
+	var text *strings.Builder // text of the element being read
+	var finish func(string)
+
+	current := func() *Folder {
+		if len(stack) == 0 {
+			return root
+		}
+		return stack[len(stack)-1]
+	}
+	flush := func() {
+		if finish != nil {
+			finish(strings.TrimSpace(text.String()))
+			finish = nil
+		}
+	}
+	collect := func(done func(string)) {
+		flush()
+		text = &strings.Builder{}
+		finish = done
+	}
+
+	z := xhtml.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			if z.Err() == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to read bookmark file: %w", z.Err())
+		}
+
+		token := z.Token()
+		switch tt {
+		case xhtml.TextToken:
+			if finish != nil {
+				text.WriteString(token.Data)
+			}
+		case xhtml.StartTagToken:
+			switch token.DataAtom {
+			case atom.H3:
+				folder := &Folder{
+					AddedAt:    unixAttr(token, "add_date"),
+					ModifiedAt: unixAttr(token, "last_modified"),
+					Toolbar:    attr(token, "personal_toolbar_folder") == "true",
+				}
+				parent := current()
+				parent.Folders = append(parent.Folders, folder)
+				pending, bookmark = folder, nil
+				collect(func(s string) { folder.Name = s })
+			case atom.A:
+				b := &Bookmark{
+					URL:         attr(token, "href"),
+					Notes:       strings.TrimSpace(attr(token, "notes")),
+					Annotations: decodeAnnotations(attr(token, "annotations")),
+					AddedAt:     unixAttr(token, "add_date"),
+				}
+				for _, tag := range strings.Split(attr(token, "tags"), ",") {
+					if tag = strings.TrimSpace(tag); tag != "" {
+						b.Tags = append(b.Tags, tag)
+					}
+				}
+				folder := current()
+				folder.Bookmarks = append(folder.Bookmarks, b)
+				bookmark = b
+				collect(func(s string) { b.Title = s })
+			case atom.Dd:
+				if b := bookmark; b != nil {
+					collect(func(s string) { b.Description = s })
+				}
+			case atom.Dt:
+				flush()
+			case atom.Dl:
+				flush()
+				// A list without a heading, such as the outermost one,
+				// holds entries of the folder it appears in.
+				if pending != nil {
+					stack = append(stack, pending)
+				} else {
+					stack = append(stack, current())
+				}
+				pending, bookmark = nil, nil
+			}
+		case xhtml.EndTagToken:
+			switch token.DataAtom {
+			case atom.H3, atom.A:
+				flush()
+			case atom.Dl:
+				flush()
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+				bookmark = nil
+			}
+		}
+	}
+	flush()
+	return root, nil
+}
+
+// WriteNetscape writes folder as a Netscape bookmark file.
+func WriteNetscape(w io.Writer, folder *Folder) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprint(bw, `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file.
+     It will be read and overwritten.
+     DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+`)
+	writeNetscapeList(bw, folder, 0)
+	return bw.Flush()
+}
+
+func writeNetscapeList(w *bufio.Writer, folder *Folder, depth int) {
+	indent := strings.Repeat("    ", depth)
+	fmt.Fprintf(w, "%s<DL><p>\n", indent)
+
+	for _, sub := range folder.Folders {
+		fmt.Fprintf(w, "%s    <DT><H3", indent)
+		writeUnixAttr(w, "ADD_DATE", sub.AddedAt)
+		writeUnixAttr(w, "LAST_MODIFIED", sub.ModifiedAt)
+		if sub.Toolbar {
+			fmt.Fprint(w, ` PERSONAL_TOOLBAR_FOLDER="true"`)
+		}
+		fmt.Fprintf(w, ">%s</H3>\n", html.EscapeString(sub.Name))
+		writeNetscapeList(w, sub, depth+1)
+	}
+
+	for _, b := range folder.Bookmarks {
+		fmt.Fprintf(w, `%s    <DT><A HREF="%s"`, indent, html.EscapeString(b.URL))
+		writeUnixAttr(w, "ADD_DATE", b.AddedAt)
+		if len(b.Tags) > 0 {
+			fmt.Fprintf(w, ` TAGS="%s"`, html.EscapeString(strings.Join(b.Tags, ",")))
+		}
+		if b.Notes != "" {
+			// Keep each entry on one line, as browsers write them.
+			notes := strings.ReplaceAll(html.EscapeString(b.Notes), "\n", "&#10;")
+			fmt.Fprintf(w, ` NOTES="%s"`, notes)
+		}
+		if annotations := encodeAnnotations(b.Annotations); annotations != "" {
+			fmt.Fprintf(w, ` ANNOTATIONS="%s"`, html.EscapeString(annotations))
+		}
+		fmt.Fprintf(w, ">%s</A>\n", html.EscapeString(b.Title))
+		if b.Description != "" {
+			fmt.Fprintf(w, "%s    <DD>%s\n", indent, html.EscapeString(b.Description))
+		}
+	}
+
+	fmt.Fprintf(w, "%s</DL><p>\n", indent)
+}
+
+func attr(token xhtml.Token, name string) string {
+	for _, a := range token.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// unixAttr reads a time stored as seconds since the Unix epoch.
+func unixAttr(token xhtml.Token, name string) time.Time {
+	seconds, err := strconv.ParseInt(attr(token, name), 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}
+	}
+	// Some browsers write microseconds.
+	if seconds > 1e12 {
+		return time.UnixMicro(seconds).UTC()
+	}
+	return time.Unix(seconds, 0).UTC()
+}
+
+func writeUnixAttr(w *bufio.Writer, name string, t time.Time) {
+	if !t.IsZero() {
+		fmt.Fprintf(w, ` %s="%d"`, name, t.Unix())
+	}
+}
@@ -0,0 +1,126 @@
+package canonical
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultTrackingParams are query parameters added by analytics and ad
+// platforms that never change what page a URL points to. A trailing "*"
+// matches any parameter with that prefix.
+var DefaultTrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "gbraid", "wbraid", "msclkid",
+	"mc_cid", "mc_eid", "igshid", "yclid", "twclid", "_hsenc", "_hsmi",
+	"mkt_tok", "oly_anon_id", "oly_enc_id", "vero_id", "_ga", "_gl",
+}
+
+// Canonicalizer normalizes URLs so that trivially different URLs of the same
+// page can be recognized as duplicates.
+type Canonicalizer struct {
+	exact    map[string]bool
+	prefixes []string
+}
+
+// NewCanonicalizer returns a canonicalizer that strips the given tracking
+// parameters, matched case-insensitively.
+func NewCanonicalizer(trackingParams []string) *Canonicalizer {
+	c := &Canonicalizer{exact: make(map[string]bool)}
+	for _, param := range trackingParams {
+		param = strings.ToLower(param)
+		if prefix, ok := strings.CutSuffix(param, "*"); ok {
+			c.prefixes = append(c.prefixes, prefix)
+		} else {
+			c.exact[param] = true
+		}
+	}
+	return c
+}
+
+func (c *Canonicalizer) isTracking(param string) bool {
+	param = strings.ToLower(param)
+	if c.exact[param] {
+		return true
+	}
+	for _, prefix := range c.prefixes {
+		if strings.HasPrefix(param, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Clean removes tracking parameters from rawURL and lowercases its scheme
+// and host, leaving everything else as the user gave it.
+func (c *Canonicalizer) Clean(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if u.RawQuery != "" {
+		u.RawQuery = c.stripTracking(u.RawQuery, false)
+	}
+	return u.String(), nil
+}
+
+// stripTracking drops tracking parameters from a query string. With sorted
+// set the remaining parameters are sorted; otherwise their order is kept.
+func (c *Canonicalizer) stripTracking(rawQuery string, sorted bool) string {
+	var kept []string
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if !c.isTracking(name) {
+			kept = append(kept, pair)
+		}
+	}
+	if sorted {
+		sort.Strings(kept)
+	}
+	return strings.Join(kept, "&")
+}
+
+// Key returns the duplicate detection key of rawURL. URLs with the same key
+// are taken to be the same page: the scheme, a "www." prefix, default ports,
+// trailing slashes, the fragment, tracking parameters and the order of the
+// other query parameters are all ignored. Fragments that look like
+// single-page app routes ("#!/..." or "#/...") are kept.
+func (c *Canonicalizer) Key(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid URL %q: no host", rawURL)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	host = strings.TrimPrefix(host, "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host = net.JoinHostPort(host, port)
+	}
+
+	// Re-escaping the decoded path normalizes needless percent-encoding and
+	// the case of escapes.
+	path := strings.TrimRight((&url.URL{Path: u.Path}).EscapedPath(), "/")
+
+	key := host + path
+	if u.RawQuery != "" {
+		if query := c.stripTracking(u.RawQuery, true); query != "" {
+			key += "?" + query
+		}
+	}
+	if strings.HasPrefix(u.Fragment, "!") || strings.HasPrefix(u.Fragment, "/") {
+		key += "#" + u.Fragment
+	}
+	return key, nil
+}
@@ -0,0 +1,444 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/san-kum/bookmarker/internal/repository"
+	"github.com/san-kum/bookmarker/internal/service/bookmarkfile"
+)
+
+// CollectionPathSeparator separates the levels of a collection path such as
+// "Bookmarks bar/Recipes".
+const CollectionPathSeparator = "/"
+
+type CollectionService struct {
+	repo      *repository.CollectionRepository
+	bookmarks *BookmarkService
+}
+
+func NewCollectionService(repo *repository.CollectionRepository, bookmarks *BookmarkService) *CollectionService {
+	return &CollectionService{
+		repo:      repo,
+		bookmarks: bookmarks,
+	}
+}
+
+// List returns every collection, each level ordered by position.
+func (s *CollectionService) List() ([]*model.Collection, error) {
+	return s.repo.List()
+}
+
+func (s *CollectionService) Get(id int64) (*model.Collection, error) {
+	return s.repo.GetByID(id)
+}
+
+func splitCollectionPath(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, CollectionPathSeparator) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Lookup returns the collection at path, such as "Bookmarks bar/Recipes".
+func (s *CollectionService) Lookup(path string) (*model.Collection, error) {
+	names := splitCollectionPath(path)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("collection path cannot be empty")
+	}
+
+	var collection *model.Collection
+	for _, name := range names {
+		var parentID *int64
+		if collection != nil {
+			parentID = &collection.ID
+		}
+		next, err := s.repo.Find(parentID, name)
+		if err != nil {
+			return nil, err
+		}
+		if next == nil {
+			return nil, fmt.Errorf("collection %q not found", path)
+		}
+		collection = next
+	}
+	return collection, nil
+}
+
+// Create returns the collection at path, creating it and any missing parent
+// collections.
+func (s *CollectionService) Create(path string) (*model.Collection, error) {
+	names := splitCollectionPath(path)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("collection path cannot be empty")
+	}
+
+	var collection *model.Collection
+	for _, name := range names {
+		var parentID *int64
+		if collection != nil {
+			parentID = &collection.ID
+		}
+		next, err := s.child(parentID, name)
+		if err != nil {
+			return nil, err
+		}
+		collection = next
+	}
+	return collection, nil
+}
+
+// child returns the child of parentID called name, creating it if needed.
+func (s *CollectionService) child(parentID *int64, name string) (*model.Collection, error) {
+	collection, err := s.repo.Find(parentID, name)
+	if err != nil || collection != nil {
+		return collection, err
+	}
+	collection = &model.Collection{Name: name, ParentID: parentID}
+	if err := s.repo.Create(collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// Path returns the path of the collection with the given ID.
+func (s *CollectionService) Path(id int64) (string, error) {
+	var names []string
+	for next := &id; next != nil; {
+		collection, err := s.repo.GetByID(*next)
+		if err != nil {
+			return "", err
+		}
+		if collection == nil {
+			return "", fmt.Errorf("collection not found")
+		}
+		names = append([]string{collection.Name}, names...)
+		next = collection.ParentID
+	}
+	return strings.Join(names, CollectionPathSeparator), nil
+}
+
+func (s *CollectionService) Rename(path, name string) error {
+	collection, err := s.Lookup(path)
+	if err != nil {
+		return err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || strings.Contains(name, CollectionPathSeparator) {
+		return fmt.Errorf("invalid collection name %q", name)
+	}
+	return s.repo.Rename(collection.ID, name)
+}
+
+// Move moves the collection at path into the collection at parentPath, or to
+// the top level if parentPath is empty.
+func (s *CollectionService) Move(path, parentPath string) error {
+	collection, err := s.Lookup(path)
+	if err != nil {
+		return err
+	}
+	var parentID *int64
+	if len(splitCollectionPath(parentPath)) > 0 {
+		parent, err := s.Lookup(parentPath)
+		if err != nil {
+			return err
+		}
+		parentID = &parent.ID
+	}
+	return s.repo.Move(collection.ID, parentID)
+}
+
+// Delete deletes the collection at path and its subcollections. Their
+// bookmarks are kept.
+func (s *CollectionService) Delete(path string) error {
+	collection, err := s.Lookup(path)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(collection.ID)
+}
+
+// AddBookmark puts a bookmark at the end of a collection, taking it out of
+// any other.
+func (s *CollectionService) AddBookmark(collectionID, bookmarkID int64) error {
+	bookmark, err := s.bookmarks.Get(bookmarkID)
+	if err != nil {
+		return err
+	}
+	if bookmark == nil {
+		return fmt.Errorf("bookmark not found.")
+	}
+	return s.repo.AddBookmark(collectionID, bookmarkID)
+}
+
+func (s *CollectionService) RemoveBookmark(bookmarkID int64) error {
+	return s.repo.RemoveBookmark(bookmarkID)
+}
+
+// MoveBookmark moves a bookmark to index position within its collection.
+func (s *CollectionService) MoveBookmark(bookmarkID int64, position int) error {
+	return s.repo.MoveBookmark(bookmarkID, position)
+}
+
+// Entry returns where a bookmark is placed, or nil if it is in no
+// collection.
+func (s *CollectionService) Entry(bookmarkID int64) (*model.CollectionEntry, error) {
+	return s.repo.GetEntry(bookmarkID)
+}
+
+// ImportStats counts what an import did.
+type ImportStats struct {
+	Bookmarks   int
+	Collections int
+	Skipped     int
+}
+
+// Import adds the bookmarks of a bookmark file, recreating its folders as
+// collections. Bookmarks already saved are moved into the imported folder
+// and gain the tags and annotations they have in the file.
+// Links other than http and https, such as bookmarklets, are skipped.
+func (s *CollectionService) Import(folder *bookmarkfile.Folder) (*ImportStats, error) {
+	stats := &ImportStats{}
+	if err := s.importFolder(folder, nil, stats); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
+
+func (s *CollectionService) importFolder(folder *bookmarkfile.Folder, collection *model.Collection, stats *ImportStats) error {
+	for _, b := range folder.Bookmarks {
+		u, err := url.Parse(b.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			stats.Skipped++
+			continue
+		}
+
+		bookmark, err := s.bookmarks.Add(b.URL, b.Tags)
+		if err != nil {
+			if bookmark == nil {
+				log.Warn().Err(err).Str("url", b.URL).Msg("Failed to import bookmark")
+				stats.Skipped++
+				continue
+			}
+			// Saved, but fetching could not be scheduled.
+			log.Warn().Err(err).Str("url", b.URL).Msg("Imported bookmark")
+		}
+		if err := s.mergeImportedTags(bookmark, b.Tags); err != nil {
+			return err
+		}
+		if err := s.keepImportedFields(bookmark, b); err != nil {
+			return err
+		}
+		if err := s.keepImportedAnnotations(bookmark, b.Annotations); err != nil {
+			return err
+		}
+		if collection != nil {
+			if err := s.repo.AddBookmark(collection.ID, bookmark.ID); err != nil {
+				return err
+			}
+		}
+		stats.Bookmarks++
+	}
+
+	for _, sub := range folder.Folders {
+		name := strings.TrimSpace(strings.ReplaceAll(sub.Name, CollectionPathSeparator, "-"))
+		if name == "" {
+			name = "Untitled"
+		}
+		var parentID *int64
+		if collection != nil {
+			parentID = &collection.ID
+		}
+		child, err := s.repo.Find(parentID, name)
+		if err != nil {
+			return err
+		}
+		if child == nil {
+			child = &model.Collection{Name: name, ParentID: parentID, CreatedAt: sub.AddedAt}
+			if err := s.repo.Create(child); err != nil {
+				return err
+			}
+			stats.Collections++
+		}
+		if err := s.importFolder(sub, child, stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeImportedTags adds the tags a bookmark had in the file to it, for
+// bookmarks that were saved before and kept their own tags.
+func (s *CollectionService) mergeImportedTags(bookmark *model.Bookmark, names []string) error {
+	resolved, err := s.bookmarks.repo.ResolveTags(names...)
+	if err != nil {
+		return err
+	}
+	var tags []string
+	for _, tagName := range resolved {
+		if tagName != "" && !bookmark.HasTag(tagName) && !containsString(tags, tagName) {
+			tags = append(tags, tagName)
+		}
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	if err := s.bookmarks.repo.AddTags(bookmark.ID, tags); err != nil {
+		return err
+	}
+	for _, tagName := range tags {
+		bookmark.AddTag(model.NewTag(tagName))
+	}
+	return s.bookmarks.reindex([]int64{bookmark.ID})
+}
+
+// keepImportedFields saves the title, description and date a bookmark had in
+// the file, unless the bookmark was saved before or already fetched, and its
+// notes unless it has notes of its own.
+func (s *CollectionService) keepImportedFields(bookmark *model.Bookmark, b *bookmarkfile.Bookmark) error {
+	fields := make(map[string]interface{})
+	if b.Notes != "" && bookmark.Notes == "" {
+		fields["notes"] = b.Notes
+		bookmark.Notes = b.Notes
+	}
+
+	if bookmark.IsPending() && bookmark.Title == bookmark.URL {
+		if b.Title != "" {
+			fields["title"] = b.Title
+			bookmark.Title = b.Title
+		}
+		if b.Description != "" {
+			fields["description"] = b.Description
+			bookmark.Description = b.Description
+		}
+		if !b.AddedAt.IsZero() && b.AddedAt.Before(bookmark.CreatedAt) {
+			fields["created_at"] = b.AddedAt
+			bookmark.CreatedAt = b.AddedAt
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	if err := s.bookmarks.repo.UpdateFields(bookmark.ID, fields); err != nil {
+		return err
+	}
+	return s.bookmarks.reindex([]int64{bookmark.ID})
+}
+
+// keepImportedAnnotations adds the annotations a bookmark had in the file,
+// leaving out those it already has, so that importing a file again does not
+// repeat them.
+func (s *CollectionService) keepImportedAnnotations(bookmark *model.Bookmark, annotations []bookmarkfile.Annotation) error {
+	var added bool
+	for _, a := range annotations {
+		if hasAnnotation(bookmark, a.Text) {
+			continue
+		}
+		annotation := model.NewAnnotation(bookmark.ID, a.Text)
+		if !a.CreatedAt.IsZero() {
+			annotation.CreatedAt = a.CreatedAt.UTC()
+			annotation.UpdatedAt = annotation.CreatedAt
+		}
+		if err := s.bookmarks.repo.AddAnnotation(annotation); err != nil {
+			return err
+		}
+		bookmark.Annotations = append(bookmark.Annotations, *annotation)
+		added = true
+	}
+	if !added {
+		return nil
+	}
+	return s.bookmarks.reindex([]int64{bookmark.ID})
+}
+
+func hasAnnotation(bookmark *model.Bookmark, text string) bool {
+	for _, annotation := range bookmark.Annotations {
+		if annotation.Text == text {
+			return true
+		}
+	}
+	return false
+}
+
+// Export returns every bookmark arranged in its collection, for writing as
+// a bookmark file. Bookmarks in no collection are at the top level.
+func (s *CollectionService) Export() (*bookmarkfile.Folder, error) {
+	collections, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.Entries()
+	if err != nil {
+		return nil, err
+	}
+	bookmarks, err := s.bookmarks.Find(repository.ListOptions{Sort: repository.SortOldest, Limit: -1})
+	if err != nil {
+		return nil, err
+	}
+
+	root := &bookmarkfile.Folder{}
+	folders := make(map[int64]*bookmarkfile.Folder, len(collections))
+	for _, collection := range collections {
+		folders[collection.ID] = &bookmarkfile.Folder{
+			Name:    collection.Name,
+			AddedAt: collection.CreatedAt,
+		}
+	}
+	for _, collection := range collections {
+		folder := folders[collection.ID]
+		parent := root
+		if collection.ParentID != nil {
+			parent = folders[*collection.ParentID]
+		} else {
+			folder.Toolbar = bookmarkfile.IsToolbarName(collection.Name)
+		}
+		parent.Folders = append(parent.Folders, folder)
+	}
+
+	byID := make(map[int64]*model.Bookmark, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		byID[bookmark.ID] = bookmark
+	}
+	// Entries are ordered by position within each collection.
+	filed := make(map[int64]bool, len(entries))
+	for _, entry := range entries {
+		if bookmark := byID[entry.BookmarkID]; bookmark != nil {
+			folder := folders[entry.CollectionID]
+			folder.Bookmarks = append(folder.Bookmarks, exportBookmark(bookmark))
+			filed[bookmark.ID] = true
+		}
+	}
+	for _, bookmark := range bookmarks {
+		if !filed[bookmark.ID] {
+			root.Bookmarks = append(root.Bookmarks, exportBookmark(bookmark))
+		}
+	}
+	return root, nil
+}
+
+func exportBookmark(bookmark *model.Bookmark) *bookmarkfile.Bookmark {
+	tags := make([]string, len(bookmark.Tags))
+	for i, tag := range bookmark.Tags {
+		tags[i] = tag.Name
+	}
+	annotations := make([]bookmarkfile.Annotation, len(bookmark.Annotations))
+	for i, annotation := range bookmark.Annotations {
+		annotations[i] = bookmarkfile.Annotation{Text: annotation.Text, CreatedAt: annotation.CreatedAt}
+	}
+	return &bookmarkfile.Bookmark{
+		URL:         bookmark.URL,
+		Title:       bookmark.Title,
+		Description: bookmark.Description,
+		Notes:       bookmark.Notes,
+		Annotations: annotations,
+		Tags:        tags,
+		AddedAt:     bookmark.CreatedAt,
+	}
+}
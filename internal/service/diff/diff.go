@@ -0,0 +1,188 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified returns a unified diff of two texts compared line by line, with
+// context lines of surrounding context around each hunk. It returns an empty
+// string when the texts are equal.
+func Unified(from, to, fromName, toName string, context int) string {
+	if from == to {
+		return ""
+	}
+
+	ops := diffLines(splitLines(from), splitLines(to))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+
+	for _, h := range hunks(ops, context) {
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(h.fromStart, h.fromCount), hunkRange(h.toStart, h.toCount))
+		for _, o := range ops[h.start:h.end] {
+			switch o.kind {
+			case opEqual:
+				out.WriteString(" ")
+			case opDelete:
+				out.WriteString("-")
+			case opInsert:
+				out.WriteString("+")
+			}
+			out.WriteString(o.line)
+			out.WriteString("\n")
+		}
+	}
+	return out.String()
+}
+
+func splitLines(s string) []string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return lines
+}
+
+// diffLines computes an edit script using the longest common subsequence of
+// the two inputs after stripping their common prefix and suffix.
+func diffLines(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, op{opEqual, line})
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	lcs := make([][]int, len(ma)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(mb)+1)
+	}
+	for i := len(ma) - 1; i >= 0; i-- {
+		for j := len(mb) - 1; j >= 0; j-- {
+			if ma[i] == mb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(ma) && j < len(mb) {
+		switch {
+		case ma[i] == mb[j]:
+			ops = append(ops, op{opEqual, ma[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{opDelete, ma[i]})
+			i++
+		default:
+			ops = append(ops, op{opInsert, mb[j]})
+			j++
+		}
+	}
+	for ; i < len(ma); i++ {
+		ops = append(ops, op{opDelete, ma[i]})
+	}
+	for ; j < len(mb); j++ {
+		ops = append(ops, op{opInsert, mb[j]})
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, op{opEqual, line})
+	}
+	return ops
+}
+
+type hunk struct {
+	start, end           int
+	fromStart, fromCount int
+	toStart, toCount     int
+}
+
+// hunks groups changed operations, together with up to context equal lines on
+// either side, into hunks. Changes separated by at most 2*context equal lines
+// share a hunk.
+func hunks(ops []op, context int) []hunk {
+	var result []hunk
+
+	fromLine, toLine := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	for i, o := range ops {
+		fromLine[i+1], toLine[i+1] = fromLine[i], toLine[i]
+		if o.kind != opInsert {
+			fromLine[i+1]++
+		}
+		if o.kind != opDelete {
+			toLine[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			i++
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == opEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = run
+		}
+
+		result = append(result, hunk{
+			start:     start,
+			end:       end,
+			fromStart: fromLine[start],
+			fromCount: fromLine[end] - fromLine[start],
+			toStart:   toLine[start],
+			toCount:   toLine[end] - toLine[start],
+		})
+		i = end
+	}
+	return result
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,40 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/san-kum/bookmarker/internal/model"
+)
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`)
+
+// WriteHighlightsMarkdown writes the highlights of bookmarks as a Markdown
+// document with a section linking to each bookmark, in which every passage
+// is a blockquote followed by its comment.
+func WriteHighlightsMarkdown(w io.Writer, bookmarks []*model.Bookmark) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# Highlights")
+
+	for _, bookmark := range bookmarks {
+		title := bookmark.Title
+		if title == "" {
+			title = bookmark.URL
+		}
+		fmt.Fprintf(bw, "\n## [%s](<%s>)\n", markdownEscaper.Replace(title), bookmark.URL)
+
+		for _, highlight := range bookmark.Highlights {
+			fmt.Fprintln(bw)
+			for _, line := range strings.Split(highlight.Text, "\n") {
+				fmt.Fprintln(bw, strings.TrimRight("> "+line, " "))
+			}
+			if highlight.Comment != "" {
+				fmt.Fprintf(bw, "\n%s\n", highlight.Comment)
+			}
+		}
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,167 @@
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"time"
+)
+
+const warcVersion = "WARC/1.1"
+
+// WARCWriter writes records in the WARC 1.1 format. When compressed, each
+// record is written as its own gzip member, as replay tools expect of
+// .warc.gz files.
+type WARCWriter struct {
+	w        io.Writer
+	compress bool
+	infoID   string
+}
+
+func NewWARCWriter(w io.Writer, compress bool) *WARCWriter {
+	return &WARCWriter{
+		w:        w,
+		compress: compress,
+	}
+}
+
+// WriteInfo writes the warcinfo record describing the file. Records written
+// afterwards reference it.
+func (w *WARCWriter) WriteInfo(filename, software string) error {
+	var block bytes.Buffer
+	fmt.Fprintf(&block, "software: %s\r\n", software)
+	fmt.Fprintf(&block, "format: WARC File Format 1.1\r\n")
+	fmt.Fprintf(&block, "conformsTo: http://iipc.github.io/warc-specifications/specifications/warc-format/warc-1.1/\r\n")
+
+	id := newRecordID()
+	headers := map[string]string{
+		"WARC-Type":      "warcinfo",
+		"WARC-Record-ID": id,
+		"WARC-Date":      formatDate(time.Now()),
+		"Content-Type":   "application/warc-fields",
+	}
+	if filename != "" {
+		headers["WARC-Filename"] = filename
+	}
+
+	if err := w.writeRecord(headers, block.Bytes()); err != nil {
+		return err
+	}
+	w.infoID = id
+	return nil
+}
+
+// WriteExchange writes a response record and the request record that
+// produced it. request and response are HTTP messages in wire format.
+func (w *WARCWriter) WriteExchange(targetURI string, date time.Time, request, response []byte) error {
+	responseID := newRecordID()
+
+	headers := w.baseHeaders("response", responseID, targetURI, date)
+	headers["Content-Type"] = "application/http;msgtype=response"
+	if payload, ok := httpPayload(response); ok {
+		headers["WARC-Payload-Digest"] = digest(payload)
+	}
+	if err := w.writeRecord(headers, response); err != nil {
+		return err
+	}
+
+	headers = w.baseHeaders("request", newRecordID(), targetURI, date)
+	headers["Content-Type"] = "application/http;msgtype=request"
+	headers["WARC-Concurrent-To"] = responseID
+	return w.writeRecord(headers, request)
+}
+
+// WriteMetadata writes a metadata record about targetURI, such as the
+// user's notes on a page, with the given content type.
+func (w *WARCWriter) WriteMetadata(targetURI string, date time.Time, contentType string, block []byte) error {
+	headers := w.baseHeaders("metadata", newRecordID(), targetURI, date)
+	headers["Content-Type"] = contentType
+	return w.writeRecord(headers, block)
+}
+
+func (w *WARCWriter) baseHeaders(recordType, id, targetURI string, date time.Time) map[string]string {
+	headers := map[string]string{
+		"WARC-Type":       recordType,
+		"WARC-Record-ID":  id,
+		"WARC-Date":       formatDate(date),
+		"WARC-Target-URI": targetURI,
+	}
+	if w.infoID != "" {
+		headers["WARC-Warcinfo-ID"] = w.infoID
+	}
+	return headers
+}
+
+// headerOrder puts the mandatory fields first, in the order used by the
+// specification's examples.
+var headerOrder = []string{
+	"WARC-Type",
+	"WARC-Record-ID",
+	"WARC-Date",
+	"WARC-Target-URI",
+	"WARC-Filename",
+	"WARC-Warcinfo-ID",
+	"WARC-Concurrent-To",
+	"Content-Type",
+	"WARC-Block-Digest",
+	"WARC-Payload-Digest",
+}
+
+func (w *WARCWriter) writeRecord(headers map[string]string, block []byte) error {
+	headers["WARC-Block-Digest"] = digest(block)
+
+	var record bytes.Buffer
+	record.WriteString(warcVersion + "\r\n")
+	for _, name := range headerOrder {
+		if value, ok := headers[name]; ok {
+			fmt.Fprintf(&record, "%s: %s\r\n", name, value)
+		}
+	}
+	fmt.Fprintf(&record, "Content-Length: %d\r\n", len(block))
+	record.WriteString("\r\n")
+	record.Write(block)
+	record.WriteString("\r\n\r\n")
+
+	if !w.compress {
+		_, err := w.w.Write(record.Bytes())
+		return err
+	}
+
+	gz := gzip.NewWriter(w.w)
+	if _, err := gz.Write(record.Bytes()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// httpPayload returns the entity body of an HTTP message.
+func httpPayload(message []byte) ([]byte, bool) {
+	i := bytes.Index(message, []byte("\r\n\r\n"))
+	if i < 0 {
+		return nil, false
+	}
+	return message[i+4:], true
+}
+
+func digest(data []byte) string {
+	sum := sha1.Sum(data)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+func formatDate(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+func newRecordID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
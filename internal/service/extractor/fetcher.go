@@ -0,0 +1,194 @@
+package extractor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const maxBodySize = 20 << 20
+
+const userAgent = "bookmarker/1.0 (+https://github.com/san-kum/bookmarker)"
+
+// Validators are the cache validators from a previous fetch, sent back as
+// conditional request headers so unchanged pages are not downloaded again.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// Response is an HTTP response with its body read into memory, along with
+// the request that produced it.
+type Response struct {
+	URL        string
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+	FetchedAt  time.Time
+
+	Method        string
+	RequestHeader http.Header
+
+	// RedirectStatus is the status code of the first redirect followed, or 0
+	// when the response was served from the requested URL.
+	RedirectStatus int
+}
+
+func (r *Response) NotModified() bool {
+	return r.StatusCode == http.StatusNotModified
+}
+
+// WireRequest serializes the request that produced the response in HTTP/1.1
+// wire format.
+func (r *Response) WireRequest() []byte {
+	var buf bytes.Buffer
+
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		u = &url.URL{Path: "/"}
+	}
+	fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\n", r.Method, u.RequestURI())
+	fmt.Fprintf(&buf, "Host: %s\r\n", u.Host)
+	r.RequestHeader.Write(&buf)
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
+
+// WireResponse serializes the response in HTTP/1.1 wire format, whatever
+// protocol version it was received over. The body is
+// stored decoded, so Content-Encoding is dropped and Content-Length
+// describes the stored body.
+func (r *Response) WireResponse() []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "HTTP/1.1 %s\r\n", r.Status)
+
+	header := r.Header.Clone()
+	header.Del("Content-Encoding")
+	header.Del("Transfer-Encoding")
+	header.Set("Content-Length", strconv.Itoa(len(r.Body)))
+	header.Write(&buf)
+
+	buf.WriteString("\r\n")
+	buf.Write(r.Body)
+	return buf.Bytes()
+}
+
+func (r *Response) Validators() Validators {
+	return Validators{
+		ETag:         r.Header.Get("ETag"),
+		LastModified: r.Header.Get("Last-Modified"),
+	}
+}
+
+type Fetcher struct {
+	httpClient *http.Client
+}
+
+func NewFetcher() *Fetcher {
+	return &Fetcher{
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Get fetches url, following redirects. Non-2xx statuses are returned as a
+// Response rather than an error so callers can decide how to treat them.
+func (f *Fetcher) Get(url string, v Validators) (*Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+
+	return f.do(req, true)
+}
+
+// GetWithHeader fetches url with extra request headers, such as the Accept
+// and Authorization headers APIs expect.
+func (f *Fetcher) GetWithHeader(url string, header http.Header) (*Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	return f.do(req, true)
+}
+
+// Probe checks that url is reachable without downloading its body. It sends
+// a HEAD request and falls back to GET when the server rejects or mishandles
+// HEAD, which many do.
+func (f *Fetcher) Probe(url string) (*Response, error) {
+	req, err := http.NewRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.do(req, false)
+	if err == nil && resp.StatusCode < 400 {
+		return resp, nil
+	}
+
+	req, err = http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return f.do(req, false)
+}
+
+func (f *Fetcher) do(req *http.Request, readBody bool) (*Response, error) {
+	req.Header.Set("User-Agent", userAgent)
+
+	var redirectStatus int
+	client := *f.httpClient
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		if redirectStatus == 0 && req.Response != nil {
+			redirectStatus = req.Response.StatusCode
+		}
+		return nil
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body []byte
+	if readBody {
+		body, err = io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+	}
+
+	return &Response{
+		URL:            resp.Request.URL.String(),
+		StatusCode:     resp.StatusCode,
+		Status:         resp.Status,
+		Header:         resp.Header,
+		Body:           body,
+		FetchedAt:      time.Now().UTC(),
+		Method:         resp.Request.Method,
+		RequestHeader:  resp.Request.Header,
+		RedirectStatus: redirectStatus,
+	}, nil
+}
@@ -2,54 +2,129 @@ package extractor
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	neturl "net/url"
 	"strings"
 	"time"
 
+	"github.com/rs/zerolog/log"
 	"golang.org/x/net/html"
 )
 
 type HTMLExtractor struct {
-	httpClient *http.Client
+	fetcher *Fetcher
+	sites   *SiteRegistry
 }
 
-func NewHTMLExtractor() *HTMLExtractor {
+// Page is the result of fetching and extracting a URL. When the server
+// answers a conditional request with 304, NotModified is set and only the
+// validators are filled in.
+type Page struct {
+	URL         string
+	Title       string
+	Description string
+	Content     string
+	Author      string
+	ContentType string
+	PageCount   int
+	PublishedAt *time.Time
+	Tags        []string
+	IconURL     string
+	// CanonicalURL is the page's preferred URL, from rel="canonical" or the
+	// site's API.
+	CanonicalURL string
+	Validators   Validators
+	NotModified  bool
+}
+
+// NewHTMLExtractor returns an extractor that hands URLs of the sites in sites
+// to their site extractors. sites may be nil.
+func NewHTMLExtractor(fetcher *Fetcher, sites *SiteRegistry) *HTMLExtractor {
 	return &HTMLExtractor{
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		fetcher: fetcher,
+		sites:   sites,
 	}
 }
 
 func (e *HTMLExtractor) ExtractContent(url string) (title, description, content string, err error) {
-	resp, err := e.httpClient.Get(url)
-
+	page, err := e.Extract(url, Validators{})
 	if err != nil {
 		return "", "", "", err
 	}
-	defer resp.Body.Close()
+	return page.Title, page.Description, page.Content, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", "", "", fmt.Errorf("failed to fetch URL, status: %d", resp.StatusCode)
+// Extract fetches url, sending v as conditional request headers, and parses
+// the returned HTML. PDF documents are handed to the PDF extractor instead,
+// and pages of sites with a site extractor to that. When a site extractor
+// fails, the page is extracted as plain HTML.
+func (e *HTMLExtractor) Extract(url string, v Validators) (*Page, error) {
+	if page, ok := e.extractSite(url); ok {
+		return page, nil
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	resp, err := e.fetcher.Get(url, v)
 	if err != nil {
-		return "", "", "", err
+		return nil, err
+	}
+
+	if resp.NotModified() {
+		return &Page{URL: resp.URL, Validators: v, NotModified: true}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch URL, status: %d", resp.StatusCode)
 	}
 
-	doc, err := html.Parse(bytes.NewReader(body))
+	if isPDF(resp) {
+		page, err := extractPDF(resp.URL, resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		page.Validators = resp.Validators()
+		return page, nil
+	}
+
+	doc, err := html.Parse(bytes.NewReader(resp.Body))
 	if err != nil {
-		return "", "", "", err
+		return nil, err
 	}
 
-	title = e.extractTitle(doc)
-	description = e.extractMetaDescription(doc)
-	content = e.extractMainContent(doc)
-	return title, description, content, nil
+	return &Page{
+		URL:          resp.URL,
+		Title:        e.extractTitle(doc),
+		Description:  e.extractMetaDescription(doc),
+		Content:      e.extractMainContent(doc),
+		ContentType:  "text/html",
+		IconURL:      e.extractLink(doc, "icon", resp.URL),
+		CanonicalURL: e.extractLink(doc, "canonical", resp.URL),
+		Validators:   resp.Validators(),
+	}, nil
+}
 
+func (e *HTMLExtractor) extractSite(rawURL string) (*Page, bool) {
+	if e.sites == nil {
+		return nil, false
+	}
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return nil, false
+	}
+	site := e.sites.Lookup(u.Hostname())
+	if site == nil {
+		return nil, false
+	}
+
+	page, err := site.Extract(u)
+	if err != nil {
+		if !errors.Is(err, errNotSiteURL) {
+			log.Warn().Err(err).Str("url", rawURL).Msg("Site extractor failed, falling back to HTML extraction")
+		}
+		return nil, false
+	}
+	return page, true
 }
 
 func (e *HTMLExtractor) extractTitle(n *html.Node) string {
@@ -68,6 +143,42 @@ func (e *HTMLExtractor) extractTitle(n *html.Node) string {
 	return ""
 }
 
+// extractLink returns the absolute URL of the first link element with rel
+// among its link types, e.g. "icon" for rel="shortcut icon".
+func (e *HTMLExtractor) extractLink(n *html.Node, rel, pageURL string) string {
+	if n.Type == html.ElementNode && n.Data == "link" {
+		var matched bool
+		var href string
+		for _, a := range n.Attr {
+			switch a.Key {
+			case "rel":
+				for _, r := range strings.Fields(strings.ToLower(a.Val)) {
+					matched = matched || r == rel
+				}
+			case "href":
+				href = strings.TrimSpace(a.Val)
+			}
+		}
+		if matched && href != "" && !strings.HasPrefix(href, "data:") {
+			base, err := neturl.Parse(pageURL)
+			if err != nil {
+				return ""
+			}
+			ref, err := neturl.Parse(href)
+			if err != nil {
+				return ""
+			}
+			return base.ResolveReference(ref).String()
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if link := e.extractLink(c, rel, pageURL); link != "" {
+			return link
+		}
+	}
+	return ""
+}
+
 func (e *HTMLExtractor) extractMetaDescription(n *html.Node) string {
 	if n.Type == html.ElementNode && n.Data == "meta" {
 		var name, content string
@@ -126,19 +237,3 @@ func (e *HTMLExtractor) extractText(n *html.Node, content *strings.Builder) {
 		e.extractText(c, content)
 	}
 }
-
-func (e *HTMLExtractor) GenerateSummary(content string) string {
-	sentences := strings.Split(content, ".")
-
-	maxSentences := 3
-	if len(sentences) < maxSentences {
-		maxSentences = len(sentences)
-	}
-
-	summary := strings.Join(sentences[:maxSentences], ".")
-	if len(sentences) > 0 {
-		summary += "."
-	}
-
-	return strings.TrimSpace(summary)
-}
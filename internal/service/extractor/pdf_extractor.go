@@ -0,0 +1,131 @@
+package extractor
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"mime"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+const contentTypePDF = "application/pdf"
+
+// isPDF reports whether a response body is a PDF document, going by the
+// Content-Type header or, for servers that send a generic type, the magic
+// bytes at the start of the body.
+func isPDF(resp *Response) bool {
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil && mediaType == contentTypePDF {
+		return true
+	}
+	return bytes.HasPrefix(resp.Body, []byte("%PDF-"))
+}
+
+// extractPDF reads the document info dictionary and the text of every page
+// of a PDF.
+func extractPDF(url string, body []byte) (page *Page, err error) {
+	// The PDF parser panics on some malformed documents.
+	defer func() {
+		if r := recover(); r != nil {
+			page, err = nil, fmt.Errorf("failed to parse PDF: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PDF: %w", err)
+	}
+
+	info := reader.Trailer().Key("Info")
+	page = &Page{
+		URL:         url,
+		Title:       strings.TrimSpace(info.Key("Title").Text()),
+		Description: strings.TrimSpace(info.Key("Subject").Text()),
+		Author:      strings.TrimSpace(info.Key("Author").Text()),
+		ContentType: contentTypePDF,
+		PageCount:   reader.NumPage(),
+	}
+	if page.Title == "" {
+		page.Title = pdfFilename(url)
+	}
+
+	var content strings.Builder
+	for i := 1; i <= page.PageCount; i++ {
+		p := reader.Page(i)
+		if p.V.IsNull() {
+			continue
+		}
+		text := pdfPageText(p)
+		if text == "" {
+			continue
+		}
+		if content.Len() > 0 {
+			content.WriteString("\n\n")
+		}
+		content.WriteString(text)
+	}
+	page.Content = content.String()
+
+	return page, nil
+}
+
+// pdfPageText lays out the glyphs of a page in reading order: rows from top
+// to bottom, glyphs left to right, with a space wherever the gap between
+// two glyphs is wider than a fraction of the font size. A page that cannot be
+// laid out falls back to the raw text stream.
+func pdfPageText(p pdf.Page) (text string) {
+	defer func() {
+		if r := recover(); r != nil {
+			text, _ = p.GetPlainText(nil)
+			text = strings.TrimSpace(text)
+		}
+	}()
+
+	rows := make(map[int64][]pdf.Text)
+	for _, glyph := range p.Content().Text {
+		y := int64(math.Round(glyph.Y))
+		rows[y] = append(rows[y], glyph)
+	}
+	positions := make([]int64, 0, len(rows))
+	for y := range rows {
+		positions = append(positions, y)
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i] > positions[j] })
+
+	lines := make([]string, 0, len(positions))
+	for _, y := range positions {
+		glyphs := rows[y]
+		sort.SliceStable(glyphs, func(i, j int) bool { return glyphs[i].X < glyphs[j].X })
+
+		var line strings.Builder
+		end := math.Inf(-1)
+		for _, glyph := range glyphs {
+			if line.Len() > 0 && glyph.X-end > glyph.FontSize*0.2 {
+				line.WriteByte(' ')
+			}
+			line.WriteString(glyph.S)
+			end = glyph.X + glyph.W
+		}
+		if text := strings.Join(strings.Fields(line.String()), " "); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pdfFilename derives a title from the last path segment of url, for
+// documents without a Title entry.
+func pdfFilename(url string) string {
+	name := url
+	if i := strings.IndexAny(name, "?#"); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	if name == "" || name == "." || name == "/" {
+		return ""
+	}
+	return strings.NewReplacer("_", " ", "-", " ").Replace(name)
+}
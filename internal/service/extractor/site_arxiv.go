@@ -0,0 +1,97 @@
+package extractor
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// arxivVersion matches the version suffix of a paper ID, e.g. "v2".
+var arxivVersion = regexp.MustCompile(`v\d+$`)
+
+type arxivCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// arxivSite extracts papers through the arXiv API: the title, abstract,
+// authors, submission date and subject categories.
+type arxivSite struct {
+	fetcher *Fetcher
+	api     string
+}
+
+func (s *arxivSite) Extract(u *url.URL) (*Page, error) {
+	var id string
+	for _, prefix := range []string{"/abs/", "/pdf/"} {
+		if strings.HasPrefix(u.Path, prefix) {
+			id = strings.TrimSuffix(strings.TrimPrefix(u.Path, prefix), ".pdf")
+		}
+	}
+	if id == "" {
+		return nil, errNotSiteURL
+	}
+
+	apiURL := s.api + "/query?" + url.Values{"id_list": {id}}.Encode()
+	resp, err := s.fetcher.GetWithHeader(apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s, status: %d", apiURL, resp.StatusCode)
+	}
+
+	var feed struct {
+		Entries []struct {
+			ID        string `xml:"id"`
+			Title     string `xml:"title"`
+			Summary   string `xml:"summary"`
+			Published string `xml:"published"`
+			Authors   []struct {
+				Name string `xml:"name"`
+			} `xml:"author"`
+			PrimaryCategory arxivCategory   `xml:"http://arxiv.org/schemas/atom primary_category"`
+			Categories      []arxivCategory `xml:"category"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(resp.Body, &feed); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", apiURL, err)
+	}
+	// The API answers unknown IDs with an entry that has no title.
+	if len(feed.Entries) == 0 || feed.Entries[0].Title == "" {
+		return nil, fmt.Errorf("paper %s not found", id)
+	}
+	entry := feed.Entries[0]
+
+	authors := make([]string, 0, len(entry.Authors))
+	for _, author := range entry.Authors {
+		authors = append(authors, author.Name)
+	}
+	summary := strings.Join(strings.Fields(entry.Summary), " ")
+
+	absURL := "https://arxiv.org/abs/" + arxivVersion.ReplaceAllString(id, "")
+	page := &Page{
+		URL:          absURL,
+		CanonicalURL: absURL,
+		Title:        strings.Join(strings.Fields(entry.Title), " "),
+		Description:  summary,
+		Content:      summary,
+		Author:       strings.Join(authors, ", "),
+		ContentType:  "text/html",
+		PublishedAt:  parseDate(entry.Published),
+	}
+
+	// The primary category comes first; it is usually listed again among
+	// the others.
+	seen := make(map[string]bool)
+	for _, c := range append([]arxivCategory{entry.PrimaryCategory}, entry.Categories...) {
+		if c.Term != "" && !seen[c.Term] {
+			seen[c.Term] = true
+			page.Tags = append(page.Tags, c.Term)
+		}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,60 @@
+package extractor
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestArXivSite(t *testing.T) {
+	var requested []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/arxiv/query", func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Query().Get("id_list"))
+		serveFile(t, "arxiv_entry.xml")(w, r)
+	})
+	mux.HandleFunc("/", notFound(t))
+	registry := newTestRegistry(t, mux)
+
+	const absURL = "https://arxiv.org/abs/1706.03762"
+	for _, rawURL := range []string{
+		"https://arxiv.org/abs/1706.03762",
+		"https://arxiv.org/abs/1706.03762v7",
+		"https://export.arxiv.org/pdf/1706.03762v2.pdf",
+	} {
+		page, err := extract(t, registry, rawURL)
+		if err != nil {
+			t.Fatalf("%s: %v", rawURL, err)
+		}
+		// Every version of a paper is bookmarked as the paper itself.
+		if page.URL != absURL || page.CanonicalURL != absURL {
+			t.Errorf("%s: URL %q, canonical URL %q, want %q", rawURL, page.URL, page.CanonicalURL, absURL)
+		}
+		if page.Title != "Attention Is All You Need" {
+			t.Errorf("%s: title %q", rawURL, page.Title)
+		}
+		if page.Author != "Ashish Vaswani, Noam Shazeer" {
+			t.Errorf("%s: author %q", rawURL, page.Author)
+		}
+		if want := "The dominant sequence transduction models are based on complex recurrent or convolutional neural networks."; page.Description != want {
+			t.Errorf("%s: description %q, want %q", rawURL, page.Description, want)
+		}
+		// The primary category is listed once, first.
+		if !reflect.DeepEqual(page.Tags, []string{"cs.CL", "cs.LG"}) {
+			t.Errorf("%s: tags %q", rawURL, page.Tags)
+		}
+		if want := time.Date(2017, 6, 12, 17, 57, 34, 0, time.UTC); page.PublishedAt == nil || !page.PublishedAt.Equal(want) {
+			t.Errorf("%s: published %v, want %v", rawURL, page.PublishedAt, want)
+		}
+	}
+	// The API is asked for the version that was bookmarked.
+	if want := []string{"1706.03762", "1706.03762v7", "1706.03762v2"}; !reflect.DeepEqual(requested, want) {
+		t.Errorf("requested IDs %q, want %q", requested, want)
+	}
+
+	if _, err := extract(t, registry, "https://arxiv.org/list/cs.CL/recent"); !errors.Is(err, errNotSiteURL) {
+		t.Errorf("listing page: got error %v, want errNotSiteURL", err)
+	}
+}
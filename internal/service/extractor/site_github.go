@@ -0,0 +1,82 @@
+package extractor
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// githubReserved are top-level GitHub paths that are not user or
+// organization names.
+var githubReserved = map[string]bool{
+	"about": true, "apps": true, "collections": true, "enterprise": true,
+	"explore": true, "features": true, "join": true, "login": true,
+	"marketplace": true, "notifications": true, "orgs": true, "pricing": true,
+	"search": true, "settings": true, "sponsors": true, "topics": true,
+}
+
+// githubSite extracts repositories through the GitHub REST API: the
+// description, owner, creation date, language and topics, with the README as
+// content.
+type githubSite struct {
+	fetcher *Fetcher
+	api     string
+	token   string
+}
+
+func (s *githubSite) Extract(u *url.URL) (*Page, error) {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || githubReserved[parts[0]] {
+		return nil, errNotSiteURL
+	}
+	repoPath := url.PathEscape(parts[0]) + "/" + url.PathEscape(strings.TrimSuffix(parts[1], ".git"))
+
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	if s.token != "" {
+		header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	var repo struct {
+		FullName    string    `json:"full_name"`
+		Description string    `json:"description"`
+		Language    string    `json:"language"`
+		Topics      []string  `json:"topics"`
+		CreatedAt   time.Time `json:"created_at"`
+		Owner       struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	}
+	if err := getJSON(s.fetcher, s.api+"/repos/"+repoPath, header, &repo); err != nil {
+		return nil, err
+	}
+
+	page := &Page{
+		URL:         u.String(),
+		Title:       repo.FullName,
+		Description: repo.Description,
+		Author:      repo.Owner.Login,
+		ContentType: "text/html",
+	}
+	if repo.Description != "" {
+		page.Title += ": " + repo.Description
+	}
+	if !repo.CreatedAt.IsZero() {
+		created := repo.CreatedAt.UTC()
+		page.PublishedAt = &created
+	}
+	if repo.Language != "" {
+		page.Tags = append(page.Tags, strings.ToLower(repo.Language))
+	}
+	page.Tags = append(page.Tags, repo.Topics...)
+
+	// The README makes the repository searchable. A repository without one
+	// is still worth the metadata.
+	header.Set("Accept", "application/vnd.github.raw")
+	if resp, err := s.fetcher.GetWithHeader(s.api+"/repos/"+repoPath+"/readme", header); err == nil && resp.StatusCode == http.StatusOK {
+		page.Content = string(resp.Body)
+	}
+
+	return page, nil
+}
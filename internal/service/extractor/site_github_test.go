@@ -0,0 +1,73 @@
+package extractor
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGitHubSite(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/github/repos/golang/go", func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get("Accept"); accept != "application/vnd.github+json" {
+			t.Errorf("repository requested with Accept %q", accept)
+		}
+		serveFile(t, "github_repo.json")(w, r)
+	})
+	mux.HandleFunc("/github/repos/golang/go/readme", func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get("Accept"); accept != "application/vnd.github.raw" {
+			t.Errorf("README requested with Accept %q", accept)
+		}
+		serveFile(t, "github_readme.md")(w, r)
+	})
+	mux.HandleFunc("/", notFound(t))
+	registry := newTestRegistry(t, mux)
+
+	for _, rawURL := range []string{
+		"https://github.com/golang/go",
+		"https://www.github.com/golang/go.git",
+		"https://github.com/golang/go/tree/master/src",
+	} {
+		page, err := extract(t, registry, rawURL)
+		if err != nil {
+			t.Fatalf("%s: %v", rawURL, err)
+		}
+		if page.Title != "golang/go: The Go programming language" {
+			t.Errorf("%s: title %q", rawURL, page.Title)
+		}
+		if page.Author != "golang" {
+			t.Errorf("%s: author %q", rawURL, page.Author)
+		}
+		wantTags := []string{"go", "go", "golang", "language", "programming-language"}
+		if !reflect.DeepEqual(page.Tags, wantTags) {
+			t.Errorf("%s: tags %q, want %q", rawURL, page.Tags, wantTags)
+		}
+		if want := time.Date(2014, 8, 19, 4, 33, 40, 0, time.UTC); page.PublishedAt == nil || !page.PublishedAt.Equal(want) {
+			t.Errorf("%s: published %v, want %v", rawURL, page.PublishedAt, want)
+		}
+		if !strings.HasPrefix(page.Content, "# The Go Programming Language") {
+			t.Errorf("%s: content is not the README: %q", rawURL, page.Content)
+		}
+	}
+}
+
+func TestGitHubSiteReservedPaths(t *testing.T) {
+	registry := newTestRegistry(t, notFound(t))
+
+	for _, rawURL := range []string{
+		"https://github.com/",
+		"https://github.com/golang",
+		"https://github.com/topics/go",
+		"https://github.com/orgs/golang/repositories",
+		"https://github.com/settings/profile",
+		"https://github.com/marketplace/actions",
+		"https://github.com/search?q=bookmarks",
+	} {
+		if _, err := extract(t, registry, rawURL); !errors.Is(err, errNotSiteURL) {
+			t.Errorf("%s: got error %v, want errNotSiteURL", rawURL, err)
+		}
+	}
+}
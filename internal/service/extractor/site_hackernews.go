@@ -0,0 +1,94 @@
+package extractor
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxHNComments caps the comments collected into a discussion's content.
+const maxHNComments = 200
+
+type hnItem struct {
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	URL       string    `json:"url"`
+	Text      string    `json:"text"`
+	Points    int       `json:"points"`
+	CreatedAt time.Time `json:"created_at"`
+	Children  []*hnItem `json:"children"`
+}
+
+// hackerNewsSite extracts Hacker News discussions through the Algolia API,
+// with the post text and its comments as content.
+type hackerNewsSite struct {
+	fetcher *Fetcher
+	api     string
+}
+
+func (s *hackerNewsSite) Extract(u *url.URL) (*Page, error) {
+	id := u.Query().Get("id")
+	if u.Path != "/item" || id == "" {
+		return nil, errNotSiteURL
+	}
+
+	var item hnItem
+	if err := getJSON(s.fetcher, s.api+"/items/"+url.PathEscape(id), nil, &item); err != nil {
+		return nil, err
+	}
+
+	var comments []string
+	var collect func(items []*hnItem)
+	collect = func(items []*hnItem) {
+		for _, child := range items {
+			if len(comments) >= maxHNComments {
+				return
+			}
+			if text := htmlText(child.Text); text != "" {
+				comments = append(comments, fmt.Sprintf("%s: %s", child.Author, text))
+			}
+			collect(child.Children)
+		}
+	}
+	collect(item.Children)
+
+	page := &Page{
+		URL:         u.String(),
+		Title:       item.Title,
+		Description: fmt.Sprintf("%d points, %d comments", item.Points, countHNComments(item.Children)),
+		Author:      item.Author,
+		ContentType: "text/html",
+	}
+	if item.URL != "" {
+		page.Description += " on " + item.URL
+	}
+	if !item.CreatedAt.IsZero() {
+		created := item.CreatedAt.UTC()
+		page.PublishedAt = &created
+	}
+
+	var content []string
+	if text := htmlText(item.Text); text != "" {
+		content = append(content, text)
+	}
+	content = append(content, comments...)
+	page.Content = strings.Join(content, "\n\n")
+
+	switch {
+	case strings.HasPrefix(item.Title, "Ask HN"):
+		page.Tags = []string{"ask-hn"}
+	case strings.HasPrefix(item.Title, "Show HN"):
+		page.Tags = []string{"show-hn"}
+	}
+
+	return page, nil
+}
+
+func countHNComments(items []*hnItem) int {
+	n := len(items)
+	for _, item := range items {
+		n += countHNComments(item.Children)
+	}
+	return n
+}
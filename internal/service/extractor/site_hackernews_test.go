@@ -0,0 +1,86 @@
+package extractor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHackerNewsSite(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hackernews/items/1001", serveFile(t, "hackernews_item.json"))
+	mux.HandleFunc("/", notFound(t))
+	registry := newTestRegistry(t, mux)
+
+	page, err := extract(t, registry, "https://news.ycombinator.com/item?id=1001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Title != "Ask HN: How do you organize your bookmarks?" || page.Author != "alice" {
+		t.Errorf("title %q by %q", page.Title, page.Author)
+	}
+	// The deleted comment counts, as it does on the site, but has no text.
+	if want := "42 points, 4 comments"; page.Description != want {
+		t.Errorf("description %q, want %q", page.Description, want)
+	}
+	wantContent := strings.Join([]string{
+		"I have thousands of them.\nWhat works for you?",
+		"bob: Tags, and a lot of them.",
+		"carol: Tags stop working past a few hundred.",
+		"dave: I just search the full text.",
+	}, "\n\n")
+	if page.Content != wantContent {
+		t.Errorf("content\n got: %q\nwant: %q", page.Content, wantContent)
+	}
+	if !reflect.DeepEqual(page.Tags, []string{"ask-hn"}) {
+		t.Errorf("tags %q", page.Tags)
+	}
+
+	if _, err := extract(t, registry, "https://news.ycombinator.com/news"); !errors.Is(err, errNotSiteURL) {
+		t.Errorf("front page: got error %v, want errNotSiteURL", err)
+	}
+}
+
+func TestHackerNewsCommentCap(t *testing.T) {
+	// Every top-level comment has a reply, so there are twice as many
+	// comments as threads.
+	threads := maxHNComments
+	item := hnItem{Title: "Show HN: A bookmark manager", Author: "alice", Points: 7, URL: "https://example.com"}
+	for i := 0; i < threads; i++ {
+		item.Children = append(item.Children, &hnItem{
+			Author:   "bob",
+			Text:     fmt.Sprintf("comment %d", i),
+			Children: []*hnItem{{Author: "carol", Text: fmt.Sprintf("reply %d", i)}},
+		})
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hackernews/items/2001", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(item)
+	})
+	mux.HandleFunc("/", notFound(t))
+	registry := newTestRegistry(t, mux)
+
+	page, err := extract(t, registry, "https://news.ycombinator.com/item?id=2001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("7 points, %d comments on https://example.com", 2*threads); page.Description != want {
+		t.Errorf("description %q, want %q", page.Description, want)
+	}
+	comments := strings.Split(page.Content, "\n\n")
+	if len(comments) != maxHNComments {
+		t.Errorf("content has %d comments, want %d", len(comments), maxHNComments)
+	}
+	// Replies follow their comment, so the cap is reached halfway through.
+	if last := comments[len(comments)-1]; last != fmt.Sprintf("carol: reply %d", maxHNComments/2-1) {
+		t.Errorf("last comment %q", last)
+	}
+	if !reflect.DeepEqual(page.Tags, []string{"show-hn"}) {
+		t.Errorf("tags %q", page.Tags)
+	}
+}
@@ -0,0 +1,97 @@
+package extractor
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxSEAnswers caps the answers collected into a question's content.
+const maxSEAnswers = 3
+
+type seOwner struct {
+	DisplayName string `json:"display_name"`
+}
+
+// stackExchangeSite extracts questions from Stack Overflow and the other
+// Stack Exchange sites through the Stack Exchange API, with the question and
+// its top answers as content.
+type stackExchangeSite struct {
+	fetcher *Fetcher
+	api     string
+}
+
+func (s *stackExchangeSite) Extract(u *url.URL) (*Page, error) {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || (parts[0] != "questions" && parts[0] != "q") {
+		return nil, errNotSiteURL
+	}
+	id := parts[1]
+	site := stackExchangeSiteName(u.Hostname())
+
+	var questions struct {
+		Items []struct {
+			Title        string   `json:"title"`
+			Body         string   `json:"body"`
+			Tags         []string `json:"tags"`
+			Score        int      `json:"score"`
+			AnswerCount  int      `json:"answer_count"`
+			CreationDate int64    `json:"creation_date"`
+			Owner        seOwner  `json:"owner"`
+		} `json:"items"`
+	}
+	query := url.Values{"site": {site}, "filter": {"withbody"}}
+	if err := getJSON(s.fetcher, s.api+"/questions/"+url.PathEscape(id)+"?"+query.Encode(), nil, &questions); err != nil {
+		return nil, err
+	}
+	if len(questions.Items) == 0 {
+		return nil, fmt.Errorf("question %s not found on %s", id, site)
+	}
+	question := questions.Items[0]
+
+	page := &Page{
+		URL:         u.String(),
+		Title:       html.UnescapeString(question.Title),
+		Description: fmt.Sprintf("%d votes, %d answers", question.Score, question.AnswerCount),
+		Author:      html.UnescapeString(question.Owner.DisplayName),
+		ContentType: "text/html",
+		Tags:        question.Tags,
+	}
+	if question.CreationDate > 0 {
+		created := time.Unix(question.CreationDate, 0).UTC()
+		page.PublishedAt = &created
+	}
+
+	content := []string{htmlText(question.Body)}
+
+	// Answers are a bonus; the question stands on its own if they fail.
+	var answers struct {
+		Items []struct {
+			Body  string  `json:"body"`
+			Owner seOwner `json:"owner"`
+		} `json:"items"`
+	}
+	query.Set("sort", "votes")
+	query.Set("order", "desc")
+	query.Set("pagesize", fmt.Sprint(maxSEAnswers))
+	if err := getJSON(s.fetcher, s.api+"/questions/"+url.PathEscape(id)+"/answers?"+query.Encode(), nil, &answers); err == nil {
+		for _, answer := range answers.Items {
+			content = append(content, html.UnescapeString(answer.Owner.DisplayName)+": "+htmlText(answer.Body))
+		}
+	}
+	page.Content = strings.Join(content, "\n\n")
+
+	return page, nil
+}
+
+// stackExchangeSiteName returns the API site parameter for host, e.g.
+// "stackoverflow" or "unix" for unix.stackexchange.com.
+func stackExchangeSiteName(host string) string {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	if sub, ok := strings.CutSuffix(host, ".stackexchange.com"); ok {
+		return sub
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(host, ".com"), ".net")
+}
@@ -0,0 +1,89 @@
+package extractor
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStackExchangeSiteName(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"stackoverflow.com", "stackoverflow"},
+		{"www.stackoverflow.com", "stackoverflow"},
+		{"serverfault.com", "serverfault"},
+		{"superuser.com", "superuser"},
+		{"askubuntu.com", "askubuntu"},
+		{"mathoverflow.net", "mathoverflow"},
+		{"unix.stackexchange.com", "unix"},
+		{"Unix.StackExchange.com", "unix"},
+		{"math.stackexchange.com", "math"},
+	}
+	for _, tt := range tests {
+		if got := stackExchangeSiteName(tt.host); got != tt.want {
+			t.Errorf("stackExchangeSiteName(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestStackExchangeSite(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stackexchange/questions/12345", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("site") != "unix" || query.Get("filter") != "withbody" {
+			t.Errorf("question requested with %s", r.URL.RawQuery)
+		}
+		serveFile(t, "stackexchange_question.json")(w, r)
+	})
+	mux.HandleFunc("/stackexchange/questions/12345/answers", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("site") != "unix" || query.Get("sort") != "votes" || query.Get("pagesize") != "3" {
+			t.Errorf("answers requested with %s", r.URL.RawQuery)
+		}
+		serveFile(t, "stackexchange_answers.json")(w, r)
+	})
+	mux.HandleFunc("/", notFound(t))
+	registry := newTestRegistry(t, mux)
+
+	for _, rawURL := range []string{
+		"https://unix.stackexchange.com/questions/12345/find-files-modified-in-the-last-hour",
+		"https://unix.stackexchange.com/q/12345",
+	} {
+		page, err := extract(t, registry, rawURL)
+		if err != nil {
+			t.Fatalf("%s: %v", rawURL, err)
+		}
+		if want := `How do I find files modified in the last hour with "find"?`; page.Title != want {
+			t.Errorf("%s: title %q, want %q", rawURL, page.Title, want)
+		}
+		if page.Author != "René" {
+			t.Errorf("%s: author %q", rawURL, page.Author)
+		}
+		if want := "17 votes, 2 answers"; page.Description != want {
+			t.Errorf("%s: description %q, want %q", rawURL, page.Description, want)
+		}
+		wantContent := strings.Join([]string{
+			"I want to list recently changed files.\nfind . -newer x",
+			"Stéphane: Use find . -mmin -60.",
+			"O'Brien: GNU find also has -newermt.",
+		}, "\n\n")
+		if page.Content != wantContent {
+			t.Errorf("%s: content\n got: %q\nwant: %q", rawURL, page.Content, wantContent)
+		}
+		if !reflect.DeepEqual(page.Tags, []string{"find", "files"}) {
+			t.Errorf("%s: tags %q", rawURL, page.Tags)
+		}
+		if want := time.Unix(1700000000, 0); page.PublishedAt == nil || !page.PublishedAt.Equal(want) {
+			t.Errorf("%s: published %v, want %v", rawURL, page.PublishedAt, want)
+		}
+	}
+
+	if _, err := extract(t, registry, "https://unix.stackexchange.com/tags"); !errors.Is(err, errNotSiteURL) {
+		t.Errorf("tags page: got error %v, want errNotSiteURL", err)
+	}
+}
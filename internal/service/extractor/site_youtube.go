@@ -0,0 +1,103 @@
+package extractor
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// youtubeSite extracts videos: the title and channel come from YouTube's
+// oEmbed endpoint, the description and upload date from the watch page.
+type youtubeSite struct {
+	fetcher *Fetcher
+	oembed  string
+}
+
+func (s *youtubeSite) Extract(u *url.URL) (*Page, error) {
+	id := youtubeVideoID(u)
+	if id == "" {
+		return nil, errNotSiteURL
+	}
+	watchURL := "https://www.youtube.com/watch?v=" + url.QueryEscape(id)
+
+	var video struct {
+		Title      string `json:"title"`
+		AuthorName string `json:"author_name"`
+	}
+	query := url.Values{"url": {watchURL}, "format": {"json"}}
+	if err := getJSON(s.fetcher, s.oembed+"?"+query.Encode(), nil, &video); err != nil {
+		return nil, err
+	}
+
+	page := &Page{
+		URL:          watchURL,
+		CanonicalURL: watchURL,
+		Title:        video.Title,
+		Author:       video.AuthorName,
+		ContentType:  "text/html",
+	}
+
+	// The watch page is large and its markup changes often, so anything
+	// missing from it is simply left out.
+	if resp, err := s.fetcher.Get(watchURL, Validators{}); err == nil && resp.StatusCode == http.StatusOK {
+		if doc, err := html.Parse(bytes.NewReader(resp.Body)); err == nil {
+			page.Description = metaContent(doc, "name", "description")
+			if page.Description == "" {
+				page.Description = metaContent(doc, "property", "og:description")
+			}
+			page.Content = page.Description
+			if date := metaContent(doc, "itemprop", "datePublished"); date != "" {
+				page.PublishedAt = parseDate(date)
+			} else if date := metaContent(doc, "itemprop", "uploadDate"); date != "" {
+				page.PublishedAt = parseDate(date)
+			}
+		}
+	}
+
+	return page, nil
+}
+
+// youtubeVideoID returns the video ID of a watch, short or youtu.be link.
+func youtubeVideoID(u *url.URL) string {
+	if strings.EqualFold(u.Hostname(), "youtu.be") {
+		return strings.Trim(u.Path, "/")
+	}
+	if u.Path == "/watch" {
+		return u.Query().Get("v")
+	}
+	for _, prefix := range []string{"/shorts/", "/live/", "/embed/"} {
+		if strings.HasPrefix(u.Path, prefix) {
+			return strings.Trim(strings.TrimPrefix(u.Path, prefix), "/")
+		}
+	}
+	return ""
+}
+
+// metaContent returns the content of the first meta element whose key
+// attribute equals value.
+func metaContent(n *html.Node, key, value string) string {
+	if n.Type == html.ElementNode && n.Data == "meta" {
+		var matched bool
+		var content string
+		for _, a := range n.Attr {
+			switch {
+			case a.Key == key && a.Val == value:
+				matched = true
+			case a.Key == "content":
+				content = a.Val
+			}
+		}
+		if matched {
+			return content
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if content := metaContent(c, key, value); content != "" {
+			return content
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package extractor
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestYouTubeVideoID(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.youtube.com/watch?v=oV9rvDllKEg", "oV9rvDllKEg"},
+		{"https://m.youtube.com/watch?v=oV9rvDllKEg&t=42s", "oV9rvDllKEg"},
+		{"https://youtu.be/oV9rvDllKEg", "oV9rvDllKEg"},
+		{"https://YOUTU.BE/oV9rvDllKEg?si=abc", "oV9rvDllKEg"},
+		{"https://www.youtube.com/shorts/oV9rvDllKEg", "oV9rvDllKEg"},
+		{"https://www.youtube.com/shorts/oV9rvDllKEg/", "oV9rvDllKEg"},
+		{"https://www.youtube.com/live/oV9rvDllKEg?feature=share", "oV9rvDllKEg"},
+		{"https://www.youtube.com/embed/oV9rvDllKEg", "oV9rvDllKEg"},
+		{"https://www.youtube.com/watch", ""},
+		{"https://www.youtube.com/", ""},
+		{"https://www.youtube.com/@golang/videos", ""},
+		{"https://www.youtube.com/feed/subscriptions", ""},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := youtubeVideoID(u); got != tt.want {
+			t.Errorf("youtubeVideoID(%s) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestYouTubeSite(t *testing.T) {
+	const watchURL = "https://www.youtube.com/watch?v=oV9rvDllKEg"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/youtube/oembed", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("url"); got != watchURL {
+			t.Errorf("oEmbed requested for %q, want %q", got, watchURL)
+		}
+		serveFile(t, "youtube_oembed.json")(w, r)
+	})
+	mux.HandleFunc("/www.youtube.com/watch", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("v"); got != "oV9rvDllKEg" {
+			t.Errorf("watch page requested for %q", got)
+		}
+		serveFile(t, "youtube_watch.html")(w, r)
+	})
+	mux.HandleFunc("/", notFound(t))
+	registry := newTestRegistry(t, mux)
+
+	for _, rawURL := range []string{
+		"https://youtu.be/oV9rvDllKEg",
+		"https://www.youtube.com/shorts/oV9rvDllKEg",
+		"https://m.youtube.com/watch?v=oV9rvDllKEg",
+	} {
+		page, err := extract(t, registry, rawURL)
+		if err != nil {
+			t.Fatalf("%s: %v", rawURL, err)
+		}
+		if page.URL != watchURL || page.CanonicalURL != watchURL {
+			t.Errorf("%s: URL %q, canonical URL %q, want %q", rawURL, page.URL, page.CanonicalURL, watchURL)
+		}
+		if page.Title != "Concurrency is not Parallelism" || page.Author != "gotreehouse" {
+			t.Errorf("%s: title %q by %q", rawURL, page.Title, page.Author)
+		}
+		if want := "Rob Pike on the difference between concurrency and parallelism."; page.Description != want || page.Content != want {
+			t.Errorf("%s: description %q, content %q, want %q", rawURL, page.Description, page.Content, want)
+		}
+		if want := time.Date(2013, 1, 11, 16, 0, 0, 0, time.UTC); page.PublishedAt == nil || !page.PublishedAt.Equal(want) {
+			t.Errorf("%s: published %v, want %v", rawURL, page.PublishedAt, want)
+		}
+	}
+
+	if _, err := extract(t, registry, "https://www.youtube.com/feed/subscriptions"); !errors.Is(err, errNotSiteURL) {
+		t.Errorf("feed page: got error %v, want errNotSiteURL", err)
+	}
+}
@@ -0,0 +1,162 @@
+package extractor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// errNotSiteURL is returned by a site extractor for URLs on its host that it
+// does not handle, such as a site's home page. They go through the generic
+// HTML extractor instead.
+var errNotSiteURL = errors.New("not a supported page of this site")
+
+// SiteExtractor extracts pages of a particular site, usually through the
+// site's API, which gives far better results than scraping its HTML.
+type SiteExtractor interface {
+	Extract(u *url.URL) (*Page, error)
+}
+
+// SiteConfig holds the API base URLs used by the site extractors, so they can
+// be pointed at mirrors or proxies, and optional credentials.
+type SiteConfig struct {
+	GitHubAPI        string `json:"github_api"`
+	GitHubToken      string `json:"github_token"`
+	YouTubeOEmbed    string `json:"youtube_oembed"`
+	HackerNewsAPI    string `json:"hackernews_api"`
+	StackExchangeAPI string `json:"stackexchange_api"`
+	ArXivAPI         string `json:"arxiv_api"`
+}
+
+func DefaultSiteConfig() SiteConfig {
+	return SiteConfig{
+		GitHubAPI:        "https://api.github.com",
+		YouTubeOEmbed:    "https://www.youtube.com/oembed",
+		HackerNewsAPI:    "https://hn.algolia.com/api/v1",
+		StackExchangeAPI: "https://api.stackexchange.com/2.3",
+		ArXivAPI:         "https://export.arxiv.org/api",
+	}
+}
+
+// SiteRegistry maps hosts to site extractors. A host registered as
+// "*.example.com" covers every subdomain of example.com.
+type SiteRegistry struct {
+	sites map[string]SiteExtractor
+}
+
+func NewSiteRegistry() *SiteRegistry {
+	return &SiteRegistry{
+		sites: make(map[string]SiteExtractor),
+	}
+}
+
+// NewDefaultSiteRegistry returns a registry with the built-in site
+// extractors.
+func NewDefaultSiteRegistry(fetcher *Fetcher, config SiteConfig) *SiteRegistry {
+	r := NewSiteRegistry()
+	r.Register(&githubSite{fetcher: fetcher, api: config.GitHubAPI, token: config.GitHubToken},
+		"github.com", "www.github.com")
+	r.Register(&youtubeSite{fetcher: fetcher, oembed: config.YouTubeOEmbed},
+		"youtube.com", "www.youtube.com", "m.youtube.com", "youtu.be")
+	r.Register(&hackerNewsSite{fetcher: fetcher, api: config.HackerNewsAPI},
+		"news.ycombinator.com")
+	r.Register(&stackExchangeSite{fetcher: fetcher, api: config.StackExchangeAPI},
+		"stackoverflow.com", "serverfault.com", "superuser.com", "askubuntu.com",
+		"mathoverflow.net", "*.stackexchange.com")
+	r.Register(&arxivSite{fetcher: fetcher, api: config.ArXivAPI},
+		"arxiv.org", "www.arxiv.org", "export.arxiv.org")
+	return r
+}
+
+func (r *SiteRegistry) Register(site SiteExtractor, hosts ...string) {
+	for _, host := range hosts {
+		r.sites[strings.ToLower(host)] = site
+	}
+}
+
+// Lookup returns the extractor for host, or nil.
+func (r *SiteRegistry) Lookup(host string) SiteExtractor {
+	host = strings.ToLower(host)
+	if site, ok := r.sites[host]; ok {
+		return site
+	}
+	for i := strings.IndexByte(host, '.'); i >= 0; i = strings.IndexByte(host, '.') {
+		host = host[i+1:]
+		if site, ok := r.sites["*."+host]; ok {
+			return site
+		}
+	}
+	return nil
+}
+
+// getJSON fetches url and decodes its JSON body into dst.
+func getJSON(fetcher *Fetcher, url string, header http.Header, dst interface{}) error {
+	resp, err := fetcher.GetWithHeader(url, header)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch %s, status: %d", url, resp.StatusCode)
+	}
+	if err := json.Unmarshal(resp.Body, dst); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", url, err)
+	}
+	return nil
+}
+
+// htmlText returns the text of an HTML fragment, with block elements on
+// separate lines.
+func htmlText(fragment string) string {
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div})
+	if err != nil {
+		return fragment
+	}
+
+	var out strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			out.WriteString(n.Data)
+		case html.ElementNode:
+			switch n.Data {
+			case "p", "pre", "li", "br", "div", "blockquote", "h1", "h2", "h3", "h4", "h5", "h6":
+				out.WriteString("\n")
+				defer out.WriteString("\n")
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+
+	lines := strings.Split(out.String(), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// parseDate reads the date formats sites use in metadata.
+func parseDate(s string) *time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
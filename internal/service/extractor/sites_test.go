@@ -0,0 +1,124 @@
+package extractor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestRegistry returns the default site registry with every site API
+// pointed at a test server running handler. The APIs are served under
+// /github, /youtube/oembed, /hackernews, /stackexchange and /arxiv. Requests
+// to other hosts, such as YouTube watch pages, reach handler too, with the
+// host as the first path element.
+func newTestRegistry(t *testing.T, handler http.Handler) *SiteRegistry {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetcher := &Fetcher{httpClient: &http.Client{Transport: hostRewriter{target: target}}}
+	return NewDefaultSiteRegistry(fetcher, SiteConfig{
+		GitHubAPI:        server.URL + "/github",
+		YouTubeOEmbed:    server.URL + "/youtube/oembed",
+		HackerNewsAPI:    server.URL + "/hackernews",
+		StackExchangeAPI: server.URL + "/stackexchange",
+		ArXivAPI:         server.URL + "/arxiv",
+	})
+}
+
+// hostRewriter sends requests for any host to the target server, moving the
+// original host into the path.
+type hostRewriter struct {
+	target *url.URL
+}
+
+func (h hostRewriter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != h.target.Host {
+		req = req.Clone(req.Context())
+		req.URL.Path = "/" + req.URL.Host + req.URL.Path
+		req.URL.Scheme = h.target.Scheme
+		req.URL.Host = h.target.Host
+		req.Host = ""
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// serveFile answers with a file from testdata.
+func serveFile(t *testing.T, name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(data)
+	}
+}
+
+// notFound fails the test for any request it receives.
+func notFound(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL)
+		http.NotFound(w, r)
+	}
+}
+
+// extract runs the site extractor registered for rawURL's host.
+func extract(t *testing.T, registry *SiteRegistry, rawURL string) (*Page, error) {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	site := registry.Lookup(u.Hostname())
+	if site == nil {
+		t.Fatalf("no site extractor for %s", rawURL)
+	}
+	return site.Extract(u)
+}
+
+type stubSite struct {
+	name string
+}
+
+func (s *stubSite) Extract(u *url.URL) (*Page, error) {
+	return nil, errNotSiteURL
+}
+
+func TestSiteRegistryLookup(t *testing.T) {
+	stackOverflow := &stubSite{"stackoverflow"}
+	stackExchange := &stubSite{"stackexchange"}
+	registry := NewSiteRegistry()
+	registry.Register(stackOverflow, "stackoverflow.com")
+	registry.Register(stackExchange, "*.StackExchange.com")
+
+	tests := []struct {
+		host string
+		want SiteExtractor
+	}{
+		{"stackoverflow.com", stackOverflow},
+		{"StackOverflow.com", stackOverflow},
+		{"unix.stackexchange.com", stackExchange},
+		{"Unix.StackExchange.COM", stackExchange},
+		{"meta.unix.stackexchange.com", stackExchange},
+		// A wildcard covers subdomains only, and exact hosts no subdomains.
+		{"stackexchange.com", nil},
+		{"meta.stackoverflow.com", nil},
+		{"notstackexchange.com", nil},
+		{"stackexchange.com.example.org", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := registry.Lookup(tt.host); got != tt.want {
+			t.Errorf("Lookup(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
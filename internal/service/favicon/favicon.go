@@ -0,0 +1,234 @@
+package favicon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/san-kum/bookmarker/internal/service/archive"
+	"github.com/san-kum/bookmarker/internal/service/extractor"
+)
+
+// maxIconSize is the largest icon accepted. Real favicons are a few
+// kilobytes; anything much bigger is not an icon.
+const maxIconSize = 1 << 20
+
+var iconExtensions = map[string]string{
+	"image/png":                ".png",
+	"image/gif":                ".gif",
+	"image/jpeg":               ".jpg",
+	"image/webp":               ".webp",
+	"image/svg+xml":            ".svg",
+	"image/x-icon":             ".ico",
+	"image/vnd.microsoft.icon": ".ico",
+}
+
+// Cache fetches site icons and stores them by content hash, so the many
+// sites sharing a hosting provider's default icon store it once. Icons are
+// looked up once per host and process.
+type Cache struct {
+	fetcher *extractor.Fetcher
+	store   *archive.Store
+
+	mu     sync.Mutex
+	hosts  map[string]string
+	colors map[string]*color.RGBA
+}
+
+func NewCache(fetcher *extractor.Fetcher, store *archive.Store) *Cache {
+	return &Cache{
+		fetcher: fetcher,
+		store:   store,
+		hosts:   make(map[string]string),
+		colors:  make(map[string]*color.RGBA),
+	}
+}
+
+// Fetch returns the store path of the icon for the page at pageURL. It tries
+// iconURL, the icon the page links to, if any, and then /favicon.ico. A site
+// without a usable icon yields "" and no error.
+func (c *Cache) Fetch(pageURL, iconURL string) (string, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	host := strings.ToLower(u.Host)
+
+	c.mu.Lock()
+	path, ok := c.hosts[host]
+	c.mu.Unlock()
+	if ok {
+		return path, nil
+	}
+
+	candidates := []string{(&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/favicon.ico"}).String()}
+	if iconURL != "" && iconURL != candidates[0] {
+		candidates = append([]string{iconURL}, candidates...)
+	}
+
+	var lastErr error
+	for _, candidate := range candidates {
+		data, ext, err := c.download(candidate)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if _, path, err = c.store.Put(data, ext); err != nil {
+			return "", err
+		}
+		lastErr = nil
+		break
+	}
+
+	// Network errors are worth retrying later; a site that simply has no
+	// icon is not.
+	if lastErr != nil && path == "" {
+		if _, ok := lastErr.(noIconError); !ok {
+			return "", lastErr
+		}
+	}
+
+	c.mu.Lock()
+	c.hosts[host] = path
+	c.mu.Unlock()
+	return path, nil
+}
+
+// noIconError reports a URL that answered but did not serve an icon.
+type noIconError struct {
+	url, reason string
+}
+
+func (e noIconError) Error() string {
+	return fmt.Sprintf("no icon at %s: %s", e.url, e.reason)
+}
+
+func (c *Cache) download(iconURL string) ([]byte, string, error) {
+	resp, err := c.fetcher.Get(iconURL, extractor.Validators{})
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", noIconError{iconURL, resp.Status}
+	}
+	if len(resp.Body) == 0 || len(resp.Body) > maxIconSize {
+		return nil, "", noIconError{iconURL, fmt.Sprintf("%d bytes", len(resp.Body))}
+	}
+
+	// Servers often label icons wrongly, so trust the content over the
+	// header, except for SVG which cannot be sniffed.
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if sniffed := http.DetectContentType(resp.Body); strings.HasPrefix(sniffed, "image/") {
+		mediaType = sniffed
+	}
+	ext, ok := iconExtensions[mediaType]
+	if !ok {
+		return nil, "", noIconError{iconURL, "not an image: " + mediaType}
+	}
+	return resp.Body, ext, nil
+}
+
+// Abs returns the absolute filesystem path of an icon.
+func (c *Cache) Abs(path string) string {
+	return c.store.Abs(path)
+}
+
+// Color returns the dominant color of an icon, ignoring transparent,
+// near-white and near-black pixels. It reports false for formats it cannot
+// decode, such as SVG, and for icons that are only black and white.
+func (c *Cache) Color(path string) (color.RGBA, bool) {
+	if c == nil || path == "" {
+		return color.RGBA{}, false
+	}
+
+	c.mu.Lock()
+	cached, ok := c.colors[path]
+	c.mu.Unlock()
+	if !ok {
+		cached = c.decodeColor(path)
+		c.mu.Lock()
+		c.colors[path] = cached
+		c.mu.Unlock()
+	}
+	if cached == nil {
+		return color.RGBA{}, false
+	}
+	return *cached, true
+}
+
+func (c *Cache) decodeColor(path string) *color.RGBA {
+	data, err := c.store.Get(path)
+	if err != nil {
+		return nil
+	}
+	if strings.HasSuffix(path, ".ico") {
+		if data = icoPNG(data); data == nil {
+			return nil
+		}
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+
+	var r, g, b, n uint64
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			pixel := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if pixel.A < 128 {
+				continue
+			}
+			if max(pixel.R, pixel.G, pixel.B) < 40 || min(pixel.R, pixel.G, pixel.B) > 215 {
+				continue
+			}
+			r, g, b, n = r+uint64(pixel.R), g+uint64(pixel.G), b+uint64(pixel.B), n+1
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	return &color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: 255}
+}
+
+// icoPNG returns the largest PNG image in an ICO file. Older icons hold
+// bitmaps instead, which are not decoded.
+func icoPNG(data []byte) []byte {
+	if len(data) < 6 || binary.LittleEndian.Uint16(data[2:]) != 1 {
+		return nil
+	}
+	count := int(binary.LittleEndian.Uint16(data[4:]))
+
+	var best []byte
+	var bestSize int
+	for i := 0; i < count; i++ {
+		entry := 6 + 16*i
+		if entry+16 > len(data) {
+			break
+		}
+		size := int(data[entry])
+		if size == 0 {
+			size = 256
+		}
+		length := int(binary.LittleEndian.Uint32(data[entry+8:]))
+		offset := int(binary.LittleEndian.Uint32(data[entry+12:]))
+		if offset < 0 || length < 8 || offset+length > len(data) {
+			continue
+		}
+		image := data[offset : offset+length]
+		if bytes.HasPrefix(image, []byte("\x89PNG")) && size > bestSize {
+			best, bestSize = image, size
+		}
+	}
+	return best
+}
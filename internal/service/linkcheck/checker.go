@@ -0,0 +1,196 @@
+package linkcheck
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/san-kum/bookmarker/internal/repository"
+	"github.com/san-kum/bookmarker/internal/service/extractor"
+)
+
+const (
+	defaultWorkers = 8
+	defaultPerHost = 2
+)
+
+// Checker probes bookmark URLs and records their health. Requests run
+// concurrently but never more than perHost at a time against the same host.
+type Checker struct {
+	fetcher *extractor.Fetcher
+	links   *repository.LinkRepository
+	workers int
+	perHost int
+
+	hostsMu sync.Mutex
+	hosts   map[string]chan struct{}
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewChecker(fetcher *extractor.Fetcher, links *repository.LinkRepository) *Checker {
+	return &Checker{
+		fetcher: fetcher,
+		links:   links,
+		workers: defaultWorkers,
+		perHost: defaultPerHost,
+		hosts:   make(map[string]chan struct{}),
+	}
+}
+
+func (c *Checker) Status(bookmarkID int64) (*model.LinkStatus, error) {
+	return c.links.Get(bookmarkID)
+}
+
+// CheckDue checks every bookmark not checked within maxAge. A maxAge of zero
+// checks all bookmarks.
+func (c *Checker) CheckDue(maxAge time.Duration) ([]*model.LinkStatus, error) {
+	bookmarks, err := c.links.ListDue(time.Now().UTC().Add(-maxAge))
+	if err != nil {
+		return nil, err
+	}
+	return c.Check(bookmarks), nil
+}
+
+// Check probes the given bookmarks and stores the results. The returned
+// statuses are in the same order as bookmarks; entries are nil for bookmarks
+// skipped because the checker was stopped.
+func (c *Checker) Check(bookmarks []*model.Bookmark) []*model.LinkStatus {
+	statuses := make([]*model.LinkStatus, len(bookmarks))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				statuses[j] = c.checkOne(bookmarks[j])
+			}
+		}()
+	}
+
+feed:
+	for i := range bookmarks {
+		select {
+		case jobs <- i:
+		case <-c.stop:
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return statuses
+}
+
+func (c *Checker) checkOne(bookmark *model.Bookmark) *model.LinkStatus {
+	release := c.acquireHost(bookmark.URL)
+	resp, err := c.fetcher.Probe(bookmark.URL)
+	release()
+
+	status := &model.LinkStatus{
+		BookmarkID: bookmark.ID,
+		CheckedAt:  time.Now().UTC(),
+	}
+
+	failed := false
+	switch {
+	case err != nil:
+		status.Error = err.Error()
+		failed = true
+	case resp.StatusCode == http.StatusTooManyRequests:
+		// Rate limited: this says nothing about the link itself.
+		status.StatusCode = resp.StatusCode
+		status.FinalURL = resp.URL
+	default:
+		status.StatusCode = resp.StatusCode
+		status.FinalURL = resp.URL
+		failed = resp.StatusCode >= 400
+		status.Moved = !failed && resp.URL != bookmark.URL &&
+			(resp.RedirectStatus == http.StatusMovedPermanently || resp.RedirectStatus == http.StatusPermanentRedirect)
+	}
+
+	previous, err := c.links.Get(bookmark.ID)
+	if err != nil {
+		log.Warn().Err(err).Int64("id", bookmark.ID).Msg("Failed to load previous link status")
+	}
+	if failed {
+		status.ConsecutiveFailures = 1
+		if previous != nil {
+			status.ConsecutiveFailures = previous.ConsecutiveFailures + 1
+		}
+	} else if resp != nil && resp.StatusCode == http.StatusTooManyRequests && previous != nil {
+		status.ConsecutiveFailures = previous.ConsecutiveFailures
+	}
+
+	if err := c.links.Save(status); err != nil {
+		log.Error().Err(err).Int64("id", bookmark.ID).Msg("Failed to save link status")
+	}
+	return status
+}
+
+// acquireHost blocks until a request slot for the URL's host is free and
+// returns the function that releases it.
+func (c *Checker) acquireHost(rawURL string) func() {
+	host := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		host = u.Host
+	}
+
+	c.hostsMu.Lock()
+	sem, ok := c.hosts[host]
+	if !ok {
+		sem = make(chan struct{}, c.perHost)
+		c.hosts[host] = sem
+	}
+	c.hostsMu.Unlock()
+
+	sem <- struct{}{}
+	return func() { <-sem }
+}
+
+// Start checks due links every interval in the background until Stop is
+// called.
+func (c *Checker) Start(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			statuses, err := c.CheckDue(interval)
+			if err != nil {
+				log.Error().Err(err).Msg("Link check failed")
+			} else if len(statuses) > 0 {
+				log.Info().Int("count", len(statuses)).Msg("Checked bookmark links")
+			}
+
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the periodic checks, waiting for requests in flight to finish.
+func (c *Checker) Stop() {
+	if c.stop == nil {
+		return
+	}
+	close(c.stop)
+	<-c.done
+	c.stop = nil
+}
@@ -0,0 +1,189 @@
+package queue
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/san-kum/bookmarker/internal/repository"
+)
+
+const (
+	defaultMaxAttempts  = 5
+	defaultBaseBackoff  = 30 * time.Second
+	defaultMaxBackoff   = time.Hour
+	defaultPollInterval = 5 * time.Second
+)
+
+// Handler processes a single job. Returning an error schedules a retry with
+// exponential backoff until the attempts are exhausted.
+type Handler func(job *model.Job) error
+
+// Queue runs persisted jobs on a fixed pool of worker goroutines.
+type Queue struct {
+	jobs     *repository.JobRepository
+	workers  int
+	handlers map[string]Handler
+	onDone   []func(job *model.Job)
+
+	maxAttempts  int
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+	pollInterval time.Duration
+
+	wake chan struct{}
+	stop chan struct{}
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+}
+
+func NewQueue(jobs *repository.JobRepository, workers int) *Queue {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Queue{
+		jobs:         jobs,
+		workers:      workers,
+		handlers:     make(map[string]Handler),
+		maxAttempts:  defaultMaxAttempts,
+		baseBackoff:  defaultBaseBackoff,
+		maxBackoff:   defaultMaxBackoff,
+		pollInterval: defaultPollInterval,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Handle registers the handler for a job kind. It must be called before Start.
+func (q *Queue) Handle(kind string, handler Handler) {
+	q.handlers[kind] = handler
+}
+
+// OnDone registers a callback invoked after a job finishes, either
+// successfully or by exhausting its attempts. Callbacks run on the worker
+// goroutine.
+func (q *Queue) OnDone(fn func(job *model.Job)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onDone = append(q.onDone, fn)
+}
+
+func (q *Queue) Enqueue(kind string, bookmarkID int64) error {
+	if _, ok := q.handlers[kind]; !ok {
+		return fmt.Errorf("no handler registered for job kind %q", kind)
+	}
+	if err := q.jobs.Enqueue(model.NewJob(bookmarkID, kind)); err != nil {
+		return err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (q *Queue) Start() error {
+	if err := q.jobs.ResetRunning(); err != nil {
+		return err
+	}
+
+	q.stop = make(chan struct{})
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	log.Info().Int("workers", q.workers).Msg("Job queue started")
+	return nil
+}
+
+// Stop signals the workers to exit and waits for in-flight jobs to finish.
+func (q *Queue) Stop() {
+	if q.stop == nil {
+		return
+	}
+	close(q.stop)
+	q.wg.Wait()
+	q.stop = nil
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.stop:
+			return
+		default:
+		}
+
+		job, err := q.jobs.Claim(time.Now().UTC())
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to claim job")
+		}
+		if job != nil {
+			q.run(job)
+			continue
+		}
+
+		select {
+		case <-q.stop:
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q *Queue) run(job *model.Job) {
+	handler, ok := q.handlers[job.Kind]
+	if !ok {
+		q.finish(job, q.jobs.Fail(job, fmt.Sprintf("unknown job kind %q", job.Kind)))
+		return
+	}
+
+	job.Attempts++
+	err := handler(job)
+	if err == nil {
+		q.finish(job, q.jobs.Complete(job))
+		return
+	}
+
+	if job.Attempts >= q.maxAttempts {
+		log.Warn().Err(err).Int64("bookmark", job.BookmarkID).Str("kind", job.Kind).Msg("Job failed permanently")
+		q.finish(job, q.jobs.Fail(job, err.Error()))
+		return
+	}
+
+	delay := q.backoff(job.Attempts)
+	log.Warn().Err(err).Int64("bookmark", job.BookmarkID).Str("kind", job.Kind).Dur("retry_in", delay).Msg("Job failed, retrying")
+	if err := q.jobs.Retry(job, time.Now().UTC().Add(delay), err.Error()); err != nil {
+		log.Error().Err(err).Int64("job", job.ID).Msg("Failed to reschedule job")
+	}
+}
+
+func (q *Queue) finish(job *model.Job, err error) {
+	if err != nil {
+		log.Error().Err(err).Int64("job", job.ID).Msg("Failed to record job result")
+	}
+
+	q.mu.Lock()
+	callbacks := q.onDone
+	q.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(job)
+	}
+}
+
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.baseBackoff << (attempts - 1)
+	if delay <= 0 || delay > q.maxBackoff {
+		delay = q.maxBackoff
+	}
+	return delay
+}
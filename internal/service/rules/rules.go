@@ -0,0 +1,206 @@
+package rules
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/san-kum/bookmarker/internal/model"
+)
+
+// Rule tags bookmarks that match all of its conditions. Host and ContentType
+// are shell-style globs ("*.github.com", "image/*", "pdf"), where a host glob
+// starting with "*." also matches the bare domain and a content type without
+// a slash is matched against the subtype. URL, Title, Content and
+// Author are regular expressions, matched case-insensitively. Empty
+// conditions are ignored, but a rule needs at least one.
+type Rule struct {
+	Name        string   `json:"name"`
+	Host        string   `json:"host"`
+	URL         string   `json:"url"`
+	Title       string   `json:"title"`
+	Content     string   `json:"content"`
+	Author      string   `json:"author"`
+	ContentType string   `json:"content_type"`
+	MinPages    int      `json:"min_pages"`
+	MaxPages    int      `json:"max_pages"`
+	Tags        []string `json:"tags"`
+}
+
+// needsContent reports whether the rule looks at anything only known once the
+// page has been fetched.
+func (r *Rule) needsContent() bool {
+	return r.Title != "" || r.Content != "" || r.Author != "" || r.ContentType != "" || r.MinPages > 0 || r.MaxPages > 0
+}
+
+type compiledRule struct {
+	Rule
+	url, title, content, author *regexp.Regexp
+}
+
+// Engine evaluates a set of tagging rules against bookmarks.
+type Engine struct {
+	rules []*compiledRule
+}
+
+// NewEngine validates and compiles rules.
+func NewEngine(rules []Rule) (*Engine, error) {
+	e := &Engine{}
+	for i, rule := range rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		compiled, err := compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag rule %q: %w", rule.Name, err)
+		}
+		e.rules = append(e.rules, compiled)
+	}
+	return e, nil
+}
+
+func compile(rule Rule) (*compiledRule, error) {
+	if len(rule.Tags) == 0 {
+		return nil, fmt.Errorf("no tags to apply")
+	}
+	if rule.Host == "" && rule.URL == "" && !rule.needsContent() {
+		return nil, fmt.Errorf("no conditions")
+	}
+	for _, glob := range []string{rule.Host, rule.ContentType} {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("bad pattern %q: %w", glob, err)
+		}
+	}
+
+	// Tags are compared with the bookmark's, which are saved normalized.
+	tags := make([]string, len(rule.Tags))
+	for i, tag := range rule.Tags {
+		tags[i] = model.NormalizeTagName(tag)
+		if tags[i] == "" {
+			return nil, fmt.Errorf("invalid tag name %q", tag)
+		}
+	}
+	rule.Tags = tags
+
+	c := &compiledRule{Rule: rule}
+	for _, re := range []struct {
+		pattern string
+		dst     **regexp.Regexp
+	}{
+		{rule.URL, &c.url},
+		{rule.Title, &c.title},
+		{rule.Content, &c.content},
+		{rule.Author, &c.author},
+	} {
+		if re.pattern == "" {
+			continue
+		}
+		compiled, err := regexp.Compile("(?i)" + re.pattern)
+		if err != nil {
+			return nil, err
+		}
+		*re.dst = compiled
+	}
+	return c, nil
+}
+
+// Match is a rule that matched a bookmark.
+type Match struct {
+	Rule string
+	Tags []string
+}
+
+// Evaluate returns the rules matching bookmark. Rules that depend on page
+// content or metadata are skipped while the bookmark has not been fetched.
+func (e *Engine) Evaluate(bookmark *model.Bookmark) []Match {
+	if e == nil {
+		return nil
+	}
+
+	var matches []Match
+	for _, rule := range e.rules {
+		if rule.needsContent() && bookmark.IsPending() {
+			continue
+		}
+		if rule.matches(bookmark) {
+			matches = append(matches, Match{Rule: rule.Name, Tags: rule.Tags})
+		}
+	}
+	return matches
+}
+
+// Tags returns the tags the rules would add to bookmark, leaving out tags it
+// already has.
+func (e *Engine) Tags(bookmark *model.Bookmark) []string {
+	have := make(map[string]bool, len(bookmark.Tags))
+	for _, tag := range bookmark.Tags {
+		have[model.NormalizeTagName(tag.Name)] = true
+	}
+
+	var tags []string
+	for _, match := range e.Evaluate(bookmark) {
+		for _, tag := range match.Tags {
+			if !have[tag] {
+				have[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+func (r *compiledRule) matches(bookmark *model.Bookmark) bool {
+	if r.Host != "" {
+		u, err := url.Parse(bookmark.URL)
+		if err != nil {
+			return false
+		}
+		if !matchHost(strings.ToLower(r.Host), strings.ToLower(u.Hostname())) {
+			return false
+		}
+	}
+	if r.ContentType != "" {
+		mediaType, _, err := mime.ParseMediaType(bookmark.ContentType)
+		if err != nil {
+			return false
+		}
+		if !strings.Contains(r.ContentType, "/") {
+			// A bare subtype such as "pdf".
+			mediaType = mediaType[strings.IndexByte(mediaType, '/')+1:]
+		}
+		if ok, _ := path.Match(r.ContentType, mediaType); !ok {
+			return false
+		}
+	}
+	if r.MinPages > 0 && bookmark.PageCount < r.MinPages {
+		return false
+	}
+	if r.MaxPages > 0 && bookmark.PageCount > r.MaxPages {
+		return false
+	}
+
+	for _, cond := range []struct {
+		re    *regexp.Regexp
+		value string
+	}{
+		{r.url, bookmark.URL},
+		{r.title, bookmark.Title},
+		{r.content, bookmark.Content},
+		{r.author, bookmark.Author},
+	} {
+		if cond.re != nil && !cond.re.MatchString(cond.value) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchHost(pattern, host string) bool {
+	if ok, _ := path.Match(pattern, host); ok {
+		return true
+	}
+	return strings.HasPrefix(pattern, "*.") && host == pattern[2:]
+}
@@ -0,0 +1,175 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/san-kum/bookmarker/internal/repository"
+)
+
+// Query is a parsed search or filter expression: free text plus any of
+//
+//	tag:<name>        bookmarks with the tag or one of its descendants
+//	lang:<code>       bookmarks in a language, e.g. lang:de
+//	readtime:<range>  reading time in minutes: 5, <10, <=10, >5, >=5 or 5-10
+//	state:<states>    read states, comma separated, e.g. state:unread,reading
+//	is:starred        starred bookmarks
+//	is:pinned         pinned bookmarks
+//	rating:<range>    rating from 1 to 5, in the syntax of readtime
+//	sort:<order>      newest, oldest, title, readtime, words, priority or rating
+type Query struct {
+	Text        string
+	Tag         string
+	Lang        string
+	ReadingTime Range
+	ReadStates  []string
+	Starred     bool
+	Pinned      bool
+	Rating      Range
+	Sort        string
+}
+
+// Range is an inclusive range of whole numbers. A zero Min leaves it open
+// below and a nil Max open above.
+type Range struct {
+	Min int
+	Max *int
+}
+
+func (r Range) IsZero() bool {
+	return r.Min == 0 && r.Max == nil
+}
+
+// ParseQuery splits s into filter terms and free text.
+func ParseQuery(s string) (*Query, error) {
+	q := &Query{}
+	var text []string
+	for _, term := range strings.Fields(s) {
+		key, value, ok := strings.Cut(term, ":")
+		if !ok || value == "" {
+			text = append(text, term)
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "tag":
+			q.Tag = value
+		case "lang":
+			q.Lang = strings.ToLower(value)
+		case "readtime":
+			r, err := parseRange(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid readtime %q: %w", value, err)
+			}
+			q.ReadingTime = r
+		case "state":
+			q.ReadStates = nil
+			for _, state := range strings.Split(strings.ToLower(value), ",") {
+				if !model.IsReadState(state) {
+					return nil, fmt.Errorf("unknown read state %q", state)
+				}
+				q.ReadStates = append(q.ReadStates, state)
+			}
+		case "is":
+			switch strings.ToLower(value) {
+			case "starred":
+				q.Starred = true
+			case "pinned":
+				q.Pinned = true
+			default:
+				return nil, fmt.Errorf("unknown filter is:%s, expected is:starred or is:pinned", value)
+			}
+		case "rating":
+			r, err := parseRange(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rating %q: %w", value, err)
+			}
+			if r.Max != nil && *r.Max < 1 {
+				return nil, fmt.Errorf("invalid rating %q: ratings go from 1 to %d", value, model.MaxRating)
+			}
+			q.Rating = r
+		case "sort":
+			q.Sort = strings.ToLower(value)
+			switch q.Sort {
+			case repository.SortNewest, repository.SortOldest, repository.SortTitle,
+				repository.SortReadingTime, repository.SortWordCount, repository.SortPriority, repository.SortRating:
+			default:
+				return nil, fmt.Errorf("unknown sort order %q", value)
+			}
+		default:
+			text = append(text, term)
+		}
+	}
+	q.Text = strings.Join(text, " ")
+	return q, nil
+}
+
+func parseRange(s string) (Range, error) {
+	var r Range
+	number := func(s string) (int, error) {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%q is not a whole number", s)
+		}
+		return n, nil
+	}
+
+	var err error
+	var max int
+	bounded := true
+	switch {
+	case strings.HasPrefix(s, "<="):
+		max, err = number(s[2:])
+	case strings.HasPrefix(s, "<"):
+		max, err = number(s[1:])
+		max--
+	case strings.HasPrefix(s, ">="):
+		r.Min, err = number(s[2:])
+		bounded = false
+	case strings.HasPrefix(s, ">"):
+		r.Min, err = number(s[1:])
+		r.Min++
+		bounded = false
+	case strings.Contains(s, "-"):
+		from, to, _ := strings.Cut(s, "-")
+		if r.Min, err = number(from); err == nil {
+			max, err = number(to)
+		}
+	default:
+		r.Min, err = number(s)
+		max = r.Min
+	}
+	if err != nil {
+		return Range{}, err
+	}
+	if bounded {
+		if max < r.Min {
+			return Range{}, fmt.Errorf("empty range")
+		}
+		r.Max = &max
+	}
+	return r, nil
+}
+
+// ListOptions converts the filter terms of q for listing bookmarks. Free
+// text is taken as a tag name, as the list filter has always done.
+func (q *Query) ListOptions() repository.ListOptions {
+	opts := repository.ListOptions{
+		Tag:            q.Tag,
+		Lang:           q.Lang,
+		MinReadingTime: q.ReadingTime.Min,
+		MaxReadingTime: q.ReadingTime.Max,
+		ReadStates:     q.ReadStates,
+		Starred:        q.Starred,
+		Pinned:         q.Pinned,
+		MinRating:      q.Rating.Min,
+		MaxRating:      q.Rating.Max,
+		Sort:           q.Sort,
+	}
+	if opts.Tag == "" {
+		opts.Tag = q.Text
+	}
+	return opts
+}
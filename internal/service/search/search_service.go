@@ -2,14 +2,37 @@ package search
 
 import (
 	"fmt"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/analysis/analyzer/standard"
+	_ "github.com/blevesearch/bleve/analysis/lang/de"
+	_ "github.com/blevesearch/bleve/analysis/lang/en"
+	_ "github.com/blevesearch/bleve/analysis/lang/es"
+	_ "github.com/blevesearch/bleve/analysis/lang/fr"
+	_ "github.com/blevesearch/bleve/analysis/lang/it"
+	_ "github.com/blevesearch/bleve/analysis/lang/nl"
+	_ "github.com/blevesearch/bleve/analysis/lang/pt"
+	_ "github.com/blevesearch/bleve/analysis/lang/ru"
+	_ "github.com/blevesearch/bleve/analysis/lang/sv"
+	"github.com/blevesearch/bleve/mapping"
+	"github.com/blevesearch/bleve/search/query"
 	"github.com/rs/zerolog/log"
 	"github.com/san-kum/bookmarker/internal/model"
 	"github.com/san-kum/bookmarker/internal/repository"
+	"github.com/san-kum/bookmarker/internal/service/textstats"
 )
 
+// indexVersion identifies the index mapping. Indexes built with an older
+// mapping are rebuilt when opened.
+const indexVersion = "9"
+
+var indexVersionKey = []byte("mapping_version")
+
 type BookmarkIndex struct {
 	ID          string   `json:"id"`
 	URL         string   `json:"url"`
@@ -17,7 +40,127 @@ type BookmarkIndex struct {
 	Description string   `json:"description"`
 	Content     string   `json:"content"`
 	Summary     string   `json:"summary"`
+	Notes       string   `json:"notes"`
+	Annotations []string `json:"annotations"`
+	Highlights  []string `json:"highlights"`
+	Author      string   `json:"author"`
+	Lang        string   `json:"lang"`
+	WordCount   int      `json:"word_count"`
+	ReadingTime int      `json:"reading_time"`
+	ReadState   string   `json:"read_state"`
+	Priority    int      `json:"priority"`
+	Starred     bool     `json:"starred"`
+	Pinned      bool     `json:"pinned"`
+	Rating      int      `json:"rating"`
 	Tags        []string `json:"tags"`
+	// TagPaths holds every tag and its ancestors, so a tag filter also
+	// matches the tag's descendants.
+	TagPaths []string `json:"tag_paths"`
+	// SortTitle is the lowercased title, kept whole for sorting by title.
+	SortTitle string    `json:"sort_title"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BleveType selects the document mapping, and with it the text analyzer, for
+// the bookmark's language.
+func (b BookmarkIndex) BleveType() string {
+	if b.Lang == "" {
+		return "bookmark"
+	}
+	return "bookmark_" + b.Lang
+}
+
+func newBookmarkIndex(bookmark *model.Bookmark) BookmarkIndex {
+	tagNames := make([]string, len(bookmark.Tags))
+	var tagPaths []string
+	for i, tag := range bookmark.Tags {
+		tagNames[i] = tag.Name
+		tagPaths = append(tagPaths, model.TagPath(tag.Name)...)
+	}
+	annotations := make([]string, len(bookmark.Annotations))
+	for i, annotation := range bookmark.Annotations {
+		annotations[i] = annotation.Text
+	}
+	// Highlights are indexed as their passages and comments.
+	var highlights []string
+	for _, highlight := range bookmark.Highlights {
+		highlights = append(highlights, highlight.Text)
+		if highlight.Comment != "" {
+			highlights = append(highlights, highlight.Comment)
+		}
+	}
+	return BookmarkIndex{
+		ID:          fmt.Sprintf("%d", bookmark.ID),
+		URL:         bookmark.URL,
+		Title:       bookmark.Title,
+		SortTitle:   strings.ToLower(bookmark.Title),
+		Description: bookmark.Description,
+		Content:     bookmark.Content,
+		Summary:     bookmark.Summary,
+		Notes:       bookmark.Notes,
+		Annotations: annotations,
+		Highlights:  highlights,
+		Author:      bookmark.Author,
+		Lang:        bookmark.Lang,
+		WordCount:   bookmark.WordCount,
+		ReadingTime: bookmark.ReadingTime,
+		ReadState:   bookmark.ReadState,
+		Priority:    bookmark.Priority,
+		Starred:     bookmark.Starred,
+		Pinned:      bookmark.Pinned,
+		Rating:      bookmark.Rating,
+		Tags:        tagNames,
+		TagPaths:    tagPaths,
+		CreatedAt:   bookmark.CreatedAt,
+	}
+}
+
+// newIndexMapping maps bookmarks in each detected language to that
+// language's analyzer, so their text is stemmed and stopword-filtered
+// accordingly. Bookmarks of unknown language use the standard analyzer.
+func newIndexMapping() mapping.IndexMapping {
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping = bookmarkMapping(standard.Name)
+	for _, lang := range textstats.Languages {
+		indexMapping.AddDocumentMapping("bookmark_"+lang, bookmarkMapping(lang))
+	}
+	return indexMapping
+}
+
+func bookmarkMapping(analyzer string) *mapping.DocumentMapping {
+	doc := bleve.NewDocumentMapping()
+
+	text := bleve.NewTextFieldMapping()
+	text.Analyzer = analyzer
+	for _, field := range []string{"title", "description", "content", "summary", "notes", "annotations", "highlights"} {
+		doc.AddFieldMappingsAt(field, text)
+	}
+
+	exact := bleve.NewTextFieldMapping()
+	exact.Analyzer = keyword.Name
+	exact.IncludeInAll = false
+	doc.AddFieldMappingsAt("lang", exact)
+	doc.AddFieldMappingsAt("tag_paths", exact)
+	doc.AddFieldMappingsAt("read_state", exact)
+	doc.AddFieldMappingsAt("sort_title", exact)
+
+	number := bleve.NewNumericFieldMapping()
+	number.IncludeInAll = false
+	doc.AddFieldMappingsAt("word_count", number)
+	doc.AddFieldMappingsAt("reading_time", number)
+	doc.AddFieldMappingsAt("rating", number)
+	doc.AddFieldMappingsAt("priority", number)
+
+	flag := bleve.NewBooleanFieldMapping()
+	flag.IncludeInAll = false
+	doc.AddFieldMappingsAt("starred", flag)
+	doc.AddFieldMappingsAt("pinned", flag)
+
+	date := bleve.NewDateTimeFieldMapping()
+	date.IncludeInAll = false
+	doc.AddFieldMappingsAt("created_at", date)
+
+	return doc
 }
 
 type SearchService struct {
@@ -39,18 +182,30 @@ func NewSearchService(repo *repository.BookmarkRepository, indexPath string) (*S
 		indexPath: indexPath,
 	}
 
+	version, err := index.GetInternal(indexVersionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search index version: %w", err)
+	}
+	if string(version) != indexVersion {
+		log.Info().Msg("Search index mapping changed, rebuilding")
+		if err := service.RebuildIndex(); err != nil {
+			return nil, err
+		}
+	}
+
 	return service, nil
 }
 
 func openOrCreateIndex(indexPath string) (bleve.Index, error) {
 	index, err := bleve.Open(indexPath)
 	if err == bleve.ErrorIndexPathDoesNotExist {
-		mapping := bleve.NewIndexMapping()
-
-		index, err = bleve.New(indexPath, mapping)
+		index, err = bleve.New(indexPath, newIndexMapping())
 		if err != nil {
 			return nil, fmt.Errorf("failed to create search index: %w", err)
 		}
+		if err := index.SetInternal(indexVersionKey, []byte(indexVersion)); err != nil {
+			return nil, fmt.Errorf("failed to record search index version: %w", err)
+		}
 		log.Info().Msg("Created new search index")
 	} else if err != nil {
 		return nil, fmt.Errorf("failed to open search index: %w", err)
@@ -65,19 +220,7 @@ func (s *SearchService) Close() error {
 }
 
 func (s *SearchService) IndexBookmark(bookmark *model.Bookmark) error {
-	tagNames := make([]string, len(bookmark.Tags))
-	for i, tag := range bookmark.Tags {
-		tagNames[i] = tag.Name
-	}
-	doc := BookmarkIndex{
-		ID:          fmt.Sprintf("%d", bookmark.ID),
-		URL:         bookmark.URL,
-		Title:       bookmark.Title,
-		Description: bookmark.Description,
-		Content:     bookmark.Content,
-		Summary:     bookmark.Summary,
-		Tags:        tagNames,
-	}
+	doc := newBookmarkIndex(bookmark)
 	return s.index.Index(doc.ID, doc)
 }
 
@@ -85,15 +228,81 @@ func (s *SearchService) DeleteBookmark(id int64) error {
 	return s.index.Delete(fmt.Sprintf("%d", id))
 }
 
-func (s *SearchService) Search(query string, limit int) ([]*model.Bookmark, error) {
+// Search runs a query in the syntax of ParseQuery: free text in bleve's
+// query string syntax, narrowed by any tag:, lang:, readtime:, state:, is:
+// and rating: terms. Free text matches notes, annotations and highlights
+// too; notes:word, annotations:word or highlights:word searches only those.
+// Pinned bookmarks come first, then the rest in the query's sort order or by
+// relevance.
+func (s *SearchService) Search(queryString string, limit int) ([]*model.Bookmark, error) {
 	if limit <= 0 {
 		limit = 20
 	}
 
-	searchQuery := bleve.NewQueryStringQuery(fmt.Sprintf("*%s*", query))
+	q, err := ParseQuery(queryString)
+	if err != nil {
+		return nil, err
+	}
+
+	var clauses []query.Query
+	if q.Text != "" {
+		clauses = append(clauses, textQuery(q.Text))
+	}
+	if q.Tag != "" {
+		tags, err := s.repo.ResolveTags(q.Tag)
+		if err != nil {
+			return nil, err
+		}
+		tagQuery := bleve.NewTermQuery(tags[0])
+		tagQuery.SetField("tag_paths")
+		clauses = append(clauses, tagQuery)
+	}
+	if q.Lang != "" {
+		langQuery := bleve.NewTermQuery(q.Lang)
+		langQuery.SetField("lang")
+		clauses = append(clauses, langQuery)
+	}
+	if len(q.ReadStates) > 0 {
+		var states []query.Query
+		for _, state := range q.ReadStates {
+			stateQuery := bleve.NewTermQuery(state)
+			stateQuery.SetField("read_state")
+			states = append(states, stateQuery)
+		}
+		clauses = append(clauses, bleve.NewDisjunctionQuery(states...))
+	}
+	for _, flag := range []struct {
+		field string
+		set   bool
+	}{{"starred", q.Starred}, {"pinned", q.Pinned}} {
+		if flag.set {
+			flagQuery := bleve.NewBoolFieldQuery(true)
+			flagQuery.SetField(flag.field)
+			clauses = append(clauses, flagQuery)
+		}
+	}
+	if !q.ReadingTime.IsZero() {
+		clauses = append(clauses, rangeQuery("reading_time", q.ReadingTime))
+	}
+	if !q.Rating.IsZero() {
+		// A rating range leaves out unrated bookmarks.
+		rating := q.Rating
+		rating.Min = max(rating.Min, 1)
+		clauses = append(clauses, rangeQuery("rating", rating))
+	}
+
+	var searchQuery query.Query = bleve.NewMatchAllQuery()
+	if len(clauses) > 0 {
+		searchQuery = bleve.NewConjunctionQuery(clauses...)
+	}
 	searchRequest := bleve.NewSearchRequest(searchQuery)
 	searchRequest.Size = limit
 	searchRequest.Fields = []string{"id"}
+	order, ok := sortFields[q.Sort]
+	if !ok {
+		order = []string{"-_score"}
+	}
+	searchRequest.SortBy(append([]string{"-pinned"}, order...))
 
 	searchResults, err := s.index.Search(searchRequest)
 	if err != nil {
@@ -107,10 +316,11 @@ func (s *SearchService) Search(query string, limit int) ([]*model.Bookmark, erro
 			continue
 		}
 
-    id, err := strconv.ParseInt(idStr, 10,  64)
-    if err != nil {
-      log.Warn().Str("docID", hit.ID).Err(err).Msg("Failed to parse bookmark ID")
-    }
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			log.Warn().Str("docID", hit.ID).Err(err).Msg("Failed to parse bookmark ID")
+			continue
+		}
 
 		bookmark, err := s.repo.GetByID(id)
 		if err != nil {
@@ -124,11 +334,56 @@ func (s *SearchService) Search(query string, limit int) ([]*model.Bookmark, erro
 	}
 
 	return bookmarks, nil
+}
 
+// rangeQuery matches a numeric field within r.
+func rangeQuery(field string, r Range) query.Query {
+	var min, max *float64
+	if r.Min > 0 {
+		v := float64(r.Min)
+		min = &v
+	}
+	if r.Max != nil {
+		v := float64(*r.Max)
+		max = &v
+	}
+	inclusive := true
+	numericQuery := bleve.NewNumericRangeInclusiveQuery(min, max, &inclusive, &inclusive)
+	numericQuery.SetField(field)
+	return numericQuery
 }
 
+// textQuery matches free text as a query string, and also as plain words run
+// through each language analyzer, so a word finds its stemmed forms in
+// documents of that language.
+func textQuery(text string) query.Query {
+	clauses := []query.Query{bleve.NewQueryStringQuery(text)}
+	for _, lang := range textstats.Languages {
+		match := bleve.NewMatchQuery(text)
+		match.Analyzer = lang
+		match.SetOperator(query.MatchQueryOperatorAnd)
+		clauses = append(clauses, match)
+	}
+	return bleve.NewDisjunctionQuery(clauses...)
+}
+
+// sortFields gives the index fields to sort search results by for each sort
+// order, matching the orders bookmarks are listed in. Sorting is done by the
+// index, so the limit applies to the sorted results.
+var sortFields = map[string][]string{
+	repository.SortNewest:      {"-created_at"},
+	repository.SortOldest:      {"created_at"},
+	repository.SortTitle:       {"sort_title"},
+	repository.SortReadingTime: {"reading_time", "-created_at"},
+	repository.SortWordCount:   {"word_count", "-created_at"},
+	repository.SortPriority:    {"-priority", "created_at"},
+	repository.SortRating:      {"-rating", "-created_at"},
+}
+
+// RebuildIndex recreates the search index from scratch with the current
+// mapping and reindexes every bookmark.
 func (s *SearchService) RebuildIndex() error {
-	bookmarks, err := s.repo.List("", 1000, 0)
+	bookmarks, err := s.repo.Find(repository.ListOptions{Limit: -1})
 	if err != nil {
 		return fmt.Errorf("failed to fetch bookmarks: %w", err)
 	}
@@ -137,6 +392,9 @@ func (s *SearchService) RebuildIndex() error {
 	if err != nil {
 		return fmt.Errorf("failed to close index: %w", err)
 	}
+	if err := os.RemoveAll(s.indexPath); err != nil {
+		return fmt.Errorf("failed to remove old index: %w", err)
+	}
 
 	s.index, err = openOrCreateIndex(s.indexPath)
 	if err != nil {
@@ -145,21 +403,7 @@ func (s *SearchService) RebuildIndex() error {
 
 	batch := s.index.NewBatch()
 	for _, bookmark := range bookmarks {
-		tagNames := make([]string, len(bookmark.Tags))
-		for i, tag := range bookmark.Tags {
-			tagNames[i] = tag.Name
-		}
-
-		doc := BookmarkIndex{
-			ID:          fmt.Sprintf("%d", bookmark.ID),
-			URL:         bookmark.URL,
-			Title:       bookmark.Title,
-			Description: bookmark.Description,
-			Content:     bookmark.Content,
-			Summary:     bookmark.Summary,
-			Tags:        tagNames,
-		}
-
+		doc := newBookmarkIndex(bookmark)
 		err = batch.Index(doc.ID, doc)
 		if err != nil {
 			return fmt.Errorf("failed to add document to batch: %w", err)
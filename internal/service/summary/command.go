@@ -0,0 +1,103 @@
+package summary
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultCommandTimeout   = 60 * time.Second
+	DefaultCommandMaxOutput = 4096
+)
+
+var errOutputTooLarge = errors.New("output too large")
+
+// Command summarizes by running an external program, writing the page
+// content to its stdin and reading the summary from its stdout. This lets a
+// locally hosted model or any other tool produce summaries without the
+// application knowing about it.
+type Command struct {
+	name      string
+	args      []string
+	timeout   time.Duration
+	maxOutput int
+}
+
+// NewCommand returns a summarizer running argv. The command is killed after
+// timeout, and output longer than maxOutput bytes is rejected. Zero values
+// select the defaults.
+func NewCommand(argv []string, timeout time.Duration, maxOutput int) *Command {
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
+	}
+	if maxOutput <= 0 {
+		maxOutput = DefaultCommandMaxOutput
+	}
+	return &Command{
+		name:      argv[0],
+		args:      argv[1:],
+		timeout:   timeout,
+		maxOutput: maxOutput,
+	}
+}
+
+func (c *Command) Summarize(content string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.name, c.args...)
+	cmd.Stdin = strings.NewReader(content)
+	stdout := &limitedBuffer{limit: c.maxOutput}
+	stderr := &limitedBuffer{limit: 1024}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Don't wait forever on children that inherited the output pipes.
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return "", fmt.Errorf("summary command %s timed out after %s", c.name, c.timeout)
+	case stdout.overflow:
+		return "", fmt.Errorf("summary command %s: %w (limit %d bytes)", c.name, errOutputTooLarge, c.maxOutput)
+	case err != nil:
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("summary command %s failed: %w: %s", c.name, err, msg)
+		}
+		return "", fmt.Errorf("summary command %s failed: %w", c.name, err)
+	}
+
+	summary := strings.TrimSpace(stdout.String())
+	if summary == "" {
+		return "", fmt.Errorf("summary command %s produced no output", c.name)
+	}
+	return summary, nil
+}
+
+// limitedBuffer keeps at most limit bytes and records whether more were
+// written. Writes never fail so the child is not killed by a broken pipe.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); len(p) > room {
+		b.overflow = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,196 @@
+package summary
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultLength is the target summary length, in characters, used when none
+// is configured.
+const DefaultLength = 320
+
+// minWords is the shortest sentence considered for a summary. Shorter
+// fragments are usually navigation, captions or headings.
+const minWords = 6
+
+// Extractive summarizes text by picking the sentences closest to the
+// document's TF-IDF centroid, i.e. those that share the most weighted
+// vocabulary with the text as a whole.
+type Extractive struct {
+	length int
+}
+
+// NewExtractive returns a summarizer aiming for summaries of about length
+// characters. It always picks at least one sentence, so a summary can run
+// over the target when the best sentence is long.
+func NewExtractive(length int) *Extractive {
+	if length <= 0 {
+		length = DefaultLength
+	}
+	return &Extractive{length: length}
+}
+
+type sentence struct {
+	index int
+	text  string
+	terms map[string]float64
+	score float64
+}
+
+// Summarize returns the highest ranked sentences of content in their
+// original order. It never fails.
+func (e *Extractive) Summarize(content string) (string, error) {
+	return e.summarize(content), nil
+}
+
+func (e *Extractive) summarize(content string) string {
+	var candidates []*sentence
+	for i, text := range Sentences(content) {
+		if !isProse(text) {
+			continue
+		}
+		candidates = append(candidates, &sentence{index: i, text: text, terms: Terms(text)})
+	}
+	if len(candidates) == 0 {
+		return truncate(strings.Join(strings.Fields(content), " "), e.length)
+	}
+
+	// Weight terms by inverse sentence frequency and build the centroid.
+	frequency := make(map[string]int)
+	for _, s := range candidates {
+		for term := range s.terms {
+			frequency[term]++
+		}
+	}
+	centroid := make(map[string]float64)
+	for _, s := range candidates {
+		for term, count := range s.terms {
+			weight := count * math.Log(1+float64(len(candidates))/float64(frequency[term]))
+			s.terms[term] = weight
+			centroid[term] += weight
+		}
+	}
+
+	for position, s := range candidates {
+		s.score = cosine(s.terms, centroid)
+		// Articles tend to front-load their point; nudge early sentences
+		// ahead of equally central ones further down.
+		s.score *= 1 + 0.2/float64(position+1)
+	}
+
+	ranked := make([]*sentence, len(candidates))
+	copy(ranked, candidates)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+
+	var chosen []*sentence
+	length := 0
+	for _, s := range ranked {
+		if len(chosen) > 0 && length+len(s.text) > e.length {
+			continue
+		}
+		chosen = append(chosen, s)
+		length += len(s.text) + 1
+		if length >= e.length {
+			break
+		}
+	}
+	sort.Slice(chosen, func(i, j int) bool { return chosen[i].index < chosen[j].index })
+
+	texts := make([]string, len(chosen))
+	for i, s := range chosen {
+		texts[i] = s.text
+	}
+	return strings.Join(texts, " ")
+}
+
+// isProse reports whether a sentence looks like running text rather than a
+// menu, heading or list of links.
+func isProse(text string) bool {
+	words := strings.Fields(text)
+	if len(words) < minWords {
+		return false
+	}
+	last := []rune(text)
+	switch last[len(last)-1] {
+	case '.', '!', '?', '"', '”', '’', ')', '…':
+	default:
+		return false
+	}
+	letters := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return letters*2 > len(last)
+}
+
+// Terms counts the content words of text: lowercased words of three or more
+// letters, excluding common English stopwords.
+func Terms(text string) map[string]float64 {
+	counts := make(map[string]float64)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		if len(word) < 3 || stopwords[word] {
+			continue
+		}
+		counts[word]++
+	}
+	return counts
+}
+
+func cosine(a, b map[string]float64) float64 {
+	var dot, normA, normB float64
+	for term, weight := range a {
+		dot += weight * b[term]
+		normA += weight * weight
+	}
+	for _, weight := range b {
+		normB += weight * weight
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// truncate shortens s to at most n characters at a word boundary.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	cut := strings.LastIndexByte(s[:n], ' ')
+	if cut <= 0 {
+		// Without a space to cut at, cut at the last whole character.
+		cut = n
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+	}
+	return strings.TrimSpace(s[:cut]) + "…"
+}
+
+var stopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true,
+	"you": true, "all": true, "any": true, "can": true, "had": true, "her": true,
+	"was": true, "one": true, "our": true, "out": true, "has": true, "have": true,
+	"his": true, "how": true, "its": true, "may": true, "new": true, "now": true,
+	"see": true, "two": true, "who": true, "did": true, "get": true, "him": true,
+	"let": true, "she": true, "too": true, "use": true, "that": true, "this": true,
+	"with": true, "from": true, "they": true, "will": true, "would": true,
+	"there": true, "their": true, "what": true, "about": true, "which": true,
+	"when": true, "make": true, "like": true, "time": true, "just": true,
+	"know": true, "take": true, "into": true, "your": true, "some": true,
+	"could": true, "them": true, "than": true, "then": true, "also": true,
+	"been": true, "were": true, "more": true, "most": true, "such": true,
+	"only": true, "other": true, "these": true, "those": true, "very": true,
+	"each": true, "where": true, "while": true, "should": true, "being": true,
+	"here": true, "over": true, "after": true, "before": true, "because": true,
+	"does": true, "doing": true, "both": true, "between": true, "through": true,
+	"same": true, "many": true, "much": true, "well": true, "even": true,
+	"still": true, "yet": true, "via": true, "per": true, "using": true,
+}
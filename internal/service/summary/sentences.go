@@ -0,0 +1,117 @@
+package summary
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// abbreviations end in a period that does not close a sentence. Entries are
+// lowercase and without the trailing period.
+var abbreviations = map[string]bool{
+	"e.g": true, "i.e": true, "etc": true, "vs": true, "cf": true, "al": true,
+	"approx": true, "fig": true, "figs": true, "eq": true, "no": true, "vol": true,
+	"pp": true, "p": true, "ch": true, "sec": true, "ref": true, "ed": true,
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "st": true,
+	"jr": true, "sr": true, "inc": true, "ltd": true, "co": true, "corp": true,
+	"jan": true, "feb": true, "mar": true, "apr": true, "jun": true, "jul": true,
+	"aug": true, "sep": true, "sept": true, "oct": true, "nov": true, "dec": true,
+	"u.s": true, "u.k": true, "a.m": true, "p.m": true,
+}
+
+// Sentences splits text into sentences. Blank lines always end a sentence,
+// so headings and list items do not run into the following paragraph. A
+// period ends a sentence only when it is followed by whitespace and does
+// not belong to a known abbreviation or an initial; periods inside numbers,
+// version strings, domains and URLs never do.
+func Sentences(text string) []string {
+	var sentences []string
+	for _, paragraph := range paragraphs(text) {
+		sentences = append(sentences, splitParagraph(paragraph)...)
+	}
+	return sentences
+}
+
+func paragraphs(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var out []string
+	for _, p := range strings.Split(text, "\n\n") {
+		if p = strings.Join(strings.Fields(p), " "); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func splitParagraph(p string) []string {
+	var sentences []string
+	start := 0
+	for i := 0; i < len(p); {
+		r, size := utf8.DecodeRuneInString(p[i:])
+		i += size
+		if r != '.' && r != '!' && r != '?' && r != '…' {
+			continue
+		}
+
+		// Take the whole run of terminators and any closing quotes or
+		// brackets that follow them.
+		end := i
+		for end < len(p) {
+			next, n := utf8.DecodeRuneInString(p[end:])
+			if !strings.ContainsRune(".!?…\"'”’)]", next) {
+				break
+			}
+			end += n
+		}
+		i = end
+
+		if end < len(p) && p[end] != ' ' {
+			// "3.14", "v1.2.0", "example.com", "a.b/c".
+			continue
+		}
+		if r == '.' && !endsSentence(p[start:end], p[end:]) {
+			continue
+		}
+
+		if s := strings.TrimSpace(p[start:end]); s != "" {
+			sentences = append(sentences, s)
+		}
+		start = end
+	}
+	if s := strings.TrimSpace(p[start:]); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+// endsSentence decides whether the period closing candidate ends a sentence,
+// given the text that follows it.
+func endsSentence(candidate, rest string) bool {
+	word := lastWord(candidate)
+	if word == "" {
+		return true
+	}
+	lower := strings.ToLower(strings.TrimRight(word, ".\"'”’)]"))
+	if abbreviations[lower] {
+		return false
+	}
+	// Single-letter initials such as "J. R. R. Tolkien".
+	if utf8.RuneCountInString(lower) == 1 && unicode.IsUpper([]rune(word)[0]) {
+		return false
+	}
+
+	// A sentence should be followed by something that can start one.
+	next, _ := utf8.DecodeRuneInString(strings.TrimLeft(rest, " "))
+	if next == utf8.RuneError {
+		return true
+	}
+	return !unicode.IsLower(next)
+}
+
+func lastWord(s string) string {
+	s = strings.TrimRight(s, " ")
+	if i := strings.LastIndexByte(s, ' '); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.TrimLeft(s, "(\"'“‘[")
+}
@@ -0,0 +1,35 @@
+package summary
+
+import (
+	"github.com/rs/zerolog/log"
+)
+
+// Summarizer produces a short summary of a page's extracted text.
+type Summarizer interface {
+	Summarize(content string) (string, error)
+}
+
+// Fallback tries a primary summarizer and falls back to a second one when the
+// primary fails or returns nothing.
+type Fallback struct {
+	primary  Summarizer
+	fallback Summarizer
+}
+
+func NewFallback(primary, fallback Summarizer) *Fallback {
+	return &Fallback{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+func (f *Fallback) Summarize(content string) (string, error) {
+	summary, err := f.primary.Summarize(content)
+	if err == nil && summary != "" {
+		return summary, nil
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg("Summarizer failed, falling back to built-in summary")
+	}
+	return f.fallback.Summarize(content)
+}
@@ -0,0 +1,175 @@
+package summary
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestSentences(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "plain",
+			text: "The first sentence. The second one! And a third?",
+			want: []string{"The first sentence.", "The second one!", "And a third?"},
+		},
+		{
+			name: "abbreviations",
+			text: "Use a short name, e.g. Bob or Ann. Long names are fine too, i.e. Robert.",
+			want: []string{"Use a short name, e.g. Bob or Ann.", "Long names are fine too, i.e. Robert."},
+		},
+		{
+			name: "version numbers",
+			text: "Version 1.2.3 fixes the parser. Upgrade from v1.2.0 today.",
+			want: []string{"Version 1.2.3 fixes the parser.", "Upgrade from v1.2.0 today."},
+		},
+		{
+			name: "urls",
+			text: "Read the docs at https://example.com/docs/v1.2 first. Then visit example.org.",
+			want: []string{"Read the docs at https://example.com/docs/v1.2 first.", "Then visit example.org."},
+		},
+		{
+			name: "initials",
+			text: "The book was written by J. R. R. Tolkien in England. It sold well.",
+			want: []string{"The book was written by J. R. R. Tolkien in England.", "It sold well."},
+		},
+		{
+			name: "titles",
+			text: "Dr. Smith and Mr. Jones met in the U.S. Capitol. They talked for hours.",
+			want: []string{"Dr. Smith and Mr. Jones met in the U.S. Capitol.", "They talked for hours."},
+		},
+		{
+			name: "lowercase continuation",
+			text: "Pi is about 3.14 and the ratio. is constant. Really.",
+			want: []string{"Pi is about 3.14 and the ratio. is constant.", "Really."},
+		},
+		{
+			name: "paragraphs",
+			text: "A heading\n\nThe body starts\nhere. It ends here.",
+			want: []string{"A heading", "The body starts here.", "It ends here."},
+		},
+		{
+			name: "empty",
+			text: " \n\n ",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sentences(tt.text)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sentences(%q)\n got: %q\nwant: %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		n    int
+		want string
+	}{
+		{"short", "a short text", 20, "a short text"},
+		{"at a space", "cut this text here", 12, "cut this…"},
+		{"no space", "abcdefghij", 4, "abcd…"},
+		{"no space, multi-byte", "東京は日本の首都", 10, "東京は…"},
+		{"url", "https://例え.jp/パス", 12, "https://例…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.s, tt.n)
+			if got != tt.want {
+				t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncate(%q, %d) is not valid UTF-8: %q", tt.s, tt.n, got)
+			}
+		})
+	}
+}
+
+// TestExtractiveGolden summarizes each testdata/*.txt article and compares
+// the summary with testdata/*.golden. Run with -update to rewrite them.
+func TestExtractiveGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no testdata inputs")
+	}
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".txt")
+		t.Run(name, func(t *testing.T) {
+			content, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := NewExtractive(DefaultLength).Summarize(string(content))
+			if err != nil {
+				t.Fatalf("Summarize: %v", err)
+			}
+
+			golden := strings.TrimSuffix(input, ".txt") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got+"\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("reading golden file: %v (run with -update to create it)", err)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("summary of %s is not valid UTF-8: %q", input, got)
+			}
+			if got != strings.TrimSuffix(string(want), "\n") {
+				t.Errorf("summary of %s\n got: %q\nwant: %q", input, got, want)
+			}
+		})
+	}
+}
+
+// TestExtractiveLength checks that the length is a target: a summary keeps
+// at least its best sentence, and longer targets never give shorter summaries.
+func TestExtractiveLength(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "long-article.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := 0
+	for _, length := range []int{20, 200, DefaultLength, 1000} {
+		got, err := NewExtractive(length).Summarize(string(content))
+		if err != nil {
+			t.Fatalf("Summarize: %v", err)
+		}
+		sentences := Sentences(got)
+		if len(sentences) == 0 {
+			t.Fatalf("length %d: empty summary", length)
+		}
+		if n := len(got); n > length && len(sentences) > 1 {
+			t.Errorf("length %d: summary of %d characters has %d sentences", length, n, len(sentences))
+		}
+		if len(got) < previous {
+			t.Errorf("length %d: summary shorter than for a smaller length", length)
+		}
+		previous = len(got)
+	}
+}
@@ -0,0 +1,212 @@
+package tagsuggest
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/san-kum/bookmarker/internal/repository"
+	"github.com/san-kum/bookmarker/internal/service/summary"
+)
+
+// Reasons a tag is suggested.
+const (
+	ReasonKeyword = "keyword"
+	ReasonDomain  = "domain"
+	ReasonRelated = "related"
+)
+
+// minScore drops weak suggestions, mostly keywords that appear only a couple
+// of times and are not already used as tags.
+const minScore = 0.25
+
+// Suggestion is a tag proposed for a bookmark, with the signals that
+// produced it.
+type Suggestion struct {
+	Tag     string
+	Score   float64
+	Reasons []string
+}
+
+// Suggester proposes tags for a bookmark from its title, content and URL,
+// from the tags of other bookmarks on the same site, and from tags that were
+// used together with the bookmark's current tags in the past.
+type Suggester struct {
+	bookmarks *repository.BookmarkRepository
+}
+
+func NewSuggester(bookmarks *repository.BookmarkRepository) *Suggester {
+	return &Suggester{
+		bookmarks: bookmarks,
+	}
+}
+
+// Suggest returns up to limit tags for bookmark, best first. Tags the
+// bookmark already has are never suggested. The bookmark need not be saved,
+// so callers can suggest tags for a URL that is still being added.
+func (s *Suggester) Suggest(bookmark *model.Bookmark, limit int) ([]Suggestion, error) {
+	tagCounts, err := s.bookmarks.TagCounts()
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]string, len(tagCounts))
+	for name := range tagCounts {
+		existing[strings.ToLower(name)] = name
+	}
+
+	current := make(map[string]bool, len(bookmark.Tags))
+	currentNames := make([]string, 0, len(bookmark.Tags))
+	for _, tag := range bookmark.Tags {
+		current[strings.ToLower(tag.Name)] = true
+		currentNames = append(currentNames, tag.Name)
+	}
+
+	scores := make(map[string]*Suggestion)
+	add := func(tag string, score float64, reason string) {
+		if tag == "" || current[strings.ToLower(tag)] || score <= 0 {
+			return
+		}
+		suggestion, ok := scores[tag]
+		if !ok {
+			suggestion = &Suggestion{Tag: tag}
+			scores[tag] = suggestion
+		}
+		suggestion.Score += score
+		for _, r := range suggestion.Reasons {
+			if r == reason {
+				return
+			}
+		}
+		suggestion.Reasons = append(suggestion.Reasons, reason)
+	}
+
+	// Keywords. Existing tags are strongly preferred over new words so the
+	// tag vocabulary does not sprawl.
+	keywords := keywords(bookmark)
+	var maxWeight float64
+	for _, weight := range keywords {
+		if weight > maxWeight {
+			maxWeight = weight
+		}
+	}
+	for word, weight := range keywords {
+		if tag, ok := matchTag(existing, word); ok {
+			add(tag, 1+weight/maxWeight, ReasonKeyword)
+		} else if weight >= 3 {
+			add(word, 0.5*weight/maxWeight, ReasonKeyword)
+		}
+	}
+
+	// The site, and what other bookmarks from it were tagged with.
+	host := hostname(bookmark.URL)
+	if host != "" {
+		if label := siteLabel(host); label != "" {
+			if tag, ok := matchTag(existing, label); ok {
+				add(tag, 1, ReasonDomain)
+			}
+		}
+
+		hostTags, total, err := s.bookmarks.HostTagCounts(host)
+		if err != nil {
+			return nil, err
+		}
+		for tag, count := range hostTags {
+			add(tag, 2*float64(count)/float64(total), ReasonDomain)
+		}
+	}
+
+	// Tags that went together with the ones already chosen.
+	if len(currentNames) > 0 {
+		related, err := s.bookmarks.CooccurringTags(currentNames)
+		if err != nil {
+			return nil, err
+		}
+		var uses int
+		for _, name := range currentNames {
+			uses += tagCounts[name]
+		}
+		for tag, count := range related {
+			if uses > 0 {
+				add(tag, 1.5*float64(count)/float64(uses), ReasonRelated)
+			}
+		}
+	}
+
+	suggestions := make([]Suggestion, 0, len(scores))
+	for _, suggestion := range scores {
+		if suggestion.Score >= minScore {
+			suggestions = append(suggestions, *suggestion)
+		}
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Tag < suggestions[j].Tag
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// keywords weighs the content words of a bookmark. Words in the title and URL
+// path count more than words in the body.
+func keywords(bookmark *model.Bookmark) map[string]float64 {
+	weights := summary.Terms(bookmark.Content)
+	if bookmark.Title != bookmark.URL {
+		for word, count := range summary.Terms(bookmark.Title) {
+			weights[word] += 3 * count
+		}
+	}
+	if u, err := url.Parse(bookmark.URL); err == nil {
+		for word, count := range summary.Terms(u.Path) {
+			weights[word] += 2 * count
+		}
+	}
+	for word := range weights {
+		if isNumeric(word) {
+			delete(weights, word)
+		}
+	}
+	return weights
+}
+
+// matchTag finds an existing tag for word, allowing for a plural "s".
+func matchTag(existing map[string]string, word string) (string, bool) {
+	for _, candidate := range []string{word, strings.TrimSuffix(word, "s"), word + "s"} {
+		if tag, ok := existing[candidate]; ok {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+func hostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// siteLabel returns the name of a site from its host, e.g. "github" for
+// "gist.github.com".
+func siteLabel(host string) string {
+	labels := strings.Split(strings.TrimPrefix(host, "www."), ".")
+	if len(labels) < 2 {
+		return ""
+	}
+	return labels[len(labels)-2]
+}
+
+func isNumeric(word string) bool {
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package textstats
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WordsPerMinute is the reading speed used to estimate reading time.
+const WordsPerMinute = 230
+
+// minDetectWords is the fewest stopword hits needed before a language is
+// reported. Below it the text is too short to tell.
+const minDetectWords = 5
+
+// Stats describes a piece of extracted text.
+type Stats struct {
+	Words       int
+	ReadingTime int // minutes
+	Lang        string
+}
+
+// Analyze counts the words in text, estimates its reading time and detects
+// its language.
+func Analyze(text string) Stats {
+	words := Words(text)
+	return Stats{
+		Words:       len(words),
+		ReadingTime: ReadingTime(len(words)),
+		Lang:        detect(words),
+	}
+}
+
+// Words splits text into lowercased words.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+	})
+}
+
+// ReadingTime estimates the minutes needed to read words words, rounding up
+// so that any text takes at least a minute.
+func ReadingTime(words int) int {
+	if words == 0 {
+		return 0
+	}
+	return (words + WordsPerMinute - 1) / WordsPerMinute
+}
+
+// DetectLanguage returns the ISO 639-1 code of the language text is most
+// likely written in, or "" if it cannot tell.
+func DetectLanguage(text string) string {
+	return detect(Words(text))
+}
+
+// detect picks the language whose most common words make up the largest share
+// of words.
+func detect(words []string) string {
+	hits := make(map[string]int)
+	for _, word := range words {
+		for _, lang := range stopwords[word] {
+			hits[lang]++
+		}
+	}
+
+	best, bestHits, total := "", 0, 0
+	for _, lang := range Languages {
+		total += hits[lang]
+		if hits[lang] > bestHits {
+			best, bestHits = lang, hits[lang]
+		}
+	}
+	if bestHits < minDetectWords || bestHits*2 < total {
+		return ""
+	}
+	return best
+}
+
+// Languages lists the languages DetectLanguage can recognize.
+var Languages = []string{"en", "de", "fr", "es", "it", "pt", "nl", "sv", "ru"}
+
+var stopwordLists = map[string]string{
+	"en": "the of and to in is that it for was on are with as be this by at from or have an not but which they you were their has been will would there what all can more if about when we",
+	"de": "der die und in den von zu das mit sich des auf für ist im dem nicht ein eine als auch es an werden aus er hat dass sie nach wird bei einer um noch wie einem über einen so zum war haben nur oder aber",
+	"fr": "le la les de des et en un une du est que qui dans pour pas par sur au plus ce il sont avec ne se aux ou son elle nous vous mais été cette leur",
+	"es": "el la los las de del y en que un una es por con para se no su al lo como más pero sus le ya o fue este ha sí porque esta son entre cuando muy sin sobre también",
+	"it": "il lo la gli le di del della dei e è che un una per non in con si da al alla sono come più ma anche questo ci nel nella delle ha loro essere",
+	"pt": "o os a as de do da dos das e é que um uma em no na para com não por se mais como mas foi ao ele ela seu sua são também pelo pela já",
+	"nl": "de het een en van in is dat op te zijn voor met niet aan er die ook als bij door maar om dan wordt nog wel naar geen deze kan zich heeft",
+	"sv": "och att det som en på är av för med till den har inte om ett var men jag de så kan man vi när från sig ska eller efter också",
+	"ru": "и в не на что с по это как он к из за то но от для же все так его она вы о бы у мы был только или было они",
+}
+
+// stopwords maps each common word to the languages it is common in.
+var stopwords = func() map[string][]string {
+	m := make(map[string][]string)
+	for lang, list := range stopwordLists {
+		for _, word := range strings.Fields(list) {
+			m[word] = append(m[word], lang)
+		}
+	}
+	return m
+}()
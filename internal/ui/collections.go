@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/san-kum/bookmarker/internal/repository"
+	"github.com/san-kum/bookmarker/internal/service"
+)
+
+func (t *TUI) setupCollectionTree() {
+	t.collectionTree = tview.NewTreeView().
+		SetRoot(tview.NewTreeNode("")).
+		SetTopLevel(1).
+		SetGraphics(false)
+	t.collectionTree.SetBorder(true).SetTitle(" Collections ")
+	t.expandedCollections = make(map[int64]bool)
+
+	t.loadCollections()
+
+	// Enter shows a collection's bookmarks in order; Space expands or
+	// collapses its subcollections.
+	t.collectionTree.SetSelectedFunc(func(node *tview.TreeNode) {
+		if id, ok := node.GetReference().(int64); ok {
+			t.loadBookmarksWith(repository.ListOptions{Collection: id})
+		}
+	})
+	t.collectionTree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() != ' ' {
+			return event
+		}
+		node := t.collectionTree.GetCurrentNode()
+		id, ok := node.GetReference().(int64)
+		if !ok || len(node.GetChildren()) == 0 {
+			return nil
+		}
+		t.expandedCollections[id] = !node.IsExpanded()
+		node.SetExpanded(t.expandedCollections[id])
+		node.SetText(t.collectionNodeText(id, true, node.IsExpanded()))
+		return nil
+	})
+}
+
+// loadCollections fills the collection tree. Collections whose children
+// were expanded stay expanded.
+func (t *TUI) loadCollections() {
+	var current int64
+	if node := t.collectionTree.GetCurrentNode(); node != nil {
+		current, _ = node.GetReference().(int64)
+	}
+	root := t.collectionTree.GetRoot().ClearChildren()
+
+	collections, err := t.collections.List()
+	if err != nil {
+		t.setStatus(fmt.Sprintf("[red]Failed to load collections: %v[white]", err))
+		return
+	}
+
+	nodes := make(map[int64]*tview.TreeNode, len(collections))
+	parents := make(map[int64]*int64, len(collections))
+	names := make(map[int64]string, len(collections))
+	for _, collection := range collections {
+		nodes[collection.ID] = tview.NewTreeNode("").
+			SetReference(collection.ID).
+			SetExpanded(t.expandedCollections[collection.ID])
+		parents[collection.ID] = collection.ParentID
+		names[collection.ID] = collection.Name
+	}
+	var path func(id int64) string
+	path = func(id int64) string {
+		if parent := parents[id]; parent != nil {
+			return path(*parent) + service.CollectionPathSeparator + names[id]
+		}
+		return names[id]
+	}
+
+	// Collections are ordered by position, so each node's children are
+	// added in order.
+	t.collectionPaths = make(map[int64]string, len(collections))
+	for _, collection := range collections {
+		node := nodes[collection.ID]
+		t.collectionPaths[collection.ID] = path(collection.ID)
+		if collection.ParentID == nil {
+			root.AddChild(node)
+		} else if parent, ok := nodes[*collection.ParentID]; ok {
+			parent.AddChild(node.SetIndent(2))
+		}
+	}
+	for id, node := range nodes {
+		node.SetText(t.collectionNodeText(id, len(node.GetChildren()) > 0, node.IsExpanded()))
+	}
+
+	if node, ok := nodes[current]; ok {
+		t.collectionTree.SetCurrentNode(node)
+	} else if children := root.GetChildren(); len(children) > 0 {
+		t.collectionTree.SetCurrentNode(children[0])
+	}
+}
+
+func (t *TUI) collectionNodeText(id int64, hasChildren, expanded bool) string {
+	path := t.collectionPaths[id]
+	name := path[strings.LastIndex(path, service.CollectionPathSeparator)+1:]
+	return treeMarker(hasChildren, expanded) + name
+}
+
+// promptCollection asks for the collection to file the current bookmark in,
+// creating it if needed. An empty path takes the bookmark out of its
+// collection.
+func (t *TUI) promptCollection() {
+	index := t.bookmarkList.GetCurrentItem()
+	if index < 0 || index >= len(t.currentBookmarks) {
+		return
+	}
+	bookmark := t.currentBookmarks[index]
+
+	entry, err := t.collections.Entry(bookmark.ID)
+	if err != nil {
+		t.setStatus(fmt.Sprintf("[red]%v[white]", err))
+		return
+	}
+	current := ""
+	if entry != nil {
+		current = t.collectionPaths[entry.CollectionID]
+	}
+
+	t.prompt("Collection", "Collection: ", current, t.bookmarkList, func(path string) {
+		if strings.TrimSpace(path) == "" {
+			if err := t.collections.RemoveBookmark(bookmark.ID); err != nil {
+				t.setStatus(fmt.Sprintf("[red]%v[white]", err))
+				return
+			}
+			t.loadCollections()
+			t.setStatus("[green]Removed from its collection[white]")
+			return
+		}
+
+		collection, err := t.collections.Create(path)
+		if err == nil {
+			err = t.collections.AddBookmark(collection.ID, bookmark.ID)
+		}
+		if err != nil {
+			t.setStatus(fmt.Sprintf("[red]%v[white]", err))
+			return
+		}
+		t.loadCollections()
+		t.setStatus(fmt.Sprintf("[green]Filed in %s[white]", tview.Escape(t.collectionPaths[collection.ID])))
+	})
+}
+
+// moveInCollection moves the current bookmark by offset places within the
+// collection being shown.
+func (t *TUI) moveInCollection(offset int) {
+	opts := t.listOptions
+	index := t.bookmarkList.GetCurrentItem()
+	if opts.Collection == 0 || opts.Sort != "" {
+		t.setStatus("[yellow]Open a collection to reorder its bookmarks[white]")
+		return
+	}
+	if index < 0 || index >= len(t.currentBookmarks) || index+offset < 0 || index+offset >= len(t.currentBookmarks) {
+		return
+	}
+
+	if err := t.collections.MoveBookmark(t.currentBookmarks[index].ID, index+offset); err != nil {
+		t.setStatus(fmt.Sprintf("[red]%v[white]", err))
+		return
+	}
+	t.loadBookmarksWith(opts)
+	t.bookmarkList.SetCurrentItem(index + offset)
+}
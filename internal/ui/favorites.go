@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/san-kum/bookmarker/internal/model"
+)
+
+// bookmarkBadges returns the glyphs shown before the title of a pinned or
+// starred bookmark.
+func bookmarkBadges(bookmark *model.Bookmark) string {
+	var badges string
+	if bookmark.Pinned {
+		badges += "[blue::b]▲[-::-]"
+	}
+	if bookmark.Starred {
+		badges += "[yellow]★[-]"
+	}
+	if badges != "" {
+		badges += " "
+	}
+	return badges
+}
+
+// ratingStars draws a rating as filled and empty stars.
+func ratingStars(rating int) string {
+	return strings.Repeat("★", rating) + strings.Repeat("☆", model.MaxRating-rating)
+}
+
+func formatMarks(bookmark *model.Bookmark) string {
+	marks := []string{"not rated"}
+	if bookmark.Rating > 0 {
+		marks[0] = fmt.Sprintf("[yellow]%s[white] (%d/%d)", ratingStars(bookmark.Rating), bookmark.Rating, model.MaxRating)
+	}
+	if bookmark.Starred {
+		marks = append(marks, "starred")
+	}
+	if bookmark.Pinned {
+		marks = append(marks, "pinned")
+	}
+	return strings.Join(marks, ", ")
+}
+
+// ratingKey reports the rating a key sets: 1 to model.MaxRating, or 0 to
+// clear it.
+func ratingKey(event *tcell.EventKey) (int, bool) {
+	if event.Key() != tcell.KeyRune || event.Modifiers()&tcell.ModAlt != 0 {
+		return 0, false
+	}
+	if r := event.Rune(); r >= '0' && r <= '0'+model.MaxRating {
+		return int(r - '0'), true
+	}
+	return 0, false
+}
+
+// updateMarks saves a change to a bookmark's star, pin or rating and returns
+// it updated, or nil if that failed.
+func (t *TUI) updateMarks(bookmark *model.Bookmark, status string, change func() error) *model.Bookmark {
+	if err := change(); err != nil {
+		t.setStatus(fmt.Sprintf("[red]%v[white]", err))
+		return nil
+	}
+	updated, err := t.bookmarkService.Get(bookmark.ID)
+	if err != nil || updated == nil {
+		return nil
+	}
+	t.setStatus(fmt.Sprintf("[green]%s[white]", status))
+	return updated
+}
+
+func (t *TUI) toggleStar(bookmark *model.Bookmark) *model.Bookmark {
+	status := "Starred"
+	if bookmark.Starred {
+		status = "Unstarred"
+	}
+	return t.updateMarks(bookmark, status, func() error {
+		return t.bookmarkService.SetStarred(bookmark.ID, !bookmark.Starred)
+	})
+}
+
+func (t *TUI) togglePin(bookmark *model.Bookmark) *model.Bookmark {
+	status := "Pinned to the top"
+	if bookmark.Pinned {
+		status = "Unpinned"
+	}
+	return t.updateMarks(bookmark, status, func() error {
+		return t.bookmarkService.SetPinned(bookmark.ID, !bookmark.Pinned)
+	})
+}
+
+func (t *TUI) rate(bookmark *model.Bookmark, rating int) *model.Bookmark {
+	status := fmt.Sprintf("Rated %d/%d", rating, model.MaxRating)
+	if rating == 0 {
+		status = "Rating cleared"
+	}
+	return t.updateMarks(bookmark, status, func() error {
+		return t.bookmarkService.SetRating(bookmark.ID, rating)
+	})
+}
@@ -0,0 +1,263 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/san-kum/bookmarker/internal/model"
+)
+
+// setupPassagePage sets up the page for highlighting passages of a
+// bookmark's content. The content is shown read-only in a text area, where
+// passages are selected with Shift and the arrow keys or the mouse.
+func (t *TUI) setupPassagePage() {
+	t.passageArea = tview.NewTextArea()
+	t.passageArea.SetBorder(true).SetTitle(" Content (Shift+arrows or mouse: select, Enter: highlight) ")
+	t.passageArea.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter:
+			t.promptHighlight()
+			return nil
+		case tcell.KeyLeft, tcell.KeyRight, tcell.KeyUp, tcell.KeyDown,
+			tcell.KeyHome, tcell.KeyEnd, tcell.KeyPgUp, tcell.KeyPgDn,
+			tcell.KeyCtrlA, tcell.KeyCtrlE, tcell.KeyCtrlF, tcell.KeyCtrlB, tcell.KeyCtrlL:
+			return event
+		}
+		// Anything else would edit the content.
+		return nil
+	})
+
+	t.passageList = tview.NewList().
+		SetSecondaryTextColor(tcell.ColorDimGray)
+	t.passageList.SetBorder(true).SetTitle(" Highlights (c: comment, d: delete) ")
+
+	t.passagePage = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(t.passageArea, 0, 3, true).
+			AddItem(t.passageList, 0, 1, false),
+			0, 1, true).
+		AddItem(t.statusBar, 1, 0, false).
+		AddItem(t.helpBar, 1, 0, false)
+
+	t.passagePage.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab:
+			t.switchFocus(t.passageArea, t.passageList)
+			return nil
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			if t.currentBookmark != nil {
+				t.viewBookmark(t.currentBookmark)
+			}
+			return nil
+		}
+		if !t.passageList.HasFocus() || t.currentBookmark == nil {
+			return event
+		}
+
+		index := t.passageList.GetCurrentItem()
+		highlights := t.currentBookmark.Highlights
+		if index < 0 || index >= len(highlights) {
+			return event
+		}
+		highlight := highlights[index]
+		switch event.Rune() {
+		case 'c':
+			t.prompt("Comment", "Comment: ", highlight.Comment, t.passageList, func(comment string) {
+				if err := t.bookmarkService.SetHighlightComment(highlight.ID, comment); err != nil {
+					t.setStatus(fmt.Sprintf("[red]Failed to save comment: %v[white]", err))
+					return
+				}
+				t.reloadPassages()
+				t.setStatus("[green]Comment saved[white]")
+			})
+			return nil
+		case 'd':
+			t.confirm("Delete this highlight?", "Delete", t.passageList, func() {
+				if err := t.bookmarkService.DeleteHighlight(highlight.ID); err != nil {
+					t.setStatus(fmt.Sprintf("[red]Failed to delete highlight: %v[white]", err))
+					return
+				}
+				t.reloadPassages()
+				t.setStatus("[green]Highlight deleted[white]")
+			})
+			return nil
+		}
+		return event
+	})
+}
+
+// viewPassages opens a bookmark's content for highlighting.
+func (t *TUI) viewPassages(bookmark *model.Bookmark) {
+	if bookmark.Content == "" {
+		t.setStatus("[yellow]No content to highlight yet[white]")
+		return
+	}
+	t.currentBookmark = bookmark
+	t.passageArea.SetText(bookmark.Content, false)
+	t.loadPassageList(bookmark)
+	t.showPage("passages")
+	t.app.SetFocus(t.passageArea)
+}
+
+// reloadPassages reloads the current bookmark after its highlights changed.
+func (t *TUI) reloadPassages() {
+	bookmark, err := t.bookmarkService.Get(t.currentBookmark.ID)
+	if err != nil || bookmark == nil {
+		return
+	}
+	t.renderBookmark(bookmark)
+	t.loadPassageList(bookmark)
+}
+
+func (t *TUI) loadPassageList(bookmark *model.Bookmark) {
+	current := t.passageList.GetCurrentItem()
+	t.passageList.Clear()
+	for _, highlight := range bookmark.Highlights {
+		highlight := highlight
+		secondary := highlight.Comment
+		if secondary == "" {
+			secondary = highlight.CreatedAt.Local().Format("2006-01-02 15:04")
+		}
+		t.passageList.AddItem(tview.Escape(passageSnippet(highlight.Text)), tview.Escape(secondary), 0, func() {
+			// Show the passage in the content, if it is still there.
+			if highlight.End() <= len(bookmark.Content) && bookmark.Content[highlight.Offset:highlight.End()] == highlight.Text {
+				t.passageArea.Select(highlight.Offset, highlight.End())
+			}
+			t.app.SetFocus(t.passageArea)
+		})
+	}
+	if current < t.passageList.GetItemCount() {
+		t.passageList.SetCurrentItem(current)
+	}
+}
+
+// promptHighlight asks for an optional comment and saves the selected
+// passage as a highlight.
+func (t *TUI) promptHighlight() {
+	bookmark := t.currentBookmark
+	text, start, _ := t.passageArea.GetSelection()
+	if bookmark == nil || strings.TrimSpace(text) == "" {
+		t.setStatus("[yellow]Select a passage first, with Shift and the arrow keys or the mouse[white]")
+		return
+	}
+
+	t.prompt("Highlight", "Comment (optional): ", "", t.passageArea, func(comment string) {
+		if _, err := t.bookmarkService.AddHighlight(bookmark.ID, text, start, comment); err != nil {
+			t.setStatus(fmt.Sprintf("[red]Failed to save highlight: %v[white]", err))
+			return
+		}
+		t.reloadPassages()
+		t.setStatus("[green]Highlight saved[white]")
+	})
+}
+
+// setupHighlightsPage sets up the page listing highlights across the
+// library.
+func (t *TUI) setupHighlightsPage() {
+	t.highlightFilter = tview.NewInputField().
+		SetLabel("Filter: ").
+		SetChangedFunc(func(string) {
+			t.loadHighlights()
+		})
+	t.highlightFilter.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			t.app.SetFocus(t.highlightList)
+		}
+	})
+
+	t.highlightList = tview.NewList().
+		SetSecondaryTextColor(tcell.ColorDimGray)
+	t.highlightList.SetBorder(true).SetTitle(" Highlights (Enter: open, d: delete) ")
+
+	t.highlightsPage = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(t.highlightFilter, 1, 0, false).
+		AddItem(t.highlightList, 0, 1, true).
+		AddItem(t.statusBar, 1, 0, false).
+		AddItem(t.helpBar, 1, 0, false)
+
+	t.highlightsPage.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			t.switchFocus(t.highlightList, t.highlightFilter)
+			return nil
+		}
+		if !t.highlightList.HasFocus() || event.Rune() != 'd' {
+			return event
+		}
+		index := t.highlightList.GetCurrentItem()
+		if index < 0 || index >= len(t.listedHighlights) {
+			return nil
+		}
+		highlight := t.listedHighlights[index]
+		t.confirm("Delete this highlight?", "Delete", t.highlightList, func() {
+			if err := t.bookmarkService.DeleteHighlight(highlight.ID); err != nil {
+				t.setStatus(fmt.Sprintf("[red]Failed to delete highlight: %v[white]", err))
+				return
+			}
+			t.loadHighlights()
+			t.setStatus("[green]Highlight deleted[white]")
+		})
+		return nil
+	})
+}
+
+// loadHighlights lists the highlights matching the filter, most recently
+// highlighted bookmarks first.
+func (t *TUI) loadHighlights() {
+	bookmarks, err := t.bookmarkService.Highlights(t.highlightFilter.GetText())
+	if err != nil {
+		t.setStatus(fmt.Sprintf("[red]Failed to load highlights: %v[white]", err))
+		return
+	}
+
+	t.highlightList.Clear()
+	t.listedHighlights = nil
+	for _, bookmark := range bookmarks {
+		id := bookmark.ID
+		source := bookmark.Title
+		for _, highlight := range bookmark.Highlights {
+			secondary := source
+			if highlight.Comment != "" {
+				secondary += " | " + highlight.Comment
+			}
+			t.highlightList.AddItem(tview.Escape(passageSnippet(highlight.Text)), tview.Escape(secondary), 0, func() {
+				bookmark, err := t.bookmarkService.Get(id)
+				if err != nil || bookmark == nil {
+					return
+				}
+				t.viewBookmark(bookmark)
+			})
+			t.listedHighlights = append(t.listedHighlights, highlight)
+		}
+	}
+}
+
+// passageSnippet returns the start of a passage on a single line.
+func passageSnippet(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > 100 {
+		return string(runes[:100]) + "…"
+	}
+	return text
+}
+
+// markHighlights escapes content for display and marks the passages
+// highlighted in it. Highlights whose passage is no longer at their offset
+// are not marked.
+func markHighlights(content string, highlights []model.Highlight) string {
+	var sb strings.Builder
+	pos := 0
+	for _, highlight := range highlights {
+		if highlight.Offset < pos || highlight.End() > len(content) || content[highlight.Offset:highlight.End()] != highlight.Text {
+			continue
+		}
+		sb.WriteString(tview.Escape(content[pos:highlight.Offset]))
+		sb.WriteString("[black:yellow]")
+		sb.WriteString(tview.Escape(highlight.Text))
+		sb.WriteString("[-:-]")
+		pos = highlight.End()
+	}
+	sb.WriteString(tview.Escape(content[pos:]))
+	return sb.String()
+}
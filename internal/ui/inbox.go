@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/san-kum/bookmarker/internal/repository"
+)
+
+// setupInboxPage sets up the read-later queue: bookmarks that are unread or
+// being read, oldest or highest priority first.
+func (t *TUI) setupInboxPage() {
+	t.inboxOrder = repository.SortOldest
+	t.inboxList = tview.NewList().
+		SetSecondaryTextColor(tcell.ColorDimGray)
+	t.inboxList.SetBorder(true)
+
+	t.inboxPage = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(t.inboxList, 0, 1, true).
+		AddItem(t.statusBar, 1, 0, false).
+		AddItem(t.helpBar, 1, 0, false)
+
+	// Opening a bookmark starts reading it.
+	t.inboxList.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		if index < 0 || index >= len(t.inboxBookmarks) {
+			return
+		}
+		bookmark := t.inboxBookmarks[index]
+		if bookmark.ReadState == model.ReadStateUnread {
+			if updated := t.setReadState(bookmark, model.ReadStateReading); updated != nil {
+				bookmark = updated
+			}
+		}
+		t.viewBookmark(bookmark)
+	})
+
+	t.inboxList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'o' {
+			if t.inboxOrder == repository.SortOldest {
+				t.inboxOrder = repository.SortPriority
+			} else {
+				t.inboxOrder = repository.SortOldest
+			}
+			t.loadInbox()
+			return nil
+		}
+
+		index := t.inboxList.GetCurrentItem()
+		if index < 0 || index >= len(t.inboxBookmarks) {
+			return event
+		}
+		bookmark := t.inboxBookmarks[index]
+		switch event.Rune() {
+		case 'r':
+			t.toggleRead(bookmark)
+		case 'e':
+			t.setReadState(bookmark, model.ReadStateArchived)
+		case '+', '-':
+			priority := bookmark.Priority + 1
+			if event.Rune() == '-' {
+				priority = bookmark.Priority - 1
+			}
+			if err := t.bookmarkService.SetPriority(bookmark.ID, priority); err != nil {
+				t.setStatus(fmt.Sprintf("[red]Failed to set priority: %v[white]", err))
+				return nil
+			}
+			t.setStatus(fmt.Sprintf("[green]Priority %d[white]", priority))
+		default:
+			return event
+		}
+		t.loadInbox()
+		return nil
+	})
+}
+
+// loadInbox fills the inbox, keeping the current position.
+func (t *TUI) loadInbox() {
+	var err error
+
+	current := t.inboxList.GetCurrentItem()
+	t.inboxList.Clear()
+	t.inboxBookmarks, err = t.bookmarkService.Inbox(t.inboxOrder, "", -1)
+	if err != nil {
+		t.setStatus(fmt.Sprintf("[red]Failed to load inbox: %v[white]", err))
+		return
+	}
+
+	for _, bookmark := range t.inboxBookmarks {
+		title, secondaryText := bookmarkListText(bookmark)
+		if bookmark.Priority != 0 {
+			secondaryText = fmt.Sprintf("priority %d | %s", bookmark.Priority, secondaryText)
+		}
+		t.inboxList.AddItem(t.domainGlyph(bookmark)+bookmarkBadges(bookmark)+tview.Escape(title), secondaryText, 0, nil)
+	}
+	if current < len(t.inboxBookmarks) {
+		t.inboxList.SetCurrentItem(current)
+	}
+
+	order := "oldest first"
+	if t.inboxOrder == repository.SortPriority {
+		order = "by priority"
+	}
+	t.inboxList.SetTitle(fmt.Sprintf(" Inbox - %d to read, %s (r: read, e: archive, +/-: priority, o: order) ", len(t.inboxBookmarks), order))
+}
+
+// setReadState moves a bookmark to a read state and returns it updated, or
+// nil if that failed.
+func (t *TUI) setReadState(bookmark *model.Bookmark, state string) *model.Bookmark {
+	updated, err := t.bookmarkService.SetReadState(bookmark.ID, state)
+	if err != nil {
+		t.setStatus(fmt.Sprintf("[red]Failed to mark bookmark %s: %v[white]", state, err))
+		return nil
+	}
+	t.setStatus(fmt.Sprintf("[green]Marked %s[white]", state))
+	return updated
+}
+
+// toggleRead marks a bookmark read, or unread again if it was read.
+func (t *TUI) toggleRead(bookmark *model.Bookmark) *model.Bookmark {
+	if bookmark.ReadState == model.ReadStateRead {
+		return t.setReadState(bookmark, model.ReadStateUnread)
+	}
+	return t.setReadState(bookmark, model.ReadStateRead)
+}
+
+// markCurrent changes the bookmark under the cursor in the bookmark list,
+// with change returning it updated or nil. Pinning or unpinning it reloads
+// the list, which has pinned bookmarks first, keeping the cursor on it.
+func (t *TUI) markCurrent(change func(*model.Bookmark) *model.Bookmark) {
+	index := t.bookmarkList.GetCurrentItem()
+	if index < 0 || index >= len(t.currentBookmarks) {
+		return
+	}
+	bookmark := t.currentBookmarks[index]
+	updated := change(bookmark)
+	if updated == nil {
+		return
+	}
+	if updated.Pinned == bookmark.Pinned {
+		t.currentBookmarks[index] = updated
+		title, secondaryText := t.bookmarkItemText(updated)
+		t.bookmarkList.SetItemText(index, title, secondaryText)
+		return
+	}
+
+	status := t.statusBar.GetText(false)
+	t.loadBookmarksWith(t.listOptions)
+	for i, b := range t.currentBookmarks {
+		if b.ID == updated.ID {
+			t.bookmarkList.SetCurrentItem(i)
+			break
+		}
+	}
+	t.setStatus(status)
+}
+
+// saveReadProgress records how far the content of the current bookmark has
+// been scrolled. Progress never goes back, so rereading the start of a
+// bookmark keeps its place.
+func (t *TUI) saveReadProgress() {
+	bookmark := t.currentBookmark
+	lines := t.contentView.GetWrappedLineCount()
+	if bookmark == nil || bookmark.Content == "" || lines == 0 {
+		return
+	}
+	row, _ := t.contentView.GetScrollOffset()
+	_, _, _, height := t.contentView.GetInnerRect()
+	progress := min((row+height)*100/lines, 100)
+	if progress <= bookmark.ReadProgress {
+		return
+	}
+
+	updated, err := t.bookmarkService.SetReadProgress(bookmark.ID, progress)
+	if err != nil {
+		t.setStatus(fmt.Sprintf("[red]Failed to save reading progress: %v[white]", err))
+		return
+	}
+	t.renderDetails(updated)
+}
+
+func formatReadState(bookmark *model.Bookmark) string {
+	var state string
+	switch bookmark.ReadState {
+	case model.ReadStateReading:
+		state = fmt.Sprintf("[yellow]reading, %d%%[white]", bookmark.ReadProgress)
+	case model.ReadStateRead:
+		state = "[green]read[white]"
+		if bookmark.ReadAt != nil {
+			state += " on " + bookmark.ReadAt.Format("2006-01-02 15:04")
+		}
+	case model.ReadStateArchived:
+		state = "[gray]archived[white]"
+	default:
+		state = "unread"
+	}
+	if bookmark.Priority != 0 {
+		state += fmt.Sprintf(", priority %d", bookmark.Priority)
+	}
+	return state
+}
@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/san-kum/bookmarker/internal/model"
+)
+
+func (t *TUI) setupNotes() {
+	t.notesArea = tview.NewTextArea().
+		SetPlaceholder("Write notes in Markdown...")
+	t.notesArea.SetBorder(true).SetTitle(" Notes (Ctrl-S: save) ")
+
+	// Notes are saved with Ctrl-S and whenever the text area loses focus,
+	// including when leaving the page.
+	t.notesArea.SetBlurFunc(t.saveNotes)
+	t.notesArea.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyCtrlS {
+			t.saveNotes()
+			return nil
+		}
+		return event
+	})
+
+	t.annotationList = tview.NewList().ShowSecondaryText(true)
+	t.annotationList.SetBorder(true).SetTitle(" Annotations (a: add, Enter: edit) ")
+}
+
+// renderNotes shows a bookmark's notes and annotations. Notes being typed
+// are left alone when the bookmark is redrawn after a background fetch.
+func (t *TUI) renderNotes(bookmark *model.Bookmark) {
+	if !t.notesArea.HasFocus() {
+		t.notesArea.SetText(bookmark.Notes, false)
+	}
+
+	current := t.annotationList.GetCurrentItem()
+	t.annotationList.Clear()
+	for _, annotation := range bookmark.Annotations {
+		annotation := annotation
+		text := strings.ReplaceAll(annotation.Text, "\n", " ")
+		t.annotationList.AddItem(tview.Escape(text), annotation.CreatedAt.Local().Format("2006-01-02 15:04"), 0, func() {
+			t.promptAnnotation(&annotation)
+		})
+	}
+	if current < t.annotationList.GetItemCount() {
+		t.annotationList.SetCurrentItem(current)
+	}
+}
+
+func (t *TUI) saveNotes() {
+	bookmark := t.currentBookmark
+	if bookmark == nil {
+		return
+	}
+	notes := strings.TrimSpace(t.notesArea.GetText())
+	if notes == bookmark.Notes {
+		return
+	}
+	if err := t.bookmarkService.SetNotes(bookmark.ID, notes); err != nil {
+		t.setStatus(fmt.Sprintf("[red]Failed to save notes: %v[white]", err))
+		return
+	}
+	bookmark.Notes = notes
+	t.setStatus("[green]Notes saved[white]")
+}
+
+// promptAnnotation asks for the text of a new annotation, or of annotation
+// if it is not nil. Clearing an existing annotation's text deletes it.
+func (t *TUI) promptAnnotation(annotation *model.Annotation) {
+	bookmark := t.currentBookmark
+	if bookmark == nil {
+		return
+	}
+
+	title, value := "New Annotation", ""
+	if annotation != nil {
+		title, value = "Edit Annotation", annotation.Text
+	}
+	t.prompt(title, "Annotation: ", value, t.annotationList, func(text string) {
+		var err error
+		switch {
+		case annotation != nil:
+			err = t.bookmarkService.UpdateAnnotation(annotation.ID, text)
+		case strings.TrimSpace(text) != "":
+			_, err = t.bookmarkService.AddAnnotation(bookmark.ID, text)
+		default:
+			return
+		}
+		if err != nil {
+			t.setStatus(fmt.Sprintf("[red]Failed to save annotation: %v[white]", err))
+			return
+		}
+
+		updated, err := t.bookmarkService.Get(bookmark.ID)
+		if err != nil || updated == nil {
+			return
+		}
+		t.renderBookmark(updated)
+		t.setStatus("[green]Annotation saved[white]")
+	})
+}
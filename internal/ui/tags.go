@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/san-kum/bookmarker/internal/repository"
+)
+
+func (t *TUI) setupTagsPage() {
+	t.tagManageList = tview.NewList().
+		SetSecondaryTextColor(tcell.ColorDimGray)
+	t.tagManageList.SetBorder(true).SetTitle(" Tags ")
+	t.selectedTags = make(map[string]bool)
+
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[yellow]Enter[white]: Show bookmarks | [yellow]Space[white]: Select | [yellow]r[white]: Rename | [yellow]m[white]: Merge | [yellow]d[white]: Delete | [yellow]p[white]: Prune unused")
+
+	t.tagsPage = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(t.tagManageList, 0, 1, true).
+		AddItem(help, 1, 0, false).
+		AddItem(t.statusBar, 1, 0, false).
+		AddItem(t.helpBar, 1, 0, false)
+
+	t.tagManageList.SetSelectedFunc(func(_ int, _ string, name string, _ rune) {
+		t.loadBookmarks(name)
+		t.filterInput.SetText(name)
+		t.showPage("bookmarkList")
+	})
+
+	t.tagManageList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if t.tagManageList.GetItemCount() == 0 {
+			return event
+		}
+		index := t.tagManageList.GetCurrentItem()
+		_, name := t.tagManageList.GetItemText(index)
+
+		switch event.Rune() {
+		case ' ':
+			if t.selectedTags[name] {
+				delete(t.selectedTags, name)
+			} else {
+				t.selectedTags[name] = true
+			}
+			t.tagManageList.SetItemText(index, t.tagItemText(name), name)
+			if index+1 < t.tagManageList.GetItemCount() {
+				t.tagManageList.SetCurrentItem(index + 1)
+			}
+		case 'r':
+			t.prompt("Rename tag", "New name: ", name, t.tagManageList, func(newName string) {
+				t.runTagChange(t.bookmarkService.RenameTag(name, newName), fmt.Sprintf("Renamed %s to %s", name, newName))
+			})
+		case 'm':
+			sources := t.selectedTagNames()
+			if len(sources) == 0 {
+				sources = []string{name}
+			}
+			t.prompt("Merge tags", "Merge into: ", name, t.tagManageList, func(target string) {
+				t.runTagChange(t.bookmarkService.MergeTags(sources, target),
+					fmt.Sprintf("Merged %s into %s", strings.Join(sources, ", "), target))
+			})
+		case 'd':
+			names := t.selectedTagNames()
+			if len(names) == 0 {
+				names = []string{name}
+			}
+			text := fmt.Sprintf("Delete %s from every bookmark?", strings.Join(names, ", "))
+			t.confirm(text, "Delete", t.tagManageList, func() {
+				for _, name := range names {
+					if err := t.bookmarkService.DeleteTag(name); err != nil {
+						t.runTagChange(err, "")
+						return
+					}
+				}
+				t.runTagChange(nil, fmt.Sprintf("Deleted %s", strings.Join(names, ", ")))
+			})
+		case 'p':
+			names, err := t.bookmarkService.PruneTags()
+			t.runTagChange(err, fmt.Sprintf("Pruned %d unused tags", len(names)))
+		default:
+			return event
+		}
+		return nil
+	})
+}
+
+// loadTagManageList lists every tag with the number of bookmarks carrying
+// it. The tag name is kept as the secondary text so it survives the
+// selection marker.
+func (t *TUI) loadTagManageList() {
+	t.tagManageList.Clear()
+	t.selectedTags = make(map[string]bool)
+
+	tags, err := t.bookmarkService.GetAllTags()
+	if err != nil {
+		t.setStatus(fmt.Sprintf("[red]Failed to load tags: %v[white]", err))
+		return
+	}
+	t.tagCounts, err = t.bookmarkService.TagCounts()
+	if err != nil {
+		t.setStatus(fmt.Sprintf("[red]Failed to load tags: %v[white]", err))
+		return
+	}
+
+	for _, tag := range tags {
+		t.tagManageList.AddItem(t.tagItemText(tag.Name), tag.Name, 0, nil)
+	}
+	t.tagManageList.ShowSecondaryText(false)
+	t.tagManageList.SetTitle(fmt.Sprintf(" Tags (%d) ", len(tags)))
+}
+
+func (t *TUI) tagItemText(name string) string {
+	text := fmt.Sprintf("%-30s %5d", tview.Escape(name), t.tagCounts[name])
+	if t.tagCounts[name] == 0 {
+		text = "[gray]" + text + " unused[-]"
+	}
+	if t.selectedTags[name] {
+		text = "[yellow::b]*[-::-] " + text
+	} else {
+		text = "  " + text
+	}
+	return text
+}
+
+func (t *TUI) selectedTagNames() []string {
+	names := make([]string, 0, len(t.selectedTags))
+	for name := range t.selectedTags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runTagChange reports the outcome of a tag change and reloads the tag lists.
+func (t *TUI) runTagChange(err error, done string) {
+	if err != nil {
+		t.setStatus(fmt.Sprintf("[red]%v[white]", err))
+		return
+	}
+	t.loadTagManageList()
+	t.loadTags()
+	t.setStatus(fmt.Sprintf("[green]%s[white]", done))
+}
+
+// loadTags fills the tag panel of the bookmark list with a tree of the tags,
+// below entries for all bookmarks and for broken and moved links. Tags whose
+// children were expanded stay expanded.
+func (t *TUI) loadTags() {
+	var err error
+
+	current := ""
+	if node := t.tagTree.GetCurrentNode(); node != nil {
+		current, _ = node.GetReference().(string)
+	}
+	root := t.tagTree.GetRoot().ClearChildren()
+
+	t.currentTags, err = t.bookmarkService.GetAllTags()
+	if err != nil {
+		t.setStatus(fmt.Sprintf("[red]Failed to load tags: %v[white]", err))
+		return
+	}
+	t.tagCounts, err = t.bookmarkService.TagCounts()
+	if err != nil {
+		t.setStatus(fmt.Sprintf("[red]Failed to load tags: %v[white]", err))
+		return
+	}
+
+	root.AddChild(tview.NewTreeNode("All").SetSelectedFunc(func() {
+		t.loadBookmarks("")
+		t.filterInput.SetText("")
+	}))
+	root.AddChild(tview.NewTreeNode("Broken links").SetSelectedFunc(func() {
+		t.loadBookmarksWith(repository.ListOptions{Health: model.LinkHealthBroken})
+	}))
+	root.AddChild(tview.NewTreeNode("Moved links").SetSelectedFunc(func() {
+		t.loadBookmarksWith(repository.ListOptions{Health: model.LinkHealthMoved})
+	}))
+
+	// Tags are sorted by name, so parents come before their children.
+	nodes := make(map[string]*tview.TreeNode, len(t.currentTags))
+	for _, tag := range t.currentTags {
+		node := tview.NewTreeNode("").
+			SetReference(tag.Name).
+			SetExpanded(t.expandedTags[tag.Name])
+		nodes[tag.Name] = node
+		if parent, ok := nodes[model.ParentTagName(tag.Name)]; ok {
+			parent.AddChild(node)
+			node.SetIndent(2)
+		} else {
+			root.AddChild(node)
+		}
+	}
+	for name, node := range nodes {
+		node.SetText(t.tagNodeText(name, len(node.GetChildren()) > 0, node.IsExpanded()))
+	}
+
+	if node, ok := nodes[current]; ok {
+		t.tagTree.SetCurrentNode(node)
+	} else {
+		t.tagTree.SetCurrentNode(root.GetChildren()[0])
+	}
+}
+
+// tagNodeText shows the last level of a tag's name with the number of
+// bookmarks under it, and whether it has children to expand.
+func (t *TUI) tagNodeText(name string, hasChildren, expanded bool) string {
+	label := name[strings.LastIndex(name, model.TagSeparator)+1:]
+	return fmt.Sprintf("%s%s (%d)", treeMarker(hasChildren, expanded), label, t.tagCounts[name])
+}
+
+// treeMarker shows whether a tree node has children and whether they are
+// shown.
+func treeMarker(hasChildren, expanded bool) string {
+	switch {
+	case hasChildren && expanded:
+		return "▾ "
+	case hasChildren:
+		return "▸ "
+	default:
+		return "  "
+	}
+}
@@ -2,15 +2,21 @@ package ui
 
 import (
 	"fmt"
+	"hash/fnv"
+	"net/url"
 	"os/exec"
 	"runtime"
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
-	"github.com/sahilm/fuzzy"
 	"github.com/san-kum/bookmarker/internal/model"
+	"github.com/san-kum/bookmarker/internal/repository"
 	"github.com/san-kum/bookmarker/internal/service"
+	"github.com/san-kum/bookmarker/internal/service/archive"
+	"github.com/san-kum/bookmarker/internal/service/favicon"
+	"github.com/san-kum/bookmarker/internal/service/linkcheck"
+	"github.com/san-kum/bookmarker/internal/service/queue"
 	"github.com/san-kum/bookmarker/internal/service/search"
 )
 
@@ -18,35 +24,85 @@ type TUI struct {
 	app             *tview.Application
 	pages           *tview.Pages
 	bookmarkService *service.BookmarkService
+	collections     *service.CollectionService
 	searchService   *search.SearchService
+	linkChecker     *linkcheck.Checker
+	archiver        *archive.Archiver
+	favicons        *favicon.Cache
 
 	mainPage         *tview.Flex
 	bookmarkListPage *tview.Flex
 	searchPage       *tview.Flex
 	addBookmarkPage  *tview.Flex
 	viewBookmarkPage *tview.Flex
-
-	bookmarkList *tview.List
-	statusBar    *tview.TextView
-	helpBar      *tview.TextView
+	historyPage      *tview.Flex
+	tagsPage         *tview.Flex
+	passagePage      *tview.Flex
+	highlightsPage   *tview.Flex
+	inboxPage        *tview.Flex
+
+	bookmarkList   *tview.List
+	tagTree        *tview.TreeView
+	collectionTree *tview.TreeView
+	contentView    *tview.TextView
+	notesArea      *tview.TextArea
+	annotationList *tview.List
+	statusBar      *tview.TextView
+	helpBar        *tview.TextView
 
 	currentBookmarks []*model.Bookmark
+	selected         map[int64]bool
+	currentBookmark  *model.Bookmark
 	currentTags      []model.Tag
+	expandedTags     map[string]bool
+	listOptions      repository.ListOptions
+
+	expandedCollections map[int64]bool
+	collectionPaths     map[int64]string
+
+	tagManageList *tview.List
+	tagCounts     map[string]int
+	selectedTags  map[string]bool
+
+	versionList        *tview.List
+	diffView           *tview.TextView
+	currentVersions    []*model.ContentVersion
+	diffAgainstCurrent bool
+
+	passageArea      *tview.TextArea
+	passageList      *tview.List
+	highlightList    *tview.List
+	highlightFilter  *tview.InputField
+	listedHighlights []model.Highlight
+
+	inboxList      *tview.List
+	inboxBookmarks []*model.Bookmark
+	inboxOrder     string
 
 	filterInput     *tview.InputField
 	addBookmarkForm *tview.Form
 	urlInput        *tview.InputField
 	tagsInput       *tview.InputField
+	suggestionView  *tview.TextView
+
+	addSuggestions    []string
+	detailSuggestions []string
 }
 
-func NewTUI(bookmarkService *service.BookmarkService, searchService *search.SearchService) *TUI {
+func NewTUI(bookmarkService *service.BookmarkService, collections *service.CollectionService, searchService *search.SearchService, jobQueue *queue.Queue, linkChecker *linkcheck.Checker, archiver *archive.Archiver, favicons *favicon.Cache) *TUI {
 	tui := &TUI{
 		app:             tview.NewApplication(),
 		bookmarkService: bookmarkService,
+		collections:     collections,
 		searchService:   searchService,
+		linkChecker:     linkChecker,
+		archiver:        archiver,
+		favicons:        favicons,
+		selected:        make(map[int64]bool),
 	}
 
 	tui.setupUI()
+	jobQueue.OnDone(tui.onJobDone)
 
 	return tui
 }
@@ -61,17 +117,31 @@ func (t *TUI) setupUI() {
 	t.setupSearchPage()
 	t.setupAddBookmarkPage()
 	t.setupViewBookmarkPage()
+	t.setupHistoryPage()
+	t.setupTagsPage()
+	t.setupPassagePage()
+	t.setupHighlightsPage()
+	t.setupInboxPage()
 
 	t.pages.AddPage("main", t.mainPage, true, true)
 	t.pages.AddPage("bookmarkList", t.bookmarkListPage, true, false)
 	t.pages.AddPage("search", t.searchPage, true, false)
 	t.pages.AddPage("addBookmark", t.addBookmarkPage, true, false)
 	t.pages.AddPage("viewBookmark", t.viewBookmarkPage, true, false)
+	t.pages.AddPage("history", t.historyPage, true, false)
+	t.pages.AddPage("tags", t.tagsPage, true, false)
+	t.pages.AddPage("passages", t.passagePage, true, false)
+	t.pages.AddPage("highlights", t.highlightsPage, true, false)
+	t.pages.AddPage("inbox", t.inboxPage, true, false)
 
 	t.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
 		case tcell.KeyEscape:
 			currentPage, _ := t.pages.GetFrontPage()
+			if currentPage == "prompt" || currentPage == "confirm" {
+				// Dialogs handle Escape themselves.
+				return event
+			}
 			if currentPage != "main" {
 				t.showPage("main")
 				return nil
@@ -80,6 +150,10 @@ func (t *TUI) setupUI() {
 			t.app.Stop()
 			return nil
 		}
+		switch t.app.GetFocus().(type) {
+		case *tview.InputField, *tview.TextArea:
+			return event
+		}
 		switch event.Rune() {
 		case 'n':
 			t.showPage("addBookmark")
@@ -128,12 +202,24 @@ func (t *TUI) setupMainPage() {
 			t.loadBookmarks("")
 			t.showPage("bookmarkList")
 		}).
+		AddItem("Inbox", "Bookmarks to read later", 'i', func() {
+			t.loadInbox()
+			t.showPage("inbox")
+		}).
 		AddItem("Search", "Search your bookmarks", 's', func() {
 			t.showPage("search")
 		}).
 		AddItem("Add Bookmark", "Add a new bookmark", 'a', func() {
 			t.showPage("addBookmark")
 		}).
+		AddItem("Manage Tags", "Rename, merge and delete tags", 't', func() {
+			t.loadTagManageList()
+			t.showPage("tags")
+		}).
+		AddItem("Highlights", "Passages highlighted in your bookmarks", 'h', func() {
+			t.loadHighlights()
+			t.showPage("highlights")
+		}).
 		AddItem("Quit", "Exit the application", 'q', func() {
 			t.app.Stop()
 		})
@@ -152,25 +238,36 @@ func (t *TUI) setupBookmarkListPage() {
 		SetSecondaryTextColor(tcell.ColorDimGray)
 	t.bookmarkList.SetBorder(true).SetTitle(" Bookmarks ")
 
-	filterInput := tview.NewInputField().
-		SetLabel("Filter by tag: ").
+	// The filter takes a tag name or the filter terms of the search syntax,
+	// e.g. "lang:de readtime:<10 sort:readtime".
+	t.filterInput = tview.NewInputField().
+		SetLabel("Filter: ").
 		SetFieldWidth(20).
 		SetDoneFunc(func(key tcell.Key) {
 			if key == tcell.KeyEnter {
-				tag := t.filterInput.GetText()
-				t.loadBookmarks(tag)
+				query, err := search.ParseQuery(t.filterInput.GetText())
+				if err != nil {
+					t.setStatus(fmt.Sprintf("[red]%v[white]", err))
+					return
+				}
+				t.loadBookmarksWith(query.ListOptions())
 			}
 		})
 
-	tagList := tview.NewList().
-		SetSecondaryTextColor(tcell.ColorDimGray)
-	tagList.SetBorder(true).SetTitle(" Tags ")
+	t.tagTree = tview.NewTreeView().
+		SetRoot(tview.NewTreeNode("")).
+		SetTopLevel(1).
+		SetGraphics(false)
+	t.tagTree.SetBorder(true).SetTitle(" Tags ")
+	t.expandedTags = make(map[string]bool)
 
-	t.loadTags(tagList)
+	t.loadTags()
+	t.setupCollectionTree()
 
 	rightPanel := tview.NewFlex().SetDirection(tview.FlexRow).
-		AddItem(filterInput, 1, 0, false).
-		AddItem(tagList, 0, 1, false)
+		AddItem(t.filterInput, 1, 0, false).
+		AddItem(t.collectionTree, 0, 1, false).
+		AddItem(t.tagTree, 0, 1, false)
 
 	// Create layout
 	t.bookmarkListPage = tview.NewFlex().SetDirection(tview.FlexRow).
@@ -187,9 +284,80 @@ func (t *TUI) setupBookmarkListPage() {
 		}
 	})
 
-	tagList.SetSelectedFunc(func(index int, mainText string, _ string, _ rune) {
-		t.loadBookmarks(mainText)
-		t.filterInput.SetText(mainText)
+	t.bookmarkListPage.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			t.switchFocus(t.bookmarkList, t.filterInput, t.collectionTree, t.tagTree)
+			return nil
+		}
+		return event
+	})
+
+	// Space marks bookmarks for merging; m merges the marked ones. c files
+	// the current bookmark in a collection, and J and K move it within the
+	// collection being shown. r marks it read or unread, e archives it. s
+	// stars it, p pins it and 0 to 5 rate it.
+	t.bookmarkList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if rating, ok := ratingKey(event); ok {
+			t.markCurrent(func(bookmark *model.Bookmark) *model.Bookmark {
+				return t.rate(bookmark, rating)
+			})
+			return nil
+		}
+		switch event.Rune() {
+		case 's':
+			t.markCurrent(t.toggleStar)
+			return nil
+		case 'p':
+			t.markCurrent(t.togglePin)
+			return nil
+		case 'r':
+			t.markCurrent(t.toggleRead)
+			return nil
+		case 'e':
+			t.markCurrent(func(bookmark *model.Bookmark) *model.Bookmark {
+				return t.setReadState(bookmark, model.ReadStateArchived)
+			})
+			return nil
+		case 'c':
+			t.promptCollection()
+			return nil
+		case 'J':
+			t.moveInCollection(1)
+			return nil
+		case 'K':
+			t.moveInCollection(-1)
+			return nil
+		case ' ':
+			t.toggleSelected(t.bookmarkList.GetCurrentItem())
+			return nil
+		case 'm':
+			t.confirmMerge()
+			return nil
+		}
+		return event
+	})
+
+	// Enter filters by a tag and its descendants; Space expands or collapses
+	// a tag's children.
+	t.tagTree.SetSelectedFunc(func(node *tview.TreeNode) {
+		if name, ok := node.GetReference().(string); ok {
+			t.loadBookmarks(name)
+			t.filterInput.SetText(name)
+		}
+	})
+	t.tagTree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() != ' ' {
+			return event
+		}
+		node := t.tagTree.GetCurrentNode()
+		name, ok := node.GetReference().(string)
+		if !ok || len(node.GetChildren()) == 0 {
+			return nil
+		}
+		t.expandedTags[name] = !node.IsExpanded()
+		node.SetExpanded(t.expandedTags[name])
+		node.SetText(t.tagNodeText(name, true, node.IsExpanded()))
+		return nil
 	})
 }
 
@@ -225,20 +393,15 @@ func (t *TUI) setupSearchPage() {
 func (t *TUI) setupAddBookmarkPage() {
 	t.urlInput = tview.NewInputField().SetLabel("URL").SetFieldWidth(40)
 	t.tagsInput = tview.NewInputField().SetLabel("Tags (comma separated)").SetFieldWidth(40)
+	t.suggestionView = tview.NewTextView().SetDynamicColors(true)
+	t.urlInput.SetChangedFunc(func(string) { t.updateAddSuggestions() })
+	t.tagsInput.SetChangedFunc(func(string) { t.updateAddSuggestions() })
 	t.addBookmarkForm = tview.NewForm().
 		AddFormItem(t.urlInput).
 		AddFormItem(t.tagsInput).
 		AddButton("Add", func() {
 			url := t.urlInput.GetText()
-			tagsStr := t.tagsInput.GetText()
-
-			tags := []string{}
-			if tagsStr != "" {
-				tags = strings.Split(tagsStr, ",")
-				for i, tag := range tags {
-					tags[i] = strings.TrimSpace(tag)
-				}
-			}
+			tags := parseTags(t.tagsInput.GetText())
 
 			t.addBookmark(url, tags)
 
@@ -249,10 +412,72 @@ func (t *TUI) setupAddBookmarkPage() {
 			t.showPage("main")
 		})
 
+	t.addBookmarkForm.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if i, ok := suggestionKey(event); ok && i < len(t.addSuggestions) {
+			tags := append(parseTags(t.tagsInput.GetText()), t.addSuggestions[i])
+			t.tagsInput.SetText(strings.Join(tags, ", "))
+			return nil
+		}
+		return event
+	})
+
 	t.addBookmarkForm.SetBorder(true).SetTitle(" Add Bookmark ")
 
-	t.addBookmarkPage = tview.NewFlex().SetDirection(tview.FlexRow).AddItem(nil, 0, 1, false).AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).AddItem(nil, 0, 1, false).AddItem(t.addBookmarkForm, 0, 2, true).AddItem(nil, 0, 1, false), 0, 2, true).AddItem(nil, 0, 1, false).AddItem(t.statusBar, 1, 0, false).AddItem(t.helpBar, 1, 0, false)
+	addColumn := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(t.addBookmarkForm, 0, 1, true).
+		AddItem(t.suggestionView, 2, 0, false)
+
+	t.addBookmarkPage = tview.NewFlex().SetDirection(tview.FlexRow).AddItem(nil, 0, 1, false).AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).AddItem(nil, 0, 1, false).AddItem(addColumn, 0, 2, true).AddItem(nil, 0, 1, false), 0, 2, true).AddItem(nil, 0, 1, false).AddItem(t.statusBar, 1, 0, false).AddItem(t.helpBar, 1, 0, false)
+
+}
+
+// updateAddSuggestions refreshes the tag suggestions for the URL and tags
+// being entered in the add form.
+func (t *TUI) updateAddSuggestions() {
+	t.addSuggestions = nil
+	t.suggestionView.SetText("")
+
+	url := strings.TrimSpace(t.urlInput.GetText())
+	if !strings.Contains(url, "://") {
+		return
+	}
+	suggestions, err := t.bookmarkService.SuggestTagsForURL(url, parseTags(t.tagsInput.GetText()), 9)
+	if err != nil || len(suggestions) == 0 {
+		return
+	}
+	for _, suggestion := range suggestions {
+		t.addSuggestions = append(t.addSuggestions, suggestion.Tag)
+	}
+	t.suggestionView.SetText(formatSuggestions(t.addSuggestions))
+}
+
+// suggestionKey maps Alt+1..Alt+9 to a suggestion index.
+func suggestionKey(event *tcell.EventKey) (int, bool) {
+	if event.Key() != tcell.KeyRune || event.Modifiers()&tcell.ModAlt == 0 {
+		return 0, false
+	}
+	if r := event.Rune(); r >= '1' && r <= '9' {
+		return int(r - '1'), true
+	}
+	return 0, false
+}
+
+func formatSuggestions(tags []string) string {
+	parts := make([]string, len(tags))
+	for i, tag := range tags {
+		parts[i] = fmt.Sprintf("[yellow]%d[white]:%s", i+1, tview.Escape(tag))
+	}
+	return "Suggested (Alt+number to add): " + strings.Join(parts, "  ")
+}
 
+func parseTags(s string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
 }
 
 // helper for opening URL
@@ -282,11 +507,14 @@ func (t *TUI) setupViewBookmarkPage() {
 		SetWordWrap(true)
 	bookmarkDetails.SetBorder(true).SetTitle(" Bookmark Details ")
 
-	contentView := tview.NewTextView().
+	t.contentView = tview.NewTextView().
 		SetDynamicColors(true).
 		SetRegions(true).
 		SetWordWrap(true)
-	contentView.SetBorder(true).SetTitle(" Content ")
+	t.contentView.SetBorder(true).SetTitle(" Content ")
+	t.contentView.SetBlurFunc(t.saveReadProgress)
+
+	t.setupNotes()
 
 	buttonBar := tview.NewFlex().SetDirection(tview.FlexColumn)
 	backButton := tview.NewButton("Back").SetSelectedFunc(func() {
@@ -294,8 +522,8 @@ func (t *TUI) setupViewBookmarkPage() {
 	})
 
 	openButton := tview.NewButton("Open").SetSelectedFunc(func() {
-		bookmark := t.currentBookmarks[0]
-		if bookmark.URL == "" {
+		bookmark := t.currentBookmark
+		if bookmark == nil || bookmark.URL == "" {
 			t.setStatus("[red]No URL to open[white]")
 			return
 		}
@@ -320,18 +548,85 @@ func (t *TUI) setupViewBookmarkPage() {
 		t.showPage("bookmarkList")
 	})
 
+	refreshButton := tview.NewButton("Refresh").SetSelectedFunc(func() {
+		if t.currentBookmark == nil {
+			return
+		}
+		if err := t.bookmarkService.QueueRefresh(t.currentBookmark.ID); err != nil {
+			t.setStatus(fmt.Sprintf("[red]Failed to schedule refresh: %v[white]", err))
+			return
+		}
+		t.setStatus("[green]Refreshing in the background...[white]")
+	})
+
+	archiveButton := tview.NewButton("Archive").SetSelectedFunc(func() {
+		if t.currentBookmark == nil {
+			return
+		}
+		if err := t.bookmarkService.QueueArchive(t.currentBookmark.ID); err != nil {
+			t.setStatus(fmt.Sprintf("[red]Failed to schedule archiving: %v[white]", err))
+			return
+		}
+		t.setStatus("[green]Saving an offline snapshot in the background...[white]")
+	})
+
+	openArchiveButton := tview.NewButton("Open Archive").SetSelectedFunc(func() {
+		if t.currentBookmark == nil {
+			return
+		}
+		snapshot, err := t.archiver.Latest(t.currentBookmark.ID)
+		if err != nil {
+			t.setStatus(fmt.Sprintf("[red]Failed to load archive: %v[white]", err))
+			return
+		}
+		if snapshot == nil {
+			t.setStatus("[yellow]No offline snapshot yet, use Archive to save one[white]")
+			return
+		}
+		path, err := t.archiver.RenderFile(snapshot)
+		if err != nil {
+			t.setStatus(fmt.Sprintf("[red]Failed to open archive: %v[white]", err))
+			return
+		}
+		openURL("file://" + path)
+		t.setStatus("[green]Opening offline snapshot...[white]")
+	})
+
+	historyButton := tview.NewButton("History").SetSelectedFunc(func() {
+		if t.currentBookmark != nil {
+			t.viewHistory(t.currentBookmark)
+		}
+	})
+
+	highlightButton := tview.NewButton("Highlight").SetSelectedFunc(func() {
+		if t.currentBookmark != nil {
+			t.viewPassages(t.currentBookmark)
+		}
+	})
+
 	editTagsButton := tview.NewButton("Edit Tags").SetSelectedFunc(func() {
 		t.setStatus("[yellow]Edit tags not implemented in this demo[white]")
 	})
 
 	buttonBar.AddItem(openButton, 0, 1, true).
 		AddItem(deleteButton, 0, 1, false).
+		AddItem(refreshButton, 0, 1, false).
+		AddItem(historyButton, 0, 1, false).
+		AddItem(highlightButton, 0, 1, false).
+		AddItem(archiveButton, 0, 1, false).
+		AddItem(openArchiveButton, 0, 1, false).
 		AddItem(editTagsButton, 0, 1, false).
 		AddItem(backButton, 0, 1, false)
 
+	notesPanel := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(t.notesArea, 0, 2, false).
+		AddItem(t.annotationList, 0, 1, false)
+
 	t.viewBookmarkPage = tview.NewFlex().SetDirection(tview.FlexRow).
-		AddItem(bookmarkDetails, 6, 0, true).
-		AddItem(contentView, 0, 1, false).
+		AddItem(bookmarkDetails, 14, 0, true).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(t.contentView, 0, 3, false).
+			AddItem(notesPanel, 0, 2, false), 0, 1, false).
 		AddItem(buttonBar, 1, 0, false).
 		AddItem(t.statusBar, 1, 0, false).
 		AddItem(t.helpBar, 1, 0, false)
@@ -339,13 +634,178 @@ func (t *TUI) setupViewBookmarkPage() {
 	t.viewBookmarkPage.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
 		case tcell.KeyTab:
-			t.switchFocus(bookmarkDetails, contentView)
+			t.switchFocus(bookmarkDetails, t.contentView, t.notesArea, t.annotationList)
+			return nil
+		}
+		if t.notesArea.HasFocus() {
+			return event
+		}
+		switch event.Rune() {
+		case 'a':
+			t.promptAnnotation(nil)
+			return nil
+		case 'h':
+			if t.currentBookmark != nil {
+				t.viewPassages(t.currentBookmark)
+			}
+			return nil
+		case 'r', 'e', 's', 'p':
+			if t.currentBookmark == nil {
+				return nil
+			}
+			var updated *model.Bookmark
+			switch event.Rune() {
+			case 'r':
+				updated = t.toggleRead(t.currentBookmark)
+			case 'e':
+				updated = t.setReadState(t.currentBookmark, model.ReadStateArchived)
+			case 's':
+				updated = t.toggleStar(t.currentBookmark)
+			case 'p':
+				updated = t.togglePin(t.currentBookmark)
+			}
+			if updated != nil {
+				t.renderDetails(updated)
+			}
+			return nil
+		}
+		if rating, ok := ratingKey(event); ok && t.currentBookmark != nil {
+			if updated := t.rate(t.currentBookmark, rating); updated != nil {
+				t.renderDetails(updated)
+			}
+			return nil
+		}
+		if i, ok := suggestionKey(event); ok && i < len(t.detailSuggestions) {
+			t.acceptSuggestion(t.detailSuggestions[i])
+			return nil
+		}
+		return event
+	})
+}
+
+func (t *TUI) acceptSuggestion(tag string) {
+	if t.currentBookmark == nil {
+		return
+	}
+	if err := t.bookmarkService.AddTag(t.currentBookmark.ID, tag); err != nil {
+		t.setStatus(fmt.Sprintf("[red]Failed to add tag: %v[white]", err))
+		return
+	}
+	bookmark, err := t.bookmarkService.Get(t.currentBookmark.ID)
+	if err != nil || bookmark == nil {
+		return
+	}
+	t.renderBookmark(bookmark)
+	t.setStatus(fmt.Sprintf("[green]Added tag %s[white]", tag))
+}
+
+func (t *TUI) setupHistoryPage() {
+	t.versionList = tview.NewList().
+		SetSecondaryTextColor(tcell.ColorDimGray)
+	t.versionList.SetBorder(true).SetTitle(" Versions ")
+
+	t.diffView = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(true)
+	t.diffView.SetBorder(true).SetTitle(" Diff ")
+
+	t.versionList.SetChangedFunc(func(index int, _ string, _ string, _ rune) {
+		t.showVersionDiff(index)
+	})
+
+	t.historyPage = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(t.versionList, 0, 1, true).
+			AddItem(t.diffView, 0, 3, false),
+			0, 1, true).
+		AddItem(t.statusBar, 1, 0, false).
+		AddItem(t.helpBar, 1, 0, false)
+
+	t.historyPage.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab:
+			t.switchFocus(t.versionList, t.diffView)
+			return nil
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			t.showPage("viewBookmark")
+			return nil
+		}
+		if event.Rune() == 'c' {
+			t.diffAgainstCurrent = !t.diffAgainstCurrent
+			t.showVersionDiff(t.versionList.GetCurrentItem())
 			return nil
 		}
 		return event
 	})
 }
 
+func (t *TUI) viewHistory(bookmark *model.Bookmark) {
+	versions, err := t.bookmarkService.ContentVersions(bookmark.ID)
+	if err != nil {
+		t.setStatus(fmt.Sprintf("[red]Failed to load history: %v[white]", err))
+		return
+	}
+	if len(versions) == 0 {
+		t.setStatus("[yellow]Content has not changed since it was first fetched[white]")
+		return
+	}
+
+	t.currentVersions = versions
+	t.diffAgainstCurrent = false
+	t.versionList.Clear()
+	for _, v := range versions {
+		t.versionList.AddItem(v.FetchedAt.Local().Format("2006-01-02 15:04"), v.ShortHash(), 0, nil)
+	}
+
+	t.versionList.SetTitle(fmt.Sprintf(" Versions - %s ", tview.Escape(bookmark.Title)))
+	t.showVersionDiff(0)
+	t.showPage("history")
+	t.app.SetFocus(t.versionList)
+	t.setStatus("[green]c[white]: toggle diff against next version / current content | [green]Backspace[white]: back")
+}
+
+// showVersionDiff shows the changes from the selected version to the version
+// that replaced it, or to the current content when diffAgainstCurrent is set.
+func (t *TUI) showVersionDiff(index int) {
+	if t.currentBookmark == nil || index < 0 || index >= len(t.currentVersions) {
+		return
+	}
+
+	var toID int64
+	if !t.diffAgainstCurrent && index > 0 {
+		toID = t.currentVersions[index-1].ID
+	}
+
+	text, err := t.bookmarkService.DiffVersions(t.currentBookmark.ID, t.currentVersions[index].ID, toID)
+	if err != nil {
+		t.setStatus(fmt.Sprintf("[red]Failed to diff versions: %v[white]", err))
+		return
+	}
+	if text == "" {
+		text = "No differences"
+	}
+
+	var colored strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		escaped := tview.Escape(line)
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			colored.WriteString("[::b]" + escaped + "[::-]")
+		case strings.HasPrefix(line, "@@"):
+			colored.WriteString("[aqua]" + escaped + "[white]")
+		case strings.HasPrefix(line, "+"):
+			colored.WriteString("[green]" + escaped + "[white]")
+		case strings.HasPrefix(line, "-"):
+			colored.WriteString("[red]" + escaped + "[white]")
+		default:
+			colored.WriteString(escaped)
+		}
+		colored.WriteString("\n")
+	}
+
+	t.diffView.SetText(colored.String()).ScrollToBeginning()
+}
+
 func (t *TUI) switchFocus(views ...tview.Primitive) {
 	for i, view := range views {
 		if t.app.GetFocus() == view {
@@ -358,63 +818,285 @@ func (t *TUI) switchFocus(views ...tview.Primitive) {
 }
 
 func (t *TUI) loadBookmarks(tag string) {
+	t.loadBookmarksWith(repository.ListOptions{Tag: tag})
+}
+
+func (t *TUI) loadBookmarksWith(opts repository.ListOptions) {
 	var err error
 
+	opts.Limit = 100
+	opts.PinnedFirst = true
+	t.listOptions = opts
 	t.bookmarkList.Clear()
-	t.currentBookmarks, err = t.bookmarkService.List(tag, 100, 0)
+	t.selected = make(map[int64]bool)
+	t.currentBookmarks, err = t.bookmarkService.Find(opts)
 	if err != nil {
 		t.setStatus(fmt.Sprintf("[red]Failed to load bookmarks: %v[white]", err))
 		return
 	}
 
 	for _, bookmark := range t.currentBookmarks {
-		title := bookmark.Title
-		if title == "" {
-			title = bookmark.URL
+		title, secondaryText := t.bookmarkItemText(bookmark)
+		t.bookmarkList.AddItem(title, secondaryText, 0, nil)
+	}
+
+	switch {
+	case opts.Lang != "" || opts.MinReadingTime > 0 || opts.MaxReadingTime != nil || len(opts.ReadStates) > 0 ||
+		opts.Starred || opts.Pinned || opts.MinRating > 0 || opts.MaxRating != nil:
+		t.bookmarkList.SetTitle(fmt.Sprintf(" Bookmarks - Filter: %s ", t.filterInput.GetText()))
+	case opts.Tag != "":
+		t.bookmarkList.SetTitle(fmt.Sprintf(" Bookmarks - Tag: %s ", opts.Tag))
+	case opts.Collection != 0:
+		t.bookmarkList.SetTitle(fmt.Sprintf(" Bookmarks - Collection: %s ", t.collectionPaths[opts.Collection]))
+	case opts.Health == model.LinkHealthBroken:
+		t.bookmarkList.SetTitle(" Bookmarks - Broken links ")
+	case opts.Health == model.LinkHealthMoved:
+		t.bookmarkList.SetTitle(" Bookmarks - Moved links ")
+	default:
+		t.bookmarkList.SetTitle(" Bookmarks ")
+	}
+
+	t.setStatus(fmt.Sprintf("[green]Loaded %d bookmarks[white]", len(t.currentBookmarks)))
+}
+
+func bookmarkListText(bookmark *model.Bookmark) (string, string) {
+	title := bookmark.Title
+	if title == "" {
+		title = bookmark.URL
+	}
+
+	var tagNames []string
+	for _, tag := range bookmark.Tags {
+		tagNames = append(tagNames, tag.Name)
+	}
+	secondaryText := tview.Escape(strings.Join(tagNames, ", "))
+	if secondaryText == "" {
+		secondaryText = "No tags"
+	}
+	if bookmark.Rating > 0 {
+		secondaryText = ratingStars(bookmark.Rating) + " | " + secondaryText
+	}
+	if bookmark.ReadingTime > 0 {
+		details := fmt.Sprintf("%d min", bookmark.ReadingTime)
+		if bookmark.Lang != "" {
+			details += ", " + bookmark.Lang
 		}
+		secondaryText = details + " | " + secondaryText
+	}
+
+	// Labels are escaped so they are not taken for color tags.
+	switch bookmark.ReadState {
+	case model.ReadStateReading:
+		secondaryText = tview.Escape(fmt.Sprintf("[reading %d%%] ", bookmark.ReadProgress)) + secondaryText
+	case model.ReadStateRead:
+		secondaryText = tview.Escape("[read] ") + secondaryText
+	case model.ReadStateArchived:
+		secondaryText = tview.Escape("[archived] ") + secondaryText
+	}
+
+	switch bookmark.FetchState {
+	case model.FetchStatePending:
+		secondaryText = tview.Escape("[fetching] ") + secondaryText
+	case model.FetchStateFailed:
+		secondaryText = tview.Escape("[fetch failed] ") + secondaryText
+	}
+
+	return title, secondaryText
+}
+
+// bookmarkItemText returns the bookmark list texts of bookmark, marked when
+// it is selected for merging.
+func (t *TUI) bookmarkItemText(bookmark *model.Bookmark) (string, string) {
+	title, secondaryText := bookmarkListText(bookmark)
+	title = t.domainGlyph(bookmark) + bookmarkBadges(bookmark) + tview.Escape(title)
+	if t.selected[bookmark.ID] {
+		title = "[yellow::b]*[-::-] " + title
+	}
+	return title, secondaryText
+}
+
+func (t *TUI) toggleSelected(index int) {
+	if index < 0 || index >= len(t.currentBookmarks) {
+		return
+	}
+	bookmark := t.currentBookmarks[index]
+	if t.selected[bookmark.ID] {
+		delete(t.selected, bookmark.ID)
+	} else {
+		t.selected[bookmark.ID] = true
+	}
+	title, secondaryText := t.bookmarkItemText(bookmark)
+	t.bookmarkList.SetItemText(index, title, secondaryText)
+	t.setStatus(fmt.Sprintf("[green]%d selected[white] | [yellow]Space[white]: Select | [yellow]m[white]: Merge", len(t.selected)))
+	if index+1 < t.bookmarkList.GetItemCount() {
+		t.bookmarkList.SetCurrentItem(index + 1)
+	}
+}
 
-		var tagNames []string
-		for _, tag := range bookmark.Tags {
-			tagNames = append(tagNames, tag.Name)
+// confirmMerge asks before merging the selected bookmarks into the oldest of
+// them.
+func (t *TUI) confirmMerge() {
+	var bookmarks []*model.Bookmark
+	for _, bookmark := range t.currentBookmarks {
+		if t.selected[bookmark.ID] {
+			bookmarks = append(bookmarks, bookmark)
 		}
-		secondaryText := strings.Join(tagNames, ", ")
-		if secondaryText == "" {
-			secondaryText = "No tags"
+	}
+	if len(bookmarks) < 2 {
+		t.setStatus("[yellow]Select at least two bookmarks to merge with Space[white]")
+		return
+	}
+
+	keep := bookmarks[0]
+	for _, bookmark := range bookmarks[1:] {
+		if bookmark.CreatedAt.Before(keep.CreatedAt) {
+			keep = bookmark
 		}
+	}
 
-		t.bookmarkList.AddItem(title, secondaryText, 0, nil)
+	t.confirm(fmt.Sprintf("Merge %d bookmarks into\n%s?", len(bookmarks), keep.URL), "Merge", t.bookmarkList, func() {
+		t.mergeBookmarks(keep, bookmarks)
+	})
+}
+
+// confirm shows a dialog asking to go ahead with an action and runs it if the
+// user agrees. Focus returns to focus either way.
+func (t *TUI) confirm(text, action string, focus tview.Primitive, run func()) {
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{action, "Cancel"}).
+		SetDoneFunc(func(_ int, label string) {
+			t.pages.RemovePage("confirm")
+			t.app.SetFocus(focus)
+			if label == action {
+				run()
+			}
+		})
+	t.pages.AddPage("confirm", modal, true, true)
+	t.app.SetFocus(modal)
+}
+
+// prompt asks for a line of text, prefilled with value, and passes it to done
+// unless the user cancels with Escape. Focus returns to focus either way.
+func (t *TUI) prompt(title, label, value string, focus tview.Primitive, done func(string)) {
+	input := tview.NewInputField().
+		SetLabel(label).
+		SetText(value)
+	input.SetBorder(true).SetTitle(" " + title + " ")
+	input.SetDoneFunc(func(key tcell.Key) {
+		t.pages.RemovePage("prompt")
+		t.app.SetFocus(focus)
+		if key == tcell.KeyEnter {
+			done(input.GetText())
+		}
+	})
+
+	// Center the input on top of the current page.
+	layout := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(input, 3, 0, true).
+			AddItem(nil, 0, 1, false), 60, 0, true).
+		AddItem(nil, 0, 1, false)
+	t.pages.AddPage("prompt", layout, true, true)
+	t.app.SetFocus(input)
+}
+
+func (t *TUI) mergeBookmarks(keep *model.Bookmark, bookmarks []*model.Bookmark) {
+	var merged int
+	for _, bookmark := range bookmarks {
+		if bookmark.ID == keep.ID {
+			continue
+		}
+		if _, err := t.bookmarkService.Merge(keep.ID, bookmark.ID); err != nil {
+			t.setStatus(fmt.Sprintf("[red]Failed to merge %s: %v[white]", bookmark.URL, err))
+			return
+		}
+		merged++
 	}
 
-	if tag != "" {
-		t.bookmarkList.SetTitle(fmt.Sprintf(" Bookmarks - Tag: %s ", tag))
+	if query, err := search.ParseQuery(t.filterInput.GetText()); err == nil {
+		t.loadBookmarksWith(query.ListOptions())
 	} else {
-		t.bookmarkList.SetTitle(" Bookmarks ")
+		t.loadBookmarks("")
 	}
+	t.setStatus(fmt.Sprintf("[green]Merged %d bookmarks into %s[white]", merged, keep.URL))
+}
 
-	t.setStatus(fmt.Sprintf("[green]Loaded %d bookmarks[white]", len(t.currentBookmarks)))
+// glyphColors are used for sites whose icon gives no color.
+var glyphColors = []tcell.Color{
+	tcell.ColorRed, tcell.ColorGreen, tcell.ColorYellow, tcell.ColorBlue,
+	tcell.ColorFuchsia, tcell.ColorAqua, tcell.ColorOrange, tcell.ColorMediumPurple,
+	tcell.ColorSpringGreen, tcell.ColorHotPink, tcell.ColorDeepSkyBlue, tcell.ColorGold,
 }
 
-func (t *TUI) loadTags(tagList *tview.List) {
-	var err error
+// domainGlyph returns the first letter of the bookmark's site, colored like
+// the site's favicon, so bookmarks from different sites are easy to tell
+// apart in a list. Sites without a usable icon get a color derived from
+// their host name.
+func (t *TUI) domainGlyph(bookmark *model.Bookmark) string {
+	u, err := url.Parse(bookmark.URL)
+	if err != nil || u.Hostname() == "" {
+		return "  "
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	letter := strings.ToUpper(string([]rune(host)[0]))
 
-	tagList.Clear()
+	var color tcell.Color
+	if c, ok := t.favicons.Color(bookmark.Favicon); ok {
+		color = tcell.NewRGBColor(int32(c.R), int32(c.G), int32(c.B))
+	} else {
+		h := fnv.New32a()
+		h.Write([]byte(host))
+		color = glyphColors[h.Sum32()%uint32(len(glyphColors))]
+	}
+	return fmt.Sprintf("[#%06x::b]%s[-::-] ", color.Hex(), letter)
+}
 
-	t.currentTags, err = t.bookmarkService.GetAllTags()
-	if err != nil {
-		t.setStatus(fmt.Sprintf("[red]Failed to load tags: %v[white]", err))
+// onJobDone is called from a queue worker once a background job finishes. It
+// refreshes any on-screen copy of the affected bookmark.
+func (t *TUI) onJobDone(job *model.Job) {
+	bookmark, err := t.bookmarkService.Get(job.BookmarkID)
+	if err != nil || bookmark == nil {
 		return
 	}
 
-	tagList.AddItem("All", "Show all bookmarks", 0, nil)
+	t.app.QueueUpdateDraw(func() {
+		for i, b := range t.currentBookmarks {
+			if b.ID != bookmark.ID {
+				continue
+			}
+			t.currentBookmarks[i] = bookmark
+			if i < t.bookmarkList.GetItemCount() {
+				title, secondaryText := t.bookmarkItemText(bookmark)
+				t.bookmarkList.SetItemText(i, title, secondaryText)
+			}
+		}
 
-	for _, tag := range t.currentTags {
-		tagList.AddItem(tag.Name, "", 0, nil)
-	}
+		if t.currentBookmark != nil && t.currentBookmark.ID == bookmark.ID {
+			t.renderBookmark(bookmark)
+		}
+
+		switch {
+		case job.Status == model.JobStatusFailed:
+			t.setStatus(fmt.Sprintf("[red]Failed to %s %s: %s[white]", job.Kind, bookmark.URL, tview.Escape(job.LastError)))
+		case job.Kind == model.JobKindArchive:
+			t.setStatus(fmt.Sprintf("[green]Archived: %s[white]", tview.Escape(bookmark.Title)))
+		default:
+			t.setStatus(fmt.Sprintf("[green]Fetched: %s[white]", tview.Escape(bookmark.Title)))
+		}
+	})
 }
 
+// highlightMatch escapes text for display and colors the occurrences of
+// query in it.
 func highlightMatch(text, query string) string {
-	text = strings.ReplaceAll(text, "[", "[[")
-	text = strings.ReplaceAll(text, "]", "]]")
+	text = tview.Escape(text)
+	if query == "" {
+		return text
+	}
+	query = tview.Escape(query)
 	return strings.ReplaceAll(text, query, fmt.Sprintf("[yellow]%s[white]", query))
 }
 
@@ -434,35 +1116,32 @@ func (t *TUI) search(query string, results *tview.List) {
 
 	results.Clear()
 
-	allBookmarks, err := t.bookmarkService.List("", 1000, 0)
+	bookmarks, err := t.searchService.Search(query, 100)
 	if err != nil {
 		t.setStatus(fmt.Sprintf("[red]Search failed: %v[white]", err))
 		return
 	}
 
-	// Perform fuzzy search
-	titles := []string{}
-	for _, b := range allBookmarks {
-		titles = append(titles, b.Title)
+	// Highlight the free text part of the query, not its filter terms.
+	text := query
+	if parsed, err := search.ParseQuery(query); err == nil {
+		text = parsed.Text
 	}
-	matches := fuzzy.Find(query, titles)
 
-	t.currentBookmarks = []*model.Bookmark{}
-	for _, match := range matches {
-		bookmark := allBookmarks[match.Index]
-		t.currentBookmarks = append(t.currentBookmarks, bookmark)
-
-		highlightedTitle := highlightMatch(bookmark.Title, query)
-		results.AddItem(highlightedTitle, bookmark.URL, 0, func() {
+	t.currentBookmarks = bookmarks
+	for _, bookmark := range bookmarks {
+		bookmark := bookmark
+		title, secondaryText := bookmarkListText(bookmark)
+		results.AddItem(t.domainGlyph(bookmark)+bookmarkBadges(bookmark)+highlightMatch(title, text), secondaryText, 0, func() {
 			t.openBookmark(bookmark)
 		})
 	}
 
 	results.SetTitle(fmt.Sprintf(" Search Results for '%s' ", query))
-	if len(matches) == 0 {
+	if len(bookmarks) == 0 {
 		t.setStatus("[red]No results found[white]")
 	} else {
-		t.setStatus(fmt.Sprintf("[green]Found %d results[white]", len(matches)))
+		t.setStatus(fmt.Sprintf("[green]Found %d results[white]", len(bookmarks)))
 	}
 
 	searchInput := t.searchPage.GetItem(0).(*tview.InputField)
@@ -483,45 +1162,144 @@ func (t *TUI) addBookmark(url string, tags []string) {
 		return
 	}
 
-	err = t.searchService.IndexBookmark(bookmark)
-	if err != nil {
-		t.setStatus(fmt.Sprintf("[yellow]Bookmark added but indexing failed: %v[white]", err))
-		return
+	if bookmark.IsPending() {
+		t.setStatus(fmt.Sprintf("[green]Added bookmark, fetching %s in the background[white]", bookmark.URL))
+	} else {
+		t.setStatus(fmt.Sprintf("[green]Added bookmark: %s[white]", tview.Escape(bookmark.Title)))
 	}
 
-	t.setStatus(fmt.Sprintf("[green]Added bookmark: %s[white]", bookmark.Title))
-
 	t.viewBookmark(bookmark)
 }
 
 func (t *TUI) viewBookmark(bookmark *model.Bookmark) {
+	t.renderBookmark(bookmark)
+
 	detailsView := t.viewBookmarkPage.GetItem(0).(*tview.TextView)
-	contentView := t.viewBookmarkPage.GetItem(1).(*tview.TextView)
+	t.app.SetFocus(detailsView)
+	t.showPage("viewBookmark")
+}
+
+func (t *TUI) renderBookmark(bookmark *model.Bookmark) {
+	t.renderDetails(bookmark)
+
+	t.contentView.SetText(fmt.Sprintf(
+		"[yellow]Summary:[white]\n%s\n\n"+
+			"[yellow]Content:[white]\n%s",
+		tview.Escape(bookmark.Summary),
+		markHighlights(bookmark.Content, bookmark.Highlights),
+	))
+	t.renderNotes(bookmark)
+}
 
+// renderDetails shows the details of bookmark, leaving its content and
+// notes, and where they are scrolled to, as they are.
+func (t *TUI) renderDetails(bookmark *model.Bookmark) {
+	t.currentBookmark = bookmark
+
+	detailsView := t.viewBookmarkPage.GetItem(0).(*tview.TextView)
 
 	detailsView.SetText(fmt.Sprintf(
 		"[yellow]Title:[white] %s\n"+
 			"[yellow]URL:[white] %s\n"+
 			"[yellow]Created:[white] %s\n"+
-			"[yellow]Tags:[white] %s\n\n"+
+			"[yellow]Status:[white] %s\n"+
+			"[yellow]Read:[white] %s\n"+
+			"[yellow]Rating:[white] %s\n"+
+			"[yellow]Link:[white] %s\n"+
+			"[yellow]Archived:[white] %s\n"+
+			"[yellow]Tags:[white] %s\n"+
+			"%s\n\n"+
 			"[yellow]Description:[white] %s",
-		bookmark.Title,
-		bookmark.URL,
+		tview.Escape(bookmark.Title),
+		tview.Escape(bookmark.URL),
 		bookmark.CreatedAt.Format("2006-01-02 15:04:05"),
+		formatFetchStatus(bookmark),
+		formatReadState(bookmark),
+		formatMarks(bookmark),
+		t.formatLinkStatus(bookmark.ID),
+		t.formatArchive(bookmark.ID),
 		t.formatTags(bookmark.Tags),
-		bookmark.Description,
+		t.formatDetailSuggestions(bookmark),
+		tview.Escape(bookmark.Description),
 	))
+}
 
-	contentView.SetText(fmt.Sprintf(
-		"[yellow]Summary:[white]\n%s\n\n"+
-			"[yellow]Content:[white]\n%s",
-		bookmark.Summary,
-		bookmark.Content,
-	))
+// formatDetailSuggestions updates the tag suggestions offered on the detail
+// page. Suggestions wait for the page content while it is being fetched.
+func (t *TUI) formatDetailSuggestions(bookmark *model.Bookmark) string {
+	t.detailSuggestions = nil
+	if bookmark.IsPending() {
+		return ""
+	}
+	suggestions, err := t.bookmarkService.SuggestTags(bookmark.ID, 9)
+	if err != nil || len(suggestions) == 0 {
+		return ""
+	}
+	for _, suggestion := range suggestions {
+		t.detailSuggestions = append(t.detailSuggestions, suggestion.Tag)
+	}
+	return formatSuggestions(t.detailSuggestions)
+}
+
+// formatFetchStatus describes the fetch state, plus the reading time and
+// document details once the page has been fetched.
+func formatFetchStatus(bookmark *model.Bookmark) string {
+	var details []string
+	if bookmark.ContentType == "application/pdf" {
+		details = append(details, fmt.Sprintf("PDF, %d pages", bookmark.PageCount))
+	}
+	var byline []string
+	if bookmark.Author != "" {
+		byline = append(byline, "by "+bookmark.Author)
+	}
+	if bookmark.PublishedAt != nil {
+		byline = append(byline, "published "+bookmark.PublishedAt.Format("2006-01-02"))
+	}
+	if len(byline) > 0 {
+		details = append(details, strings.Join(byline, ", "))
+	}
+	if bookmark.WordCount > 0 {
+		reading := fmt.Sprintf("%d words, %d min read", bookmark.WordCount, bookmark.ReadingTime)
+		if bookmark.Lang != "" {
+			reading += ", " + bookmark.Lang
+		}
+		details = append(details, reading)
+	}
+	if len(details) == 0 {
+		return bookmark.FetchState
+	}
+	return fmt.Sprintf("%s (%s)", bookmark.FetchState, strings.Join(details, "; "))
+}
 
+func (t *TUI) formatLinkStatus(bookmarkID int64) string {
+	status, err := t.linkChecker.Status(bookmarkID)
+	if err != nil || status == nil {
+		return "not checked"
+	}
 
-	t.app.SetFocus(detailsView)
-	t.showPage("viewBookmark")
+	checked := status.CheckedAt.Local().Format("2006-01-02 15:04")
+	switch {
+	case status.IsBroken():
+		reason := status.Error
+		if reason == "" {
+			reason = fmt.Sprintf("HTTP %d", status.StatusCode)
+		}
+		return fmt.Sprintf("[red]broken[white] (%s, %d failures, checked %s)", tview.Escape(reason), status.ConsecutiveFailures, checked)
+	case status.IsMoved():
+		return fmt.Sprintf("[yellow]moved[white] to %s (checked %s)", tview.Escape(status.FinalURL), checked)
+	case status.Error != "":
+		return fmt.Sprintf("error: %s (checked %s)", tview.Escape(status.Error), checked)
+	default:
+		return fmt.Sprintf("HTTP %d (checked %s)", status.StatusCode, checked)
+	}
+}
+
+func (t *TUI) formatArchive(bookmarkID int64) string {
+	snapshot, err := t.archiver.Latest(bookmarkID)
+	if err != nil || snapshot == nil {
+		return "no"
+	}
+	return fmt.Sprintf("%s (%d KB, %d assets)", snapshot.CreatedAt.Local().Format("2006-01-02 15:04"), snapshot.Size/1024, snapshot.Assets)
 }
 
 func (t *TUI) formatTags(tags []model.Tag) string {